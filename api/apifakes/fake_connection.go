@@ -37,6 +37,13 @@ type FakeConnection struct {
 		result1 string
 		result2 error
 	}
+	ApiVersionStub        func() (string, error)
+	apiVersionMutex       sync.RWMutex
+	apiVersionArgsForCall []struct{}
+	apiVersionReturns     struct {
+		result1 string
+		result2 error
+	}
 	UsernameStub        func() (string, error)
 	usernameMutex       sync.RWMutex
 	usernameArgsForCall []struct{}
@@ -44,15 +51,6 @@ type FakeConnection struct {
 		result1 string
 		result2 error
 	}
-	CliCommandWithoutTerminalOutputStub        func(args ...string) ([]string, error)
-	cliCommandWithoutTerminalOutputMutex       sync.RWMutex
-	cliCommandWithoutTerminalOutputArgsForCall []struct {
-		args []string
-	}
-	cliCommandWithoutTerminalOutputReturns struct {
-		result1 []string
-		result2 error
-	}
 	GetAppStub        func(string) (plugin_models.GetAppModel, error)
 	getAppMutex       sync.RWMutex
 	getAppArgsForCall []struct {
@@ -195,6 +193,32 @@ func (fake *FakeConnection) AccessTokenReturns(result1 string, result2 error) {
 	}{result1, result2}
 }
 
+func (fake *FakeConnection) ApiVersion() (string, error) {
+	fake.apiVersionMutex.Lock()
+	fake.apiVersionArgsForCall = append(fake.apiVersionArgsForCall, struct{}{})
+	fake.recordInvocation("ApiVersion", []interface{}{})
+	fake.apiVersionMutex.Unlock()
+	if fake.ApiVersionStub != nil {
+		return fake.ApiVersionStub()
+	} else {
+		return fake.apiVersionReturns.result1, fake.apiVersionReturns.result2
+	}
+}
+
+func (fake *FakeConnection) ApiVersionCallCount() int {
+	fake.apiVersionMutex.RLock()
+	defer fake.apiVersionMutex.RUnlock()
+	return len(fake.apiVersionArgsForCall)
+}
+
+func (fake *FakeConnection) ApiVersionReturns(result1 string, result2 error) {
+	fake.ApiVersionStub = nil
+	fake.apiVersionReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeConnection) Username() (string, error) {
 	fake.usernameMutex.Lock()
 	fake.usernameArgsForCall = append(fake.usernameArgsForCall, struct{}{})
@@ -221,40 +245,6 @@ func (fake *FakeConnection) UsernameReturns(result1 string, result2 error) {
 	}{result1, result2}
 }
 
-func (fake *FakeConnection) CliCommandWithoutTerminalOutput(args ...string) ([]string, error) {
-	fake.cliCommandWithoutTerminalOutputMutex.Lock()
-	fake.cliCommandWithoutTerminalOutputArgsForCall = append(fake.cliCommandWithoutTerminalOutputArgsForCall, struct {
-		args []string
-	}{args})
-	fake.recordInvocation("CliCommandWithoutTerminalOutput", []interface{}{args})
-	fake.cliCommandWithoutTerminalOutputMutex.Unlock()
-	if fake.CliCommandWithoutTerminalOutputStub != nil {
-		return fake.CliCommandWithoutTerminalOutputStub(args...)
-	} else {
-		return fake.cliCommandWithoutTerminalOutputReturns.result1, fake.cliCommandWithoutTerminalOutputReturns.result2
-	}
-}
-
-func (fake *FakeConnection) CliCommandWithoutTerminalOutputCallCount() int {
-	fake.cliCommandWithoutTerminalOutputMutex.RLock()
-	defer fake.cliCommandWithoutTerminalOutputMutex.RUnlock()
-	return len(fake.cliCommandWithoutTerminalOutputArgsForCall)
-}
-
-func (fake *FakeConnection) CliCommandWithoutTerminalOutputArgsForCall(i int) []string {
-	fake.cliCommandWithoutTerminalOutputMutex.RLock()
-	defer fake.cliCommandWithoutTerminalOutputMutex.RUnlock()
-	return fake.cliCommandWithoutTerminalOutputArgsForCall[i].args
-}
-
-func (fake *FakeConnection) CliCommandWithoutTerminalOutputReturns(result1 []string, result2 error) {
-	fake.CliCommandWithoutTerminalOutputStub = nil
-	fake.cliCommandWithoutTerminalOutputReturns = struct {
-		result1 []string
-		result2 error
-	}{result1, result2}
-}
-
 func (fake *FakeConnection) GetApp(arg1 string) (plugin_models.GetAppModel, error) {
 	fake.getAppMutex.Lock()
 	fake.getAppArgsForCall = append(fake.getAppArgsForCall, struct {
@@ -394,10 +384,10 @@ func (fake *FakeConnection) Invocations() map[string][][]interface{} {
 	defer fake.apiEndpointMutex.RUnlock()
 	fake.accessTokenMutex.RLock()
 	defer fake.accessTokenMutex.RUnlock()
+	fake.apiVersionMutex.RLock()
+	defer fake.apiVersionMutex.RUnlock()
 	fake.usernameMutex.RLock()
 	defer fake.usernameMutex.RUnlock()
-	fake.cliCommandWithoutTerminalOutputMutex.RLock()
-	defer fake.cliCommandWithoutTerminalOutputMutex.RUnlock()
 	fake.getAppMutex.RLock()
 	defer fake.getAppMutex.RUnlock()
 	fake.getOrgMutex.RLock()
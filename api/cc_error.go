@@ -0,0 +1,68 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+)
+
+// CCError is an error the Cloud Controller explained in its response body.
+type CCError struct {
+	StatusCode  int    `json:"-"`
+	Code        int    `json:"code"`
+	ErrorCode   string `json:"error_code"`
+	Description string `json:"description"`
+}
+
+func (e CCError) Error() string {
+	return e.ErrorCode + " - " + e.Description
+}
+
+// Temporary reports whether the Cloud Controller turned the request down
+// because it was overloaded or failed unexpectedly.
+func (e CCError) Temporary() bool {
+	switch e.ErrorCode {
+	case "CF-RateLimitExceeded", "CF-ServiceUnavailable", "CF-ServerError":
+		return true
+	}
+	return StatusError{StatusCode: e.StatusCode}.Temporary()
+}
+
+// IsNotAuthorized reports whether the user is not allowed to do what the
+// request asked for. An expired or invalid token is a different error.
+func IsNotAuthorized(err error) bool {
+	return hasErrorCode(err, "CF-NotAuthorized") || hasStatusCode(err, http.StatusForbidden)
+}
+
+// IsNotFound reports whether the requested resource does not exist, whichever
+// kind of resource it was.
+func IsNotFound(err error) bool {
+	if ccErr, ok := err.(CCError); ok && strings.HasSuffix(ccErr.ErrorCode, "NotFound") {
+		return true
+	}
+	return hasStatusCode(err, http.StatusNotFound)
+}
+
+func IsRateLimited(err error) bool {
+	return hasErrorCode(err, "CF-RateLimitExceeded") || hasStatusCode(err, http.StatusTooManyRequests)
+}
+
+// IsStagingError reports whether the app has no instances to report because
+// staging it failed.
+func IsStagingError(err error) bool {
+	return hasErrorCode(err, "CF-StagingError")
+}
+
+func hasErrorCode(err error, errorCode string) bool {
+	ccErr, ok := err.(CCError)
+	return ok && ccErr.ErrorCode == errorCode
+}
+
+func hasStatusCode(err error, statusCode int) bool {
+	switch e := err.(type) {
+	case CCError:
+		return e.StatusCode == statusCode
+	case StatusError:
+		return e.StatusCode == statusCode
+	}
+	return false
+}
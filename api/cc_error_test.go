@@ -0,0 +1,59 @@
+package api_test
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/diego-enabler/api"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CCError", func() {
+	It("describes the error with its error code", func() {
+		err := api.CCError{ErrorCode: "CF-AppNotFound", Description: "The app could not be found"}
+		Expect(err).To(MatchError("CF-AppNotFound - The app could not be found"))
+	})
+
+	Describe("IsNotAuthorized", func() {
+		It("recognizes the error code and the status", func() {
+			Expect(api.IsNotAuthorized(api.CCError{ErrorCode: "CF-NotAuthorized"})).To(BeTrue())
+			Expect(api.IsNotAuthorized(api.StatusError{StatusCode: http.StatusForbidden})).To(BeTrue())
+		})
+
+		It("does not match errors that only mention it", func() {
+			Expect(api.IsNotAuthorized(errors.New("CF-NotAuthorized - You are not authorized"))).To(BeFalse())
+			Expect(api.IsNotAuthorized(api.CCError{ErrorCode: "CF-InvalidAuthToken", StatusCode: http.StatusUnauthorized})).To(BeFalse())
+		})
+	})
+
+	Describe("IsNotFound", func() {
+		It("recognizes any kind of resource that was not found", func() {
+			Expect(api.IsNotFound(api.CCError{ErrorCode: "CF-AppNotFound"})).To(BeTrue())
+			Expect(api.IsNotFound(api.CCError{ErrorCode: "CF-NotFound"})).To(BeTrue())
+			Expect(api.IsNotFound(api.StatusError{StatusCode: http.StatusNotFound})).To(BeTrue())
+			Expect(api.IsNotFound(api.CCError{ErrorCode: "CF-NotAuthorized"})).To(BeFalse())
+		})
+	})
+
+	Describe("IsRateLimited", func() {
+		It("recognizes the error code and the status", func() {
+			Expect(api.IsRateLimited(api.CCError{ErrorCode: "CF-RateLimitExceeded"})).To(BeTrue())
+			Expect(api.IsRateLimited(api.StatusError{StatusCode: http.StatusTooManyRequests})).To(BeTrue())
+			Expect(api.IsRateLimited(api.StatusError{StatusCode: http.StatusBadGateway})).To(BeFalse())
+		})
+	})
+
+	Describe("IsStagingError", func() {
+		It("recognizes the error code", func() {
+			Expect(api.IsStagingError(api.CCError{ErrorCode: "CF-StagingError"})).To(BeTrue())
+			Expect(api.IsStagingError(errors.New("CF-StagingError - Staging error: failed"))).To(BeFalse())
+		})
+	})
+
+	It("is transient when the Cloud Controller is overloaded or failing", func() {
+		Expect(api.IsTransientError(api.CCError{ErrorCode: "CF-RateLimitExceeded"})).To(BeTrue())
+		Expect(api.IsTransientError(api.CCError{ErrorCode: "CF-UnknownError", StatusCode: http.StatusServiceUnavailable})).To(BeTrue())
+		Expect(api.IsTransientError(api.CCError{ErrorCode: "CF-NotAuthorized", StatusCode: http.StatusForbidden})).To(BeFalse())
+	})
+})
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 
 	"github.com/cloudfoundry/cli/plugin/models"
 )
@@ -13,19 +14,23 @@ import (
 type Client struct {
 	BaseUrl   *url.URL
 	AuthToken string
+	Backend   Backend
 }
 
 //go:generate counterfeiter . Connection
 
+// Connection is what the plugin needs from the cf CLI: whether and where the
+// user is logged in, and the org, space and apps they refer to by name.
+// Everything else goes to the Cloud Controller directly.
 type Connection interface {
 	IsLoggedIn() (bool, error)
 	IsSSLDisabled() (bool, error)
 	ApiEndpoint() (string, error)
 	AccessToken() (string, error)
+	ApiVersion() (string, error)
 
 	Username() (string, error)
 
-	CliCommandWithoutTerminalOutput(args ...string) ([]string, error)
 	GetApp(string) (plugin_models.GetAppModel, error)
 	GetOrg(string) (plugin_models.GetOrg_Model, error)
 	GetSpace(string) (plugin_models.GetSpace_Model, error)
@@ -56,32 +61,53 @@ func NewClient(connection Connection) (*Client, error) {
 		return nil, err
 	}
 
+	backend, err := DetectBackend(connection)
+	if err != nil {
+		return nil, err
+	}
+
 	client := &Client{
 		BaseUrl:   u,
 		AuthToken: authToken,
+		Backend:   backend,
 	}
 
 	return client, nil
 }
 
+// NewGetAppsRequest lists the apps from /v3/apps on foundations that serve
+// it, see DetectBackend.
 func (c *Client) NewGetAppsRequest() (*http.Request, error) {
-	req := &http.Request{
-		Method: "GET",
-		URL:    c.BaseUrl,
+	if c.Backend == BackendV3 {
+		return c.newGetRequest("/v3/apps"), nil
 	}
-	req.URL.Path = "/v2/apps"
+	return c.newGetRequest("/v2/apps"), nil
+}
 
-	return req, nil
+// NewGetV2AppsRequest lists the apps from /v2/apps whatever the backend, for
+// the runtime of the apps.
+func (c *Client) NewGetV2AppsRequest() (*http.Request, error) {
+	return c.newGetRequest("/v2/apps"), nil
 }
 
 func (c *Client) NewGetSpacesRequest() (*http.Request, error) {
-	req := &http.Request{
+	return c.newGetRequest("/v2/spaces"), nil
+}
+
+func (c *Client) NewGetStacksRequest() (*http.Request, error) {
+	return c.newGetRequest("/v2/stacks"), nil
+}
+
+// newGetRequest copies BaseUrl so that requests built at the same time do not
+// share, and overwrite, each other's URL.
+func (c *Client) newGetRequest(path string) *http.Request {
+	u := *c.BaseUrl
+	u.Path = path
+
+	return &http.Request{
 		Method: "GET",
-		URL:    c.BaseUrl,
+		URL:    &u,
 	}
-	req.URL.Path = "/v2/spaces"
-
-	return req, nil
 }
 
 func (c *Client) HandleFiltersAndParameters(next func() (*http.Request, error)) func(filter Filter, params map[string]interface{}) (*http.Request, error) {
@@ -91,7 +117,17 @@ func (c *Client) HandleFiltersAndParameters(next func() (*http.Request, error))
 			return new(http.Request), err
 		}
 
-		req.URL.RawQuery = generateParams(filter, params).Encode()
+		generate := generateParams
+		if strings.HasPrefix(req.URL.Path, "/v3/") {
+			generate = generateV3Params
+		}
+
+		values, err := generate(filter, params)
+		if err != nil {
+			return new(http.Request), err
+		}
+
+		req.URL.RawQuery = values.Encode()
 		return req, nil
 	}
 }
@@ -111,17 +147,27 @@ func (c *Client) Authorize(next func() (*http.Request, error)) func() (*http.Req
 	}
 }
 
-func generateParams(filter Filter, params map[string]interface{}) url.Values {
+func generateParams(filter Filter, params map[string]interface{}) (url.Values, error) {
+	for _, f := range flattenFilters(Filters{filter}) {
+		err := checkV2Filter(f)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	values := url.Values{}
-	q := filter.ToFilterQueryParam()
-	if q != "" {
+	if filters, ok := filter.(Filters); ok {
+		for _, q := range filters.ToFilterQueryParams() {
+			values.Add("q", q)
+		}
+	} else if q := filter.ToFilterQueryParam(); q != "" {
 		values.Set("q", q)
 	}
 
 	for k, v := range params {
 		values.Set(k, fmt.Sprint(v))
 	}
-	return values
+	return values, nil
 }
 
 func NewHttpClient(cliConnection Connection) (*http.Client, error) {
@@ -2,6 +2,8 @@ package api_test
 
 import (
 	"net/http"
+	"net/url"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -93,6 +95,71 @@ var _ = Describe("Api", func() {
 			})
 		})
 
+		Context("when given several filters", func() {
+			It("puts each filter into its own `q`", func() {
+				requestFactory := apiClient.HandleFiltersAndParameters(func() (*http.Request, error) {
+					return &http.Request{Method: "GET", URL: &url.URL{Path: "/foobar"}}, nil
+				})
+				request, err := requestFactory(Filters{
+					EqualFilter{Name: "name", Value: "some-app:name"},
+					EqualFilter{Name: "diego", Value: true},
+				}, params)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(request.URL.Query()["q"]).To(Equal([]string{"name:some-app:name", "diego:true"}))
+			})
+		})
+
+		Context("when a value contains a character the Cloud Controller splits on", func() {
+			var requestFactory RequestFactory
+
+			BeforeEach(func() {
+				requestFactory = apiClient.HandleFiltersAndParameters(func() (*http.Request, error) {
+					return &http.Request{Method: "GET", URL: &url.URL{Path: "/foobar"}}, nil
+				})
+			})
+
+			It("keeps a `:`, since filters are only split at their first operator", func() {
+				request, err := requestFactory(Filters{
+					EqualFilter{Name: "name", Value: "app:name"},
+					InclusionFilter{Name: "name", Values: []interface{}{"app:one", "app:two"}},
+					ComparisonFilter{Name: "updated_at", Operator: GreaterThan, Value: "2016-06-01T00:00:00Z"},
+				}, params)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(request.URL.Query()["q"]).To(Equal([]string{
+					"name:app:name",
+					"name IN app:one,app:two",
+					"updated_at>2016-06-01T00:00:00Z",
+				}))
+			})
+
+			It("refuses a `;` in any value", func() {
+				_, err := requestFactory(EqualFilter{Name: "name", Value: "app;name"}, params)
+				Expect(err).To(MatchError(InvalidFilterValueError{Name: "name", Value: "app;name"}))
+
+				_, err = requestFactory(ComparisonFilter{Name: "name", Operator: GreaterThan, Value: "app;name"}, params)
+				Expect(err).To(MatchError(InvalidFilterValueError{Name: "name", Value: "app;name"}))
+
+				_, err = requestFactory(Filters{
+					EqualFilter{Name: "diego", Value: true},
+					InclusionFilter{Name: "name", Values: []interface{}{"app", "app;name"}},
+				}, params)
+				Expect(err).To(MatchError(InvalidFilterValueError{Name: "name", Value: "app;name"}))
+			})
+
+			It("refuses a `,` in the values of an IN filter", func() {
+				_, err := requestFactory(InclusionFilter{Name: "name", Values: []interface{}{"app", "app,name"}}, params)
+				Expect(err).To(MatchError(InvalidFilterValueError{Name: "name", Value: "app,name"}))
+			})
+
+			It("keeps a `,` in a single value", func() {
+				request, err := requestFactory(EqualFilter{Name: "name", Value: "app,name"}, params)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(request.URL.Query().Get("q")).To(Equal("name:app,name"))
+			})
+		})
+
 		Context("when given params", func() {
 			BeforeEach(func() {
 				params = map[string]interface{}{"param1": "paramValue", "param2": "some value with spaces"}
@@ -115,6 +182,86 @@ var _ = Describe("Api", func() {
 			Expect(request.Method).To(Equal("GET"))
 			Expect(request.URL.String()).To(Equal("https://api.my-crazy-domain.com/v2/apps"))
 		})
+
+		Context("when the Cloud Controller serves the v3 API", func() {
+			BeforeEach(func() {
+				fakeConnection.ApiVersionReturns("2.100.0", nil)
+			})
+
+			It("lists the apps from /v3/apps", func() {
+				Expect(apiClient.Backend).To(Equal(BackendV3))
+				Expect(request.URL.String()).To(Equal("https://api.my-crazy-domain.com/v3/apps"))
+			})
+		})
+	})
+
+	Describe("HandleFiltersAndParameters for v3 requests", func() {
+		var requestFactory RequestFactory
+
+		BeforeEach(func() {
+			fakeConnection.ApiVersionReturns("2.100.0", nil)
+		})
+
+		JustBeforeEach(func() {
+			requestFactory = apiClient.HandleFiltersAndParameters(apiClient.NewGetAppsRequest)
+		})
+
+		It("turns filters into v3 query parameters", func() {
+			since := time.Date(2016, time.June, 1, 0, 0, 0, 0, time.UTC)
+			filters := NewFilterBuilder().
+				Equal("organization_guid", "some-org-guid").
+				In("space_guid", "space-1", "space-2").
+				Since("updated_at", since).
+				Before("created_at", since).
+				Filters()
+
+			request, err := requestFactory(filters, map[string]interface{}{"results-per-page": 50, "page": 2})
+			Expect(err).NotTo(HaveOccurred())
+
+			query := request.URL.Query()
+			Expect(query.Get("organization_guids")).To(Equal("some-org-guid"))
+			Expect(query.Get("space_guids")).To(Equal("space-1,space-2"))
+			Expect(query.Get("updated_ats[gte]")).To(Equal("2016-06-01T00:00:00Z"))
+			Expect(query.Get("created_ats[lt]")).To(Equal("2016-06-01T00:00:00Z"))
+			Expect(query.Get("per_page")).To(Equal("50"))
+			Expect(query.Get("page")).To(Equal("2"))
+			Expect(query).NotTo(HaveKey("q"))
+			Expect(query).NotTo(HaveKey("results-per-page"))
+		})
+
+		It("keeps a `;` or `:` in a value", func() {
+			request, err := requestFactory(EqualFilter{Name: "name", Value: "app;name:one"}, map[string]interface{}{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(request.URL.Query().Get("names")).To(Equal("app;name:one"))
+		})
+
+		It("refuses a `,` in a value, since every filter is a list", func() {
+			_, err := requestFactory(EqualFilter{Name: "name", Value: "app,name"}, map[string]interface{}{})
+			Expect(err).To(MatchError(InvalidFilterValueError{Name: "name", Value: "app,name"}))
+
+			_, err = requestFactory(InclusionFilter{Name: "name", Values: []interface{}{"app", "app,name"}}, map[string]interface{}{})
+			Expect(err).To(MatchError(InvalidFilterValueError{Name: "name", Value: "app,name"}))
+		})
+
+		It("refuses filters the v3 API does not offer", func() {
+			_, err := requestFactory(EqualFilter{Name: "diego", Value: true}, map[string]interface{}{})
+			Expect(err).To(MatchError(UnsupportedFilterError{Filter: "diego:true"}))
+		})
+	})
+
+	Describe("building requests at the same time", func() {
+		It("does not share the URL between requests", func() {
+			appsRequest, err := apiClient.NewGetAppsRequest()
+			Expect(err).NotTo(HaveOccurred())
+			spacesRequest, err := apiClient.NewGetSpacesRequest()
+			Expect(err).NotTo(HaveOccurred())
+
+			appsRequest.URL.RawQuery = "page=2"
+
+			Expect(appsRequest.URL.String()).To(Equal("https://api.my-crazy-domain.com/v2/apps?page=2"))
+			Expect(spacesRequest.URL.String()).To(Equal("https://api.my-crazy-domain.com/v2/spaces"))
+			Expect(apiClient.BaseUrl.String()).To(Equal("https://api.my-crazy-domain.com"))
+		})
 	})
 
 	Describe("NewGetSpacesRequest", func() {
@@ -128,6 +275,17 @@ var _ = Describe("Api", func() {
 		})
 	})
 
+	Describe("NewGetStacksRequest", func() {
+		JustBeforeEach(func() {
+			request, err = apiClient.NewGetStacksRequest()
+		})
+
+		It("hits the appropriate API URL", func() {
+			Expect(request.Method).To(Equal("GET"))
+			Expect(request.URL.String()).To(Equal("https://api.my-crazy-domain.com/v2/stacks"))
+		})
+	})
+
 	Describe("EqualFilter", func() {
 		It("serializes to name:val", func() {
 			filter := EqualFilter{
@@ -164,6 +322,68 @@ var _ = Describe("Api", func() {
 		})
 	})
 
+	Describe("ComparisonFilter", func() {
+		It("puts the operator between the name and the value", func() {
+			filter := ComparisonFilter{
+				Name:     "instances",
+				Operator: GreaterThanOrEqual,
+				Value:    2,
+			}
+			Expect(filter.ToFilterQueryParam()).To(Equal("instances>=2"))
+		})
+
+		It("writes times in UTC the way the Cloud Controller does", func() {
+			filter := ComparisonFilter{
+				Name:     "updated_at",
+				Operator: GreaterThan,
+				Value:    time.Date(2016, time.June, 1, 14, 30, 0, 0, time.FixedZone("CEST", 2*60*60)),
+			}
+			Expect(filter.ToFilterQueryParam()).To(Equal("updated_at>2016-06-01T12:30:00Z"))
+		})
+	})
+
+	Describe("FilterBuilder", func() {
+		It("composes filters in order", func() {
+			since := time.Date(2016, time.June, 1, 0, 0, 0, 0, time.UTC)
+			filters := NewFilterBuilder().
+				Equal("diego", false).
+				In("state", "STARTED", "STOPPED").
+				Since("updated_at", since).
+				Before("created_at", since).
+				Filters()
+
+			Expect(filters.ToFilterQueryParams()).To(Equal([]string{
+				"diego:false",
+				"state IN STARTED,STOPPED",
+				"updated_at>=2016-06-01T00:00:00Z",
+				"created_at<2016-06-01T00:00:00Z",
+			}))
+		})
+
+		It("uses an IN filter only for several values", func() {
+			filters := NewFilterBuilder().
+				OneOf("organization_guid", []string{"org-1"}).
+				OneOf("space_guid", []string{"space-1", "space-2"}).
+				Filters()
+
+			Expect(filters).To(Equal(Filters{
+				EqualFilter{Name: "organization_guid", Value: "org-1"},
+				InclusionFilter{Name: "space_guid", Values: []interface{}{"space-1", "space-2"}},
+			}))
+		})
+
+		It("leaves out optional filters that are not set", func() {
+			filters := NewFilterBuilder().
+				EqualIfSet("space_guid", "").
+				OneOf("organization_guid", nil).
+				Since("updated_at", time.Time{}).
+				Before("created_at", time.Time{}).
+				Filters()
+
+			Expect(filters).To(BeEmpty())
+		})
+	})
+
 	Describe("Filters", func() {
 		It("combines its filters together with semicolons", func() {
 			filter1 := new(apifakes.FakeFilter)
@@ -287,5 +507,11 @@ var _ = Describe("Api", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(pages.TotalPages).To(Equal(1))
 		})
+
+		It("parses the pagination of v3 listings", func() {
+			pages, err := PageParser{}.Parse([]byte(`{"pagination": {"total_results": 250, "total_pages": 3}, "resources": []}`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pages.TotalPages).To(Equal(3))
+		})
 	})
 })
@@ -0,0 +1,284 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/diego-enabler/models"
+)
+
+// DefaultRouteLookupsInFlight bounds how many apps CountAppRoutes users look
+// up at the same time.
+const DefaultRouteLookupsInFlight = 10
+
+// CloudController is the typed client for the Cloud Controller endpoints
+// that are not paginated listings. Every request is retried according to
+// Retry, so it only offers requests that are safe to repeat.
+type CloudController struct {
+	Requests *Client
+	Client   CloudControllerClient
+	Retry    RetryPolicy
+}
+
+func NewCloudController(cliConnection Connection) (*CloudController, error) {
+	apiClient, err := NewClient(cliConnection)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient, err := NewHttpClient(cliConnection)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CloudController{
+		Requests: apiClient,
+		Client:   NewTokenRefreshingClient(NewTracingClient(httpClient, TraceLogger()), cliConnection),
+		Retry:    DefaultRetryPolicy(),
+	}, nil
+}
+
+// AppUpdate holds the fields of an app to change; nil fields are left as
+// they are.
+type AppUpdate struct {
+	Diego *bool `json:"diego,omitempty"`
+}
+
+type AppInstance struct {
+	State string `json:"state"`
+}
+
+func (cc *CloudController) v3() bool {
+	return cc.Requests.Backend == BackendV3
+}
+
+// GetApp reads the app from the v2 API whatever the backend. That is where
+// its runtime is, see MinV3ApiVersion, and the v3 API has nothing the
+// migration needs on top.
+func (cc *CloudController) GetApp(appGUID string) (models.Application, error) {
+	var app models.Application
+	err := cc.do("GET", "/v2/apps/"+appGUID, nil, &app)
+	return app, err
+}
+
+type AppNotFoundError struct {
+	Name string
+}
+
+func (e AppNotFoundError) Error() string {
+	return fmt.Sprintf("App %s not found", e.Name)
+}
+
+// FindApp looks up an app by name in a space. It reads /v2/apps whatever the
+// backend, for the diego flag.
+func (cc *CloudController) FindApp(spaceGUID string, appName string) (models.Application, error) {
+	query, err := generateParams(Filters{
+		EqualFilter{Name: "name", Value: appName},
+		EqualFilter{Name: "space_guid", Value: spaceGUID},
+	}, nil)
+	if err != nil {
+		return models.Application{}, err
+	}
+
+	var page struct {
+		Resources []models.Application `json:"resources"`
+	}
+	err = cc.do("GET", "/v2/apps?"+query.Encode(), nil, &page)
+	if err != nil {
+		return models.Application{}, err
+	}
+
+	if len(page.Resources) == 0 {
+		return models.Application{}, AppNotFoundError{Name: appName}
+	}
+	return page.Resources[0], nil
+}
+
+// V3ProcessesPerPage is the largest page of processes the v3 API serves.
+const V3ProcessesPerPage = 5000
+
+// GetProcesses returns the processes of the apps, which hold their instance
+// count and memory in the v3 API. A page of apps hardly ever has more than
+// one page of processes.
+func (cc *CloudController) GetProcesses(appGUIDs []string) ([]models.V3Process, error) {
+	query := url.Values{}
+	query.Set("app_guids", strings.Join(appGUIDs, ","))
+	query.Set("per_page", strconv.Itoa(V3ProcessesPerPage))
+
+	var processes []models.V3Process
+	next := "/v3/processes?" + query.Encode()
+	for next != "" {
+		var page struct {
+			Pagination struct {
+				Next *struct {
+					Href string `json:"href"`
+				} `json:"next"`
+			} `json:"pagination"`
+			Resources []models.V3Process `json:"resources"`
+		}
+		err := cc.do("GET", next, nil, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		processes = append(processes, page.Resources...)
+
+		next = ""
+		if page.Pagination.Next != nil {
+			next = page.Pagination.Next.Href
+		}
+	}
+
+	return processes, nil
+}
+
+// UpdateApp is safe to retry since setting a field to the same value again is
+// harmless.
+func (cc *CloudController) UpdateApp(appGUID string, update AppUpdate) (models.Application, error) {
+	var app models.Application
+	err := cc.do("PUT", "/v2/apps/"+appGUID, update, &app)
+	return app, err
+}
+
+// GetAppInstances returns the instances of a started app by index. With the
+// v3 backend they are the instances of the app's web process.
+func (cc *CloudController) GetAppInstances(appGUID string) (map[string]AppInstance, error) {
+	if cc.v3() {
+		var stats struct {
+			Resources []struct {
+				Index int    `json:"index"`
+				State string `json:"state"`
+			} `json:"resources"`
+		}
+		err := cc.do("GET", "/v3/apps/"+appGUID+"/processes/web/stats", nil, &stats)
+		if err != nil {
+			return nil, err
+		}
+
+		instances := map[string]AppInstance{}
+		for _, stat := range stats.Resources {
+			instances[strconv.Itoa(stat.Index)] = AppInstance{State: stat.State}
+		}
+		return instances, nil
+	}
+
+	var instances map[string]AppInstance
+	err := cc.do("GET", "/v2/apps/"+appGUID+"/instances", nil, &instances)
+	return instances, err
+}
+
+// CountAppRoutes asks for a single route of the app, which is enough to learn
+// from total_results how many routes it has.
+func (cc *CloudController) CountAppRoutes(appGUID string) (int, error) {
+	if cc.v3() {
+		var page struct {
+			Pagination struct {
+				TotalResults int `json:"total_results"`
+			} `json:"pagination"`
+		}
+		err := cc.do("GET", "/v3/apps/"+appGUID+"/routes?per_page=1", nil, &page)
+		return page.Pagination.TotalResults, err
+	}
+
+	var page struct {
+		TotalResults int `json:"total_results"`
+	}
+	err := cc.do("GET", "/v2/apps/"+appGUID+"/routes?results-per-page=1", nil, &page)
+	return page.TotalResults, err
+}
+
+// do sends a request with an optional JSON body and decodes the response
+// into result. Cloud Controller errors are returned as a CCError.
+func (cc *CloudController) do(method string, path string, body interface{}, result interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return err
+		}
+	}
+
+	u, err := cc.Requests.BaseUrl.Parse(path)
+	if err != nil {
+		return err
+	}
+
+	return cc.Retry.Do(method+" "+u.String(), func() error {
+		var reader io.Reader
+		if payload != nil {
+			reader = bytes.NewReader(payload)
+		}
+
+		req, err := http.NewRequest(method, u.String(), reader)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", cc.Requests.AuthToken)
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		res, err := cc.Client.Do(req)
+		if err != nil {
+			return err
+		}
+
+		defer res.Body.Close()
+		contents, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			return err
+		}
+
+		return decodeResponse(method, u.String(), res.StatusCode, contents, result)
+	})
+}
+
+func decodeResponse(method string, url string, statusCode int, contents []byte, result interface{}) error {
+	if statusCode >= 400 {
+		ccErr := CCError{StatusCode: statusCode}
+		if json.Unmarshal(contents, &ccErr) == nil && ccErr.ErrorCode != "" {
+			return ccErr
+		}
+
+		// The v3 API lists its errors, the first of which is the one to report.
+		var v3Errors struct {
+			Errors []struct {
+				Code   int    `json:"code"`
+				Title  string `json:"title"`
+				Detail string `json:"detail"`
+			} `json:"errors"`
+		}
+		if json.Unmarshal(contents, &v3Errors) == nil && len(v3Errors.Errors) > 0 {
+			v3Err := v3Errors.Errors[0]
+			ccErr.Code = v3Err.Code
+			ccErr.ErrorCode = v3Err.Title
+			ccErr.Description = v3Err.Detail
+			return ccErr
+		}
+
+		return StatusError{
+			Method:     method,
+			URL:        url,
+			StatusCode: statusCode,
+		}
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	err := json.Unmarshal(contents, result)
+	if err != nil {
+		return fmt.Errorf("Unexpected response:\n%s", contents)
+	}
+
+	return nil
+}
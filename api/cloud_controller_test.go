@@ -0,0 +1,287 @@
+package api_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/diego-enabler/api"
+	"github.com/cloudfoundry-incubator/diego-enabler/api/apifakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CloudController", func() {
+	var (
+		fakeClient *apifakes.FakeCloudControllerClient
+		cc         *api.CloudController
+		responses  []string
+		statuses   []int
+	)
+
+	respond := func(statusCode int, body string) {
+		statuses = append(statuses, statusCode)
+		responses = append(responses, body)
+	}
+
+	BeforeEach(func() {
+		fakeClient = new(apifakes.FakeCloudControllerClient)
+		baseURL, err := url.Parse("https://api.example.com")
+		Expect(err).NotTo(HaveOccurred())
+
+		cc = &api.CloudController{
+			Requests: &api.Client{BaseUrl: baseURL, AuthToken: "bearer some-token"},
+			Client:   fakeClient,
+			Retry:    api.RetryPolicy{MaxAttempts: 3},
+		}
+
+		statuses, responses = nil, nil
+		fakeClient.DoStub = func(*http.Request) (*http.Response, error) {
+			i := fakeClient.DoCallCount() - 1
+			if i >= len(responses) {
+				i = len(responses) - 1
+			}
+			return &http.Response{
+				StatusCode: statuses[i],
+				Body:       ioutil.NopCloser(strings.NewReader(responses[i])),
+			}, nil
+		}
+	})
+
+	Describe("GetApp", func() {
+		BeforeEach(func() {
+			respond(http.StatusOK, `{"metadata": {"guid": "some-app-guid"}, "entity": {"name": "some-app", "diego": true}}`)
+		})
+
+		It("gets the app", func() {
+			app, err := cc.GetApp("some-app-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(app.Name).To(Equal("some-app"))
+			Expect(app.Diego).To(BeTrue())
+
+			req := fakeClient.DoArgsForCall(0)
+			Expect(req.Method).To(Equal("GET"))
+			Expect(req.URL.String()).To(Equal("https://api.example.com/v2/apps/some-app-guid"))
+			Expect(req.Header.Get("Authorization")).To(Equal("bearer some-token"))
+		})
+	})
+
+	Describe("UpdateApp", func() {
+		BeforeEach(func() {
+			respond(http.StatusCreated, `{"metadata": {"guid": "some-app-guid"}, "entity": {"name": "some-app", "diego": true}}`)
+		})
+
+		It("sends only the fields to change", func() {
+			enable := true
+			app, err := cc.UpdateApp("some-app-guid", api.AppUpdate{Diego: &enable})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(app.Diego).To(BeTrue())
+
+			req := fakeClient.DoArgsForCall(0)
+			Expect(req.Method).To(Equal("PUT"))
+			Expect(req.URL.String()).To(Equal("https://api.example.com/v2/apps/some-app-guid"))
+			Expect(req.Header.Get("Content-Type")).To(Equal("application/json"))
+
+			body, err := ioutil.ReadAll(req.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(body)).To(MatchJSON(`{"diego": true}`))
+		})
+	})
+
+	Describe("GetAppInstances", func() {
+		BeforeEach(func() {
+			respond(http.StatusOK, `{"0": {"state": "RUNNING"}, "1": {"state": "CRASHED"}}`)
+		})
+
+		It("returns the instances by index", func() {
+			instances, err := cc.GetAppInstances("some-app-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(instances).To(Equal(map[string]api.AppInstance{
+				"0": {State: "RUNNING"},
+				"1": {State: "CRASHED"},
+			}))
+
+			req := fakeClient.DoArgsForCall(0)
+			Expect(req.URL.String()).To(Equal("https://api.example.com/v2/apps/some-app-guid/instances"))
+		})
+	})
+
+	Describe("CountAppRoutes", func() {
+		var (
+			count int
+			err   error
+		)
+
+		JustBeforeEach(func() {
+			count, err = cc.CountAppRoutes("some-app-guid")
+		})
+
+		Context("when the app has routes", func() {
+			BeforeEach(func() {
+				respond(http.StatusOK, `{"total_results": 3, "resources": [{}]}`)
+			})
+
+			It("asks for a single route of the app and returns the total", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(count).To(Equal(3))
+
+				req := fakeClient.DoArgsForCall(0)
+				Expect(req.URL.String()).To(Equal("https://api.example.com/v2/apps/some-app-guid/routes?results-per-page=1"))
+			})
+		})
+
+		Context("when the Cloud Controller returns an error", func() {
+			BeforeEach(func() {
+				respond(http.StatusNotFound, `{"code": 100004, "description": "The app could not be found: some-app-guid", "error_code": "CF-AppNotFound"}`)
+			})
+
+			It("returns the error without retrying", func() {
+				Expect(err).To(MatchError("CF-AppNotFound - The app could not be found: some-app-guid"))
+				Expect(fakeClient.DoCallCount()).To(Equal(1))
+			})
+		})
+
+		Context("when the Cloud Controller is rate limiting", func() {
+			BeforeEach(func() {
+				respond(http.StatusTooManyRequests, `{"code": 10013, "description": "Rate Limit Exceeded", "error_code": "CF-RateLimitExceeded"}`)
+				respond(http.StatusOK, `{"total_results": 1}`)
+			})
+
+			It("retries the request", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(count).To(Equal(1))
+				Expect(fakeClient.DoCallCount()).To(Equal(2))
+			})
+		})
+
+		Context("when the request fails without a Cloud Controller error", func() {
+			BeforeEach(func() {
+				respond(http.StatusBadGateway, "502 Bad Gateway")
+			})
+
+			It("gives up after the last attempt", func() {
+				Expect(err).To(BeAssignableToTypeOf(api.StatusError{}))
+				Expect(fakeClient.DoCallCount()).To(Equal(3))
+			})
+		})
+
+		Context("when the response is not JSON", func() {
+			BeforeEach(func() {
+				respond(http.StatusOK, "<html>")
+			})
+
+			It("returns the response as the error", func() {
+				Expect(err).To(MatchError("Unexpected response:\n<html>"))
+			})
+		})
+	})
+
+	Describe("FindApp", func() {
+		It("looks up the app by name in the space", func() {
+			respond(http.StatusOK, `{"resources": [{"metadata": {"guid": "some-app-guid"}, "entity": {"name": "some-app", "diego": true}}]}`)
+
+			app, err := cc.FindApp("some-space-guid", "some-app")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(app.Guid).To(Equal("some-app-guid"))
+			Expect(app.Diego).To(BeTrue())
+
+			req := fakeClient.DoArgsForCall(0)
+			Expect(req.URL.Path).To(Equal("/v2/apps"))
+			Expect(req.URL.Query()["q"]).To(Equal([]string{"name:some-app", "space_guid:some-space-guid"}))
+		})
+
+		It("reports an app that is not in the space", func() {
+			respond(http.StatusOK, `{"resources": []}`)
+
+			_, err := cc.FindApp("some-space-guid", "some-app")
+			Expect(err).To(MatchError(api.AppNotFoundError{Name: "some-app"}))
+		})
+
+		It("refuses names the Cloud Controller cannot filter on", func() {
+			_, err := cc.FindApp("some-space-guid", "some;app")
+			Expect(err).To(MatchError(api.InvalidFilterValueError{Name: "name", Value: "some;app"}))
+			Expect(fakeClient.DoCallCount()).To(BeZero())
+		})
+	})
+
+	Context("with the v3 backend", func() {
+		BeforeEach(func() {
+			cc.Requests.Backend = api.BackendV3
+		})
+
+		It("gets the app from /v2/apps only", func() {
+			respond(http.StatusOK, `{"metadata": {"guid": "some-app-guid"}, "entity": {"name": "some-app", "diego": false, "instances": 3}}`)
+
+			app, err := cc.GetApp("some-app-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(app.Name).To(Equal("some-app"))
+			Expect(app.Diego).To(BeFalse())
+			Expect(app.InstanceCount).To(Equal(3))
+
+			Expect(fakeClient.DoCallCount()).To(Equal(1))
+			Expect(fakeClient.DoArgsForCall(0).URL.String()).To(Equal("https://api.example.com/v2/apps/some-app-guid"))
+		})
+
+		It("sets the runtime with the diego flag of /v2/apps", func() {
+			respond(http.StatusCreated, `{"metadata": {"guid": "some-app-guid"}, "entity": {"name": "some-app", "diego": false}}`)
+
+			disable := false
+			app, err := cc.UpdateApp("some-app-guid", api.AppUpdate{Diego: &disable})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(app.Diego).To(BeFalse())
+
+			req := fakeClient.DoArgsForCall(0)
+			Expect(req.Method).To(Equal("PUT"))
+			Expect(req.URL.String()).To(Equal("https://api.example.com/v2/apps/some-app-guid"))
+		})
+
+		It("returns the instances of the web process by index", func() {
+			respond(http.StatusOK, `{"resources": [{"type": "web", "index": 0, "state": "RUNNING"}, {"type": "web", "index": 1, "state": "STARTING"}]}`)
+
+			instances, err := cc.GetAppInstances("some-app-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(instances).To(Equal(map[string]api.AppInstance{
+				"0": {State: "RUNNING"},
+				"1": {State: "STARTING"},
+			}))
+
+			req := fakeClient.DoArgsForCall(0)
+			Expect(req.URL.String()).To(Equal("https://api.example.com/v3/apps/some-app-guid/processes/web/stats"))
+		})
+
+		It("counts the routes from the pagination", func() {
+			respond(http.StatusOK, `{"pagination": {"total_results": 2}, "resources": [{}]}`)
+
+			count, err := cc.CountAppRoutes("some-app-guid")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(2))
+
+			req := fakeClient.DoArgsForCall(0)
+			Expect(req.URL.String()).To(Equal("https://api.example.com/v3/apps/some-app-guid/routes?per_page=1"))
+		})
+
+		It("gets the processes of several apps, following the pages", func() {
+			respond(http.StatusOK, `{"pagination": {"next": {"href": "https://api.example.com/v3/processes?app_guids=app-1,app-2&page=2"}}, "resources": [{"type": "web", "instances": 2, "relationships": {"app": {"data": {"guid": "app-1"}}}}]}`)
+			respond(http.StatusOK, `{"pagination": {"next": null}, "resources": [{"type": "web", "instances": 3, "relationships": {"app": {"data": {"guid": "app-2"}}}}]}`)
+
+			processes, err := cc.GetProcesses([]string{"app-1", "app-2"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(processes).To(HaveLen(2))
+			Expect(processes[0].AppGuid()).To(Equal("app-1"))
+			Expect(processes[1].Instances).To(Equal(3))
+
+			Expect(fakeClient.DoCallCount()).To(Equal(2))
+			Expect(fakeClient.DoArgsForCall(0).URL.String()).To(Equal("https://api.example.com/v3/processes?app_guids=app-1%2Capp-2&per_page=5000"))
+			Expect(fakeClient.DoArgsForCall(1).URL.String()).To(Equal("https://api.example.com/v3/processes?app_guids=app-1,app-2&page=2"))
+		})
+
+		It("reads v3 errors", func() {
+			respond(http.StatusNotFound, `{"errors": [{"code": 10010, "title": "CF-ResourceNotFound", "detail": "App not found"}]}`)
+
+			_, err := cc.GetProcesses([]string{"some-app-guid"})
+			Expect(err).To(MatchError("CF-ResourceNotFound - App not found"))
+			Expect(api.IsNotFound(err)).To(BeTrue())
+		})
+	})
+})
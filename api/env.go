@@ -0,0 +1,49 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// EnvWarnings is where settings in the environment that are ignored get
+// reported.
+var EnvWarnings io.Writer = os.Stderr
+
+var (
+	envWarned   = map[string]bool{}
+	envWarnedMu sync.Mutex
+)
+
+// positiveIntFromEnv reads the positive integer the environment variable
+// name is set to. A value that is not a positive integer is reported and
+// ignored.
+func positiveIntFromEnv(name string) (int, bool) {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0, false
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		warnEnv(name, value, "expected a positive whole number")
+		return 0, false
+	}
+	return n, true
+}
+
+// warnEnv reports each setting once, however many clients read it.
+func warnEnv(name, value, reason string) {
+	envWarnedMu.Lock()
+	defer envWarnedMu.Unlock()
+
+	setting := name + "=" + value
+	if envWarned[setting] {
+		return
+	}
+	envWarned[setting] = true
+
+	fmt.Fprintf(EnvWarnings, "Ignoring %s=%q: %s\n", name, value, reason)
+}
@@ -2,9 +2,16 @@ package api
 
 import (
 	"fmt"
+	"net/url"
 	"strings"
+	"time"
 )
 
+// Filters are sent to the Cloud Controller as one `q` parameter each. The
+// Cloud Controller splits a filter at its first operator, so a `:` in a value
+// is kept, but it also splits `q` on `;` and IN values on `,`, and has no way
+// to escape either. Requests filtering on such values fail with an
+// InvalidFilterValueError instead of silently matching something else.
 type Filters []Filter
 
 //go:generate counterfeiter . Filter
@@ -18,19 +25,28 @@ type EqualFilter struct {
 }
 
 func (f EqualFilter) ToFilterQueryParam() string {
-	return fmt.Sprintf("%s:%v", f.Name, f.Value)
+	return fmt.Sprintf("%s:%s", f.Name, formatFilterValue(f.Value))
 }
 
 func (f Filters) ToFilterQueryParam() string {
+	return strings.Join(f.ToFilterQueryParams(), ";")
+}
+
+func (f Filters) ToFilterQueryParams() []string {
 	var filters []string
 
 	for _, x := range f {
-		filters = append(filters, x.ToFilterQueryParam())
+		if nested, ok := x.(Filters); ok {
+			filters = append(filters, nested.ToFilterQueryParams()...)
+		} else {
+			filters = append(filters, x.ToFilterQueryParam())
+		}
 	}
 
-	return strings.Join(filters, ";")
+	return filters
 }
 
+// InclusionFilter matches any of Values.
 type InclusionFilter struct {
 	Name   string
 	Values []interface{}
@@ -40,8 +56,242 @@ func (f InclusionFilter) ToFilterQueryParam() string {
 	var vals []string
 
 	for _, v := range f.Values {
-		vals = append(vals, fmt.Sprint(v))
+		vals = append(vals, formatFilterValue(v))
 	}
 
 	return fmt.Sprintf("%s IN %v", f.Name, strings.Join(vals, ","))
 }
+
+const (
+	GreaterThan        = ">"
+	LessThan           = "<"
+	GreaterThanOrEqual = ">="
+	LessThanOrEqual    = "<="
+)
+
+type ComparisonFilter struct {
+	Name     string
+	Operator string
+	Value    interface{}
+}
+
+func (f ComparisonFilter) ToFilterQueryParam() string {
+	return f.Name + f.Operator + formatFilterValue(f.Value)
+}
+
+type InvalidFilterValueError struct {
+	Name  string
+	Value string
+}
+
+func (e InvalidFilterValueError) Error() string {
+	return fmt.Sprintf("Cannot filter %s on %q: the Cloud Controller cannot tell where the value ends", e.Name, e.Value)
+}
+
+// checkFilterValue formats value, refusing one that contains any of the
+// reserved characters.
+func checkFilterValue(name string, value interface{}, reserved string) (string, error) {
+	formatted := formatFilterValue(value)
+	if strings.ContainsAny(formatted, reserved) {
+		return "", InvalidFilterValueError{Name: name, Value: formatted}
+	}
+	return formatted, nil
+}
+
+// checkV2Filter makes sure the Cloud Controller reads every value of f
+// whole.
+func checkV2Filter(f Filter) error {
+	var err error
+
+	switch f := f.(type) {
+	case EqualFilter:
+		_, err = checkFilterValue(f.Name, f.Value, ";")
+	case ComparisonFilter:
+		_, err = checkFilterValue(f.Name, f.Value, ";")
+	case InclusionFilter:
+		for _, v := range f.Values {
+			_, err = checkFilterValue(f.Name, v, ";,")
+			if err != nil {
+				break
+			}
+		}
+	}
+
+	return err
+}
+
+// formatFilterValue writes times the way the Cloud Controller writes its
+// timestamps.
+func formatFilterValue(value interface{}) string {
+	if t, ok := value.(time.Time); ok {
+		return t.UTC().Format(time.RFC3339)
+	}
+
+	return fmt.Sprint(value)
+}
+
+// FilterBuilder composes filters, leaving out the optional ones that are not
+// set.
+type FilterBuilder struct {
+	filters Filters
+}
+
+func NewFilterBuilder() *FilterBuilder {
+	return &FilterBuilder{filters: Filters{}}
+}
+
+func (b *FilterBuilder) Equal(name string, value interface{}) *FilterBuilder {
+	b.filters = append(b.filters, EqualFilter{Name: name, Value: value})
+	return b
+}
+
+// EqualIfSet only filters on a value that is not empty.
+func (b *FilterBuilder) EqualIfSet(name string, value string) *FilterBuilder {
+	if value == "" {
+		return b
+	}
+	return b.Equal(name, value)
+}
+
+func (b *FilterBuilder) In(name string, values ...interface{}) *FilterBuilder {
+	b.filters = append(b.filters, InclusionFilter{Name: name, Values: values})
+	return b
+}
+
+// OneOf filters on a single value with Equal and on several with In, and
+// not at all without values.
+func (b *FilterBuilder) OneOf(name string, values []string) *FilterBuilder {
+	switch len(values) {
+	case 0:
+		return b
+	case 1:
+		return b.Equal(name, values[0])
+	}
+
+	var inValues []interface{}
+	for _, value := range values {
+		inValues = append(inValues, value)
+	}
+	return b.In(name, inValues...)
+}
+
+func (b *FilterBuilder) Compare(name string, operator string, value interface{}) *FilterBuilder {
+	b.filters = append(b.filters, ComparisonFilter{Name: name, Operator: operator, Value: value})
+	return b
+}
+
+// Since keeps what was changed at or after t, unless t is zero.
+func (b *FilterBuilder) Since(name string, t time.Time) *FilterBuilder {
+	if t.IsZero() {
+		return b
+	}
+	return b.Compare(name, GreaterThanOrEqual, t)
+}
+
+// Before keeps what was changed before t, unless t is zero.
+func (b *FilterBuilder) Before(name string, t time.Time) *FilterBuilder {
+	if t.IsZero() {
+		return b
+	}
+	return b.Compare(name, LessThan, t)
+}
+
+func (b *FilterBuilder) Filters() Filters {
+	return b.filters
+}
+
+// v3 listings take a parameter per field instead of `q`, and only for some
+// fields. Every such parameter is a comma separated list.
+var v3FilterFields = map[string]bool{
+	"guid":              true,
+	"name":              true,
+	"organization_guid": true,
+	"space_guid":        true,
+	"stack":             true,
+	"created_at":        true,
+	"updated_at":        true,
+}
+
+var v3Operators = map[string]string{
+	GreaterThan:        "gt",
+	LessThan:           "lt",
+	GreaterThanOrEqual: "gte",
+	LessThanOrEqual:    "lte",
+}
+
+type UnsupportedFilterError struct {
+	Filter string
+}
+
+func (e UnsupportedFilterError) Error() string {
+	return fmt.Sprintf("The v3 Cloud Controller API cannot filter on %s", e.Filter)
+}
+
+func generateV3Params(filter Filter, params map[string]interface{}) (url.Values, error) {
+	values := url.Values{}
+
+	filters, ok := filter.(Filters)
+	if !ok {
+		filters = Filters{filter}
+	}
+
+	for _, f := range flattenFilters(filters) {
+		switch f := f.(type) {
+		case EqualFilter:
+			if !v3FilterFields[f.Name] {
+				return nil, UnsupportedFilterError{Filter: f.ToFilterQueryParam()}
+			}
+			value, err := checkFilterValue(f.Name, f.Value, ",")
+			if err != nil {
+				return nil, err
+			}
+			values.Set(f.Name+"s", value)
+		case InclusionFilter:
+			if !v3FilterFields[f.Name] {
+				return nil, UnsupportedFilterError{Filter: f.ToFilterQueryParam()}
+			}
+			var vals []string
+			for _, v := range f.Values {
+				value, err := checkFilterValue(f.Name, v, ",")
+				if err != nil {
+					return nil, err
+				}
+				vals = append(vals, value)
+			}
+			values.Set(f.Name+"s", strings.Join(vals, ","))
+		case ComparisonFilter:
+			operator, ok := v3Operators[f.Operator]
+			if !v3FilterFields[f.Name] || !ok {
+				return nil, UnsupportedFilterError{Filter: f.ToFilterQueryParam()}
+			}
+			values.Set(f.Name+"s["+operator+"]", formatFilterValue(f.Value))
+		default:
+			if q := f.ToFilterQueryParam(); q != "" {
+				return nil, UnsupportedFilterError{Filter: q}
+			}
+		}
+	}
+
+	for k, v := range params {
+		if k == "results-per-page" {
+			k = "per_page"
+		}
+		values.Set(k, fmt.Sprint(v))
+	}
+
+	return values, nil
+}
+
+func flattenFilters(filters Filters) []Filter {
+	var flat []Filter
+
+	for _, f := range filters {
+		if nested, ok := f.(Filters); ok {
+			flat = append(flat, flattenFilters(nested)...)
+		} else {
+			flat = append(flat, f)
+		}
+	}
+
+	return flat
+}
@@ -8,8 +8,13 @@ type PaginatedResponse struct {
 
 type PageParser struct{}
 
+// Parse reads the number of pages from a v2 listing, or from the pagination
+// object of a v3 one.
 func (p PageParser) Parse(body []byte) (PaginatedResponse, error) {
-	var pages PaginatedResponse
+	var pages struct {
+		PaginatedResponse
+		Pagination *PaginatedResponse `json:"pagination"`
+	}
 	emptyPages := PaginatedResponse{}
 
 	err := json.Unmarshal(body, &pages)
@@ -17,5 +22,9 @@ func (p PageParser) Parse(body []byte) (PaginatedResponse, error) {
 		return emptyPages, err
 	}
 
-	return pages, nil
+	if pages.Pagination != nil {
+		return *pages.Pagination, nil
+	}
+
+	return pages.PaginatedResponse, nil
 }
@@ -1,8 +1,18 @@
 package api
 
 import (
+	"fmt"
 	"io/ioutil"
 	"net/http"
+	"os"
+	"sync"
+)
+
+const (
+	// MaxResultsPerPage is the largest page the Cloud Controller serves.
+	MaxResultsPerPage = 100
+
+	DefaultMaxPagesInFlight = 4
 )
 
 //go:generate counterfeiter . RequestFactory
@@ -19,10 +29,17 @@ type PaginatedParser interface {
 	Parse([]byte) (PaginatedResponse, error)
 }
 
+// PaginatedRequester fetches every page of a listing. After the first page,
+// up to MaxPagesInFlight pages are fetched at the same time; zero fetches
+// them one after another. ResultsPerPage is left to the Cloud Controller
+// when zero.
 type PaginatedRequester struct {
-	RequestFactory RequestFactory
-	Client         CloudControllerClient
-	PageParser     PaginatedParser
+	RequestFactory   RequestFactory
+	Client           CloudControllerClient
+	PageParser       PaginatedParser
+	Retry            RetryPolicy
+	MaxPagesInFlight int
+	ResultsPerPage   int
 }
 
 func NewPaginatedRequester(cliConnection Connection, requestFactory RequestFactory) (*PaginatedRequester, error) {
@@ -34,61 +51,149 @@ func NewPaginatedRequester(cliConnection Connection, requestFactory RequestFacto
 	}
 
 	return &PaginatedRequester{
-		RequestFactory: requestFactory,
-		Client:         httpClient,
-		PageParser:     pageParser,
+		RequestFactory:   requestFactory,
+		Client:           NewTokenRefreshingClient(NewTracingClient(httpClient, TraceLogger()), cliConnection),
+		PageParser:       pageParser,
+		Retry:            DefaultRetryPolicy(),
+		MaxPagesInFlight: DefaultMaxPagesInFlight,
+		ResultsPerPage:   DefaultResultsPerPage(),
 	}, nil
 }
 
+// DefaultResultsPerPage honors CF_RESULTS_PER_PAGE, capped at
+// MaxResultsPerPage. Zero leaves the page size to the Cloud Controller.
+func DefaultResultsPerPage() int {
+	resultsPerPage, ok := positiveIntFromEnv("CF_RESULTS_PER_PAGE")
+	if !ok {
+		return 0
+	}
+
+	if resultsPerPage > MaxResultsPerPage {
+		warnEnv("CF_RESULTS_PER_PAGE", os.Getenv("CF_RESULTS_PER_PAGE"), fmt.Sprintf("the Cloud Controller serves at most %d", MaxResultsPerPage))
+		return MaxResultsPerPage
+	}
+	return resultsPerPage
+}
+
 func (p *PaginatedRequester) Do(filter Filter, params map[string]interface{}) ([][]byte, error) {
 	var noBodies [][]byte
 
-	req, err := p.RequestFactory(filter, params)
+	body, err := p.getPage(filter, params, 1)
 	if err != nil {
 		return noBodies, err
 	}
 
-	var responseBodies [][]byte
-
-	res, err := p.Client.Do(req)
+	paginatedRes, err := p.PageParser.Parse(body)
 	if err != nil {
 		return noBodies, err
 	}
 
-	defer res.Body.Close()
-	body, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return noBodies, err
+	if paginatedRes.TotalPages <= 1 {
+		return [][]byte{body}, nil
 	}
 
-	responseBodies = append(responseBodies, body)
+	responseBodies := make([][]byte, paginatedRes.TotalPages)
+	responseBodies[0] = body
 
-	paginatedRes, err := p.PageParser.Parse(body)
-	if err != nil {
-		return noBodies, err
+	maxInFlight := p.MaxPagesInFlight
+	if maxInFlight < 1 {
+		maxInFlight = 1
 	}
+
+	var (
+		wg       sync.WaitGroup
+		mutex    sync.Mutex
+		firstErr error
+	)
+	inFlight := make(chan struct{}, maxInFlight)
+
 	for page := 2; page <= paginatedRes.TotalPages; page++ {
-		// construct a new request with the current page
-		params["page"] = page
-		req, err := p.RequestFactory(filter, params)
-		if err != nil {
-			return noBodies, err
+		mutex.Lock()
+		failed := firstErr != nil
+		mutex.Unlock()
+		if failed {
+			break
 		}
 
-		// perform the request
+		inFlight <- struct{}{}
+		wg.Add(1)
+
+		go func(page int) {
+			defer wg.Done()
+			defer func() { <-inFlight }()
+
+			body, err := p.getPage(filter, params, page)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			responseBodies[page-1] = body
+		}(page)
+	}
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return noBodies, firstErr
+	}
+
+	return responseBodies, nil
+}
+
+// getPage builds and performs the request for one page. Every page gets its
+// own copy of params, since pages are requested concurrently.
+func (p *PaginatedRequester) getPage(filter Filter, params map[string]interface{}, page int) ([]byte, error) {
+	pageParams := map[string]interface{}{}
+	for k, v := range params {
+		pageParams[k] = v
+	}
+
+	if page > 1 {
+		pageParams["page"] = page
+	}
+
+	if _, ok := pageParams["results-per-page"]; !ok && p.ResultsPerPage > 0 {
+		pageParams["results-per-page"] = p.ResultsPerPage
+	}
+
+	req, err := p.RequestFactory(filter, pageParams)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.get(req)
+}
+
+// get performs a single page request, retrying it according to the retry
+// policy. Pages are only ever read, so repeating a request is always safe.
+// A page the Cloud Controller refuses to serve is returned as a CCError, the
+// same as for any other request.
+func (p *PaginatedRequester) get(req *http.Request) ([]byte, error) {
+	var body []byte
+
+	err := p.Retry.Do(req.Method+" "+req.URL.String(), func() error {
 		res, err := p.Client.Do(req)
 		if err != nil {
-			return noBodies, err
+			return err
 		}
 
 		defer res.Body.Close()
 		body, err = ioutil.ReadAll(res.Body)
 		if err != nil {
-			return noBodies, err
+			return err
 		}
 
-		responseBodies = append(responseBodies, body)
+		return decodeResponse(req.Method, req.URL.String(), res.StatusCode, body, nil)
+	})
+
+	if err != nil {
+		return nil, err
 	}
 
-	return responseBodies, nil
+	return body, err
 }
@@ -1,11 +1,16 @@
 package api_test
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
+	"sync"
 
 	"io/ioutil"
 	"net/http"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/cloudfoundry-incubator/diego-enabler/api"
 	"github.com/cloudfoundry-incubator/diego-enabler/api/apifakes"
@@ -108,6 +113,118 @@ var _ = Describe("PaginatedRequester", func() {
 			})
 		})
 
+		Context("when the Cloud Controller is temporarily unavailable", func() {
+			var attempts int
+
+			BeforeEach(func() {
+				attempts = 0
+				paginatedRequester.Retry = api.RetryPolicy{
+					MaxAttempts: 3,
+					Sleep:       func(time.Duration) {},
+				}
+
+				fakeCloudControllerClient.DoStub = func(*http.Request) (*http.Response, error) {
+					attempts++
+					if attempts == 1 {
+						return generateApiResponse(""), errors.New("read tcp 10.0.0.1:443: read: connection reset by peer")
+					}
+
+					response := generateApiResponse("some-body")
+					if attempts == 2 {
+						response.StatusCode = http.StatusServiceUnavailable
+					}
+					return response, nil
+				}
+			})
+
+			It("retries the request", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeCloudControllerClient.DoCallCount()).To(Equal(3))
+				Expect(responseBodies).To(Equal([][]byte{[]byte("some-body")}))
+			})
+
+			Context("when it stays unavailable", func() {
+				BeforeEach(func() {
+					fakeCloudControllerClient.DoStub = nil
+					fakeCloudControllerClient.DoReturns(&http.Response{
+						StatusCode: http.StatusBadGateway,
+						Body:       ioutil.NopCloser(strings.NewReader("")),
+					}, nil)
+				})
+
+				It("returns the status once it runs out of attempts", func() {
+					Expect(responseBodies).To(BeEmpty())
+					Expect(err).To(MatchError(ContainSubstring("failed with status 502")))
+					Expect(fakeCloudControllerClient.DoCallCount()).To(Equal(3))
+				})
+			})
+		})
+
+		Context("when the Cloud Controller rejects the request", func() {
+			BeforeEach(func() {
+				paginatedRequester.Retry = api.RetryPolicy{
+					MaxAttempts: 3,
+					Sleep:       func(time.Duration) {},
+				}
+
+				fakeCloudControllerClient.DoReturns(&http.Response{
+					StatusCode: http.StatusBadRequest,
+					Body: ioutil.NopCloser(strings.NewReader(`{
+						"code": 1001,
+						"description": "The query parameter is invalid: q is invalid",
+						"error_code": "CF-BadQueryParameter"
+					}`)),
+				}, nil)
+			})
+
+			It("returns the Cloud Controller error without retrying", func() {
+				Expect(responseBodies).To(BeEmpty())
+				Expect(err).To(Equal(api.CCError{
+					StatusCode:  http.StatusBadRequest,
+					Code:        1001,
+					ErrorCode:   "CF-BadQueryParameter",
+					Description: "The query parameter is invalid: q is invalid",
+				}))
+				Expect(fakeCloudControllerClient.DoCallCount()).To(Equal(1))
+				Expect(fakePaginatedParser.ParseCallCount()).To(BeZero())
+			})
+
+			Context("when the error comes from the v3 API", func() {
+				BeforeEach(func() {
+					fakeCloudControllerClient.DoReturns(&http.Response{
+						StatusCode: http.StatusBadRequest,
+						Body: ioutil.NopCloser(strings.NewReader(`{
+							"errors": [{
+								"code": 10005,
+								"title": "CF-BadQueryParameter",
+								"detail": "The query parameter is invalid: Unknown query parameter(s): 'q'"
+							}]
+						}`)),
+					}, nil)
+				})
+
+				It("returns the first error", func() {
+					Expect(responseBodies).To(BeEmpty())
+					Expect(err).To(MatchError("CF-BadQueryParameter - The query parameter is invalid: Unknown query parameter(s): 'q'"))
+				})
+			})
+
+			Context("without explaining why", func() {
+				BeforeEach(func() {
+					fakeCloudControllerClient.DoReturns(&http.Response{
+						StatusCode: http.StatusForbidden,
+						Body:       ioutil.NopCloser(strings.NewReader("")),
+					}, nil)
+				})
+
+				It("returns the status", func() {
+					Expect(responseBodies).To(BeEmpty())
+					Expect(err).To(MatchError(ContainSubstring("failed with status 403")))
+					Expect(api.IsNotAuthorized(err)).To(BeTrue())
+				})
+			})
+		})
+
 		Context("when making the request succeeds", func() {
 			response := generateApiResponse("")
 
@@ -187,4 +304,161 @@ var _ = Describe("PaginatedRequester", func() {
 			})
 		})
 	})
+
+	Context("when there are many pages", func() {
+		var (
+			mutex       sync.Mutex
+			inFlight    int
+			maxInFlight int
+			arrivals    map[string]chan struct{}
+		)
+
+		BeforeEach(func() {
+			inFlight = 0
+			maxInFlight = 0
+			arrivals = map[string]chan struct{}{
+				"2": make(chan struct{}),
+				"3": make(chan struct{}),
+			}
+
+			fakeRequestFactory.Stub = func(filter api.Filter, params map[string]interface{}) (*http.Request, error) {
+				return http.NewRequest("GET", fmt.Sprintf("/v2/apps?page=%v", params["page"]), nil)
+			}
+			fakePaginatedParser.ParseReturns(api.PaginatedResponse{TotalPages: 6}, nil)
+
+			fakeCloudControllerClient.DoStub = func(req *http.Request) (*http.Response, error) {
+				mutex.Lock()
+				inFlight++
+				if inFlight > maxInFlight {
+					maxInFlight = inFlight
+				}
+				mutex.Unlock()
+
+				// pages 2 and 3 only return once both are in flight, which
+				// can only happen when pages are fetched concurrently
+				page := req.URL.Query().Get("page")
+				if arrived, ok := arrivals[page]; ok {
+					close(arrived)
+					for _, other := range arrivals {
+						select {
+						case <-other:
+						case <-time.After(time.Second):
+						}
+					}
+				}
+
+				mutex.Lock()
+				inFlight--
+				mutex.Unlock()
+
+				return generateApiResponse("page-" + page), nil
+			}
+
+			paginatedRequester.MaxPagesInFlight = 2
+		})
+
+		It("returns the pages in order", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(responseBodies).To(Equal([][]byte{
+				[]byte("page-<nil>"),
+				[]byte("page-2"),
+				[]byte("page-3"),
+				[]byte("page-4"),
+				[]byte("page-5"),
+				[]byte("page-6"),
+			}))
+		})
+
+		It("fetches no more than MaxPagesInFlight pages at a time", func() {
+			Expect(fakeCloudControllerClient.DoCallCount()).To(Equal(6))
+			Expect(maxInFlight).To(Equal(2))
+		})
+
+		It("gives every page its own params", func() {
+			var pages []interface{}
+			for i := 0; i < fakeRequestFactory.CallCount(); i++ {
+				_, params := fakeRequestFactory.ArgsForCall(i)
+				pages = append(pages, params["page"])
+			}
+
+			Expect(pages).To(ConsistOf(BeNil(), 2, 3, 4, 5, 6))
+			Expect(params).To(BeEmpty())
+		})
+
+		Context("when a page fails", func() {
+			BeforeEach(func() {
+				fakeCloudControllerClient.DoStub = func(req *http.Request) (*http.Response, error) {
+					if req.URL.Query().Get("page") == "3" {
+						return nil, errors.New("page 3 failed")
+					}
+					return generateApiResponse("some-body"), nil
+				}
+			})
+
+			It("returns the error", func() {
+				Expect(responseBodies).To(BeEmpty())
+				Expect(err).To(MatchError("page 3 failed"))
+			})
+		})
+	})
+
+	Context("when results per page is set", func() {
+		BeforeEach(func() {
+			fakePaginatedParser.ParseReturns(api.PaginatedResponse{TotalPages: 1}, nil)
+			paginatedRequester.ResultsPerPage = 100
+		})
+
+		It("asks for that many results per page", func() {
+			_, params := fakeRequestFactory.ArgsForCall(0)
+			Expect(params["results-per-page"]).To(Equal(100))
+		})
+
+		Context("when the caller already asked for a page size", func() {
+			BeforeEach(func() {
+				params["results-per-page"] = 10
+			})
+
+			It("keeps the page size of the caller", func() {
+				_, params := fakeRequestFactory.ArgsForCall(0)
+				Expect(params["results-per-page"]).To(Equal(10))
+			})
+		})
+	})
+})
+
+var _ = Describe("DefaultResultsPerPage", func() {
+	var warnings *bytes.Buffer
+
+	BeforeEach(func() {
+		warnings = new(bytes.Buffer)
+		api.EnvWarnings = warnings
+	})
+
+	AfterEach(func() {
+		api.EnvWarnings = os.Stderr
+		os.Unsetenv("CF_RESULTS_PER_PAGE")
+	})
+
+	It("leaves the page size to the Cloud Controller", func() {
+		Expect(api.DefaultResultsPerPage()).To(Equal(0))
+		Expect(warnings.String()).To(BeEmpty())
+	})
+
+	It("honors CF_RESULTS_PER_PAGE", func() {
+		os.Setenv("CF_RESULTS_PER_PAGE", "25")
+		Expect(api.DefaultResultsPerPage()).To(Equal(25))
+		Expect(warnings.String()).To(BeEmpty())
+	})
+
+	It("warns about and ignores a CF_RESULTS_PER_PAGE that is not a number", func() {
+		os.Setenv("CF_RESULTS_PER_PAGE", "lots")
+		Expect(api.DefaultResultsPerPage()).To(Equal(0))
+		Expect(warnings.String()).To(Equal(`Ignoring CF_RESULTS_PER_PAGE="lots": expected a positive whole number` + "\n"))
+	})
+
+	It("warns about and caps a CF_RESULTS_PER_PAGE above MaxResultsPerPage", func() {
+		os.Setenv("CF_RESULTS_PER_PAGE", "500")
+		Expect(api.DefaultResultsPerPage()).To(Equal(api.MaxResultsPerPage))
+		Expect(warnings.String()).To(Equal(`Ignoring CF_RESULTS_PER_PAGE="500": the Cloud Controller serves at most 100` + "\n"))
+	})
 })
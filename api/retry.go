@@ -0,0 +1,156 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry/cli/cf/trace"
+)
+
+const (
+	DefaultMaxAttempts = 5
+	DefaultBaseDelay   = 500 * time.Millisecond
+	DefaultMaxDelay    = 10 * time.Second
+)
+
+// RetryPolicy retries requests that failed with a transient error, waiting
+// exponentially longer between attempts. The zero value makes a single
+// attempt.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+
+	Sleep  func(time.Duration)
+	Logger trace.Printer
+}
+
+// DefaultRetryPolicy honors CF_RETRY_MAX_ATTEMPTS, falling back to
+// DefaultMaxAttempts when it is not set or not a positive number, and traces
+// retries the way CF_TRACE asks for.
+func DefaultRetryPolicy() RetryPolicy {
+	maxAttempts := DefaultMaxAttempts
+	if attempts, ok := positiveIntFromEnv("CF_RETRY_MAX_ATTEMPTS"); ok {
+		maxAttempts = attempts
+	}
+
+	return RetryPolicy{
+		MaxAttempts: maxAttempts,
+		BaseDelay:   DefaultBaseDelay,
+		MaxDelay:    DefaultMaxDelay,
+		Sleep:       time.Sleep,
+		Logger:      TraceLogger(),
+	}
+}
+
+// Do calls attempt until it succeeds, fails with an error that is not
+// transient or runs out of attempts. The last error is returned.
+func (p RetryPolicy) Do(description string, attempt func() error) error {
+	var err error
+
+	for i := 1; ; i++ {
+		err = attempt()
+		if err == nil || !IsTransientError(err) || i >= p.MaxAttempts {
+			return err
+		}
+
+		delay := p.delay(i)
+		if p.Logger != nil {
+			p.Logger.Printf("RETRYING %s in %s (attempt %d of %d failed): %s\n", description, delay, i, p.MaxAttempts, err)
+		}
+		if p.Sleep != nil {
+			p.Sleep(delay)
+		}
+	}
+}
+
+// delay doubles BaseDelay for every failed attempt up to MaxDelay, and then
+// picks a random delay between half of that and all of it so that parallel
+// migrations do not retry in lockstep.
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+type StatusError struct {
+	Method     string
+	URL        string
+	StatusCode int
+}
+
+func (e StatusError) Error() string {
+	return fmt.Sprintf("%s %s failed with status %d %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Temporary reports whether the Cloud Controller, or the router in front of
+// it, is likely to answer the same request successfully later.
+func (e StatusError) Temporary() bool {
+	switch e.StatusCode {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+type temporary interface {
+	Temporary() bool
+}
+
+// some transport errors only come back as text
+var transientMessages = []string{
+	"connection reset by peer",
+	"connection refused",
+	"i/o timeout",
+	"TLS handshake timeout",
+	"unexpected EOF",
+}
+
+// IsTransientError reports whether err is worth retrying: connection resets
+// and timeouts, rate limiting and server errors. Only use it for requests
+// that are safe to repeat.
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return true
+	}
+
+	if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+		return true
+	}
+
+	if t, ok := err.(temporary); ok && t.Temporary() {
+		return true
+	}
+
+	message := err.Error()
+	for _, transient := range transientMessages {
+		if strings.Contains(message, transient) {
+			return true
+		}
+	}
+
+	return strings.HasSuffix(message, ": EOF")
+}
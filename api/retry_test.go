@@ -0,0 +1,159 @@
+package api_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/cloudfoundry-incubator/diego-enabler/api"
+	"github.com/cloudfoundry/cli/cf/trace/fakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RetryPolicy", func() {
+	var (
+		policy     api.RetryPolicy
+		fakeLogger *fakes.FakePrinter
+		sleeps     []time.Duration
+		attempts   int
+	)
+
+	BeforeEach(func() {
+		sleeps = nil
+		attempts = 0
+		fakeLogger = new(fakes.FakePrinter)
+
+		policy = api.RetryPolicy{
+			MaxAttempts: 4,
+			BaseDelay:   time.Second,
+			MaxDelay:    3 * time.Second,
+			Sleep: func(d time.Duration) {
+				sleeps = append(sleeps, d)
+			},
+			Logger: fakeLogger,
+		}
+	})
+
+	failWith := func(errs ...error) func() error {
+		return func() error {
+			attempts++
+			if attempts > len(errs) {
+				return nil
+			}
+			return errs[attempts-1]
+		}
+	}
+
+	It("does not retry a successful attempt", func() {
+		Expect(policy.Do("GET /v2/apps", failWith())).To(Succeed())
+		Expect(attempts).To(Equal(1))
+		Expect(sleeps).To(BeEmpty())
+	})
+
+	It("retries transient errors with a growing, jittered delay", func() {
+		err := policy.Do("GET /v2/apps", failWith(io.EOF, io.EOF, io.EOF))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(attempts).To(Equal(4))
+
+		Expect(sleeps).To(HaveLen(3))
+		Expect(sleeps[0]).To(BeNumerically("~", 750*time.Millisecond, 250*time.Millisecond))
+		Expect(sleeps[1]).To(BeNumerically("~", 1500*time.Millisecond, 500*time.Millisecond))
+		Expect(sleeps[2]).To(BeNumerically("~", 2250*time.Millisecond, 750*time.Millisecond))
+	})
+
+	It("traces every retry", func() {
+		policy.Do("GET /v2/apps", failWith(io.EOF))
+
+		Expect(fakeLogger.PrintfCallCount()).To(Equal(1))
+		format, args := fakeLogger.PrintfArgsForCall(0)
+		Expect(fmt.Sprintf(format, args...)).To(ContainSubstring("RETRYING GET /v2/apps"))
+		Expect(fmt.Sprintf(format, args...)).To(ContainSubstring("attempt 1 of 4 failed"))
+	})
+
+	It("returns the last error once it runs out of attempts", func() {
+		last := api.StatusError{Method: "GET", URL: "/v2/apps", StatusCode: http.StatusBadGateway}
+		err := policy.Do("GET /v2/apps", failWith(io.EOF, io.EOF, io.EOF, last))
+		Expect(err).To(Equal(last))
+		Expect(attempts).To(Equal(4))
+	})
+
+	It("does not retry other errors", func() {
+		disaster := errors.New("disaster")
+		err := policy.Do("GET /v2/apps", failWith(disaster))
+		Expect(err).To(Equal(disaster))
+		Expect(attempts).To(Equal(1))
+	})
+
+	Context("with the zero value", func() {
+		It("makes a single attempt", func() {
+			err := api.RetryPolicy{}.Do("GET /v2/apps", failWith(io.EOF))
+			Expect(err).To(Equal(io.EOF))
+			Expect(attempts).To(Equal(1))
+		})
+	})
+})
+
+var _ = Describe("DefaultRetryPolicy", func() {
+	var warnings *bytes.Buffer
+
+	BeforeEach(func() {
+		warnings = new(bytes.Buffer)
+		api.EnvWarnings = warnings
+	})
+
+	AfterEach(func() {
+		api.EnvWarnings = os.Stderr
+		os.Unsetenv("CF_RETRY_MAX_ATTEMPTS")
+	})
+
+	It("makes DefaultMaxAttempts attempts", func() {
+		Expect(api.DefaultRetryPolicy().MaxAttempts).To(Equal(api.DefaultMaxAttempts))
+		Expect(warnings.String()).To(BeEmpty())
+	})
+
+	It("honors CF_RETRY_MAX_ATTEMPTS", func() {
+		os.Setenv("CF_RETRY_MAX_ATTEMPTS", "2")
+		Expect(api.DefaultRetryPolicy().MaxAttempts).To(Equal(2))
+		Expect(warnings.String()).To(BeEmpty())
+	})
+
+	It("warns about and ignores a CF_RETRY_MAX_ATTEMPTS that is not a number", func() {
+		os.Setenv("CF_RETRY_MAX_ATTEMPTS", "three")
+		Expect(api.DefaultRetryPolicy().MaxAttempts).To(Equal(api.DefaultMaxAttempts))
+		Expect(warnings.String()).To(Equal(`Ignoring CF_RETRY_MAX_ATTEMPTS="three": expected a positive whole number` + "\n"))
+	})
+
+	It("warns about and ignores a CF_RETRY_MAX_ATTEMPTS below one, once", func() {
+		os.Setenv("CF_RETRY_MAX_ATTEMPTS", "0")
+		api.DefaultRetryPolicy()
+		Expect(api.DefaultRetryPolicy().MaxAttempts).To(Equal(api.DefaultMaxAttempts))
+		Expect(warnings.String()).To(Equal(`Ignoring CF_RETRY_MAX_ATTEMPTS="0": expected a positive whole number` + "\n"))
+	})
+})
+
+var _ = Describe("IsTransientError", func() {
+	It("retries connection resets and timeouts", func() {
+		Expect(api.IsTransientError(errors.New("read tcp 10.0.0.1:443: read: connection reset by peer"))).To(BeTrue())
+		Expect(api.IsTransientError(errors.New("dial tcp 10.0.0.1:443: i/o timeout"))).To(BeTrue())
+		Expect(api.IsTransientError(io.EOF)).To(BeTrue())
+	})
+
+	It("retries rate limiting and server errors", func() {
+		Expect(api.IsTransientError(api.StatusError{StatusCode: http.StatusTooManyRequests})).To(BeTrue())
+		Expect(api.IsTransientError(api.StatusError{StatusCode: http.StatusInternalServerError})).To(BeTrue())
+		Expect(api.IsTransientError(api.StatusError{StatusCode: http.StatusBadGateway})).To(BeTrue())
+		Expect(api.IsTransientError(api.StatusError{StatusCode: http.StatusServiceUnavailable})).To(BeTrue())
+	})
+
+	It("does not retry anything else", func() {
+		Expect(api.IsTransientError(nil)).To(BeFalse())
+		Expect(api.IsTransientError(api.StatusError{StatusCode: http.StatusUnauthorized})).To(BeFalse())
+		Expect(api.IsTransientError(api.StatusError{StatusCode: http.StatusNotFound})).To(BeFalse())
+		Expect(api.IsTransientError(errors.New("disaster"))).To(BeFalse())
+	})
+})
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+// TokenRefreshingClient replays requests that were rejected because the
+// access token expired. The CLI connection hands out a fresh token, which is
+// then used for every later request as well.
+type TokenRefreshingClient struct {
+	Client     CloudControllerClient
+	Connection Connection
+
+	mutex sync.Mutex
+	token string
+}
+
+func NewTokenRefreshingClient(client CloudControllerClient, connection Connection) *TokenRefreshingClient {
+	return &TokenRefreshingClient{
+		Client:     client,
+		Connection: connection,
+	}
+}
+
+func (c *TokenRefreshingClient) Do(req *http.Request) (*http.Response, error) {
+	stale := c.authorize(req)
+
+	res, err := c.Client.Do(req)
+	if err != nil || res.StatusCode != http.StatusUnauthorized || !canReplay(req) {
+		return res, err
+	}
+
+	token, err := c.refresh(stale)
+	if err != nil || token == stale {
+		return res, nil
+	}
+
+	io.Copy(ioutil.Discard, res.Body)
+	res.Body.Close()
+
+	if req.GetBody != nil {
+		req.Body, err = req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	req.Header.Set("Authorization", token)
+	return c.Client.Do(req)
+}
+
+// authorize swaps in the refreshed token, if there is one, and returns the
+// token the request is sent with.
+func (c *TokenRefreshingClient) authorize(req *http.Request) string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.token != "" {
+		if req.Header == nil {
+			req.Header = http.Header{}
+		}
+		req.Header.Set("Authorization", c.token)
+	}
+
+	return req.Header.Get("Authorization")
+}
+
+// refresh asks the CLI for a new token, unless another request already
+// replaced the stale one in the meantime.
+func (c *TokenRefreshingClient) refresh(stale string) (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.token != "" && c.token != stale {
+		return c.token, nil
+	}
+
+	token, err := c.Connection.AccessToken()
+	if err != nil {
+		return "", err
+	}
+
+	c.token = token
+	return token, nil
+}
+
+func canReplay(req *http.Request) bool {
+	return req.Body == nil || req.GetBody != nil
+}
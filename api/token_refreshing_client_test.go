@@ -0,0 +1,131 @@
+package api_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/diego-enabler/api"
+	"github.com/cloudfoundry-incubator/diego-enabler/api/apifakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TokenRefreshingClient", func() {
+	var (
+		fakeCloudControllerClient *apifakes.FakeCloudControllerClient
+		fakeConnection            *apifakes.FakeConnection
+		client                    *api.TokenRefreshingClient
+
+		request  *http.Request
+		response *http.Response
+		err      error
+	)
+
+	respond := func(statusCode int, body string) *http.Response {
+		return &http.Response{
+			StatusCode: statusCode,
+			Body:       ioutil.NopCloser(strings.NewReader(body)),
+		}
+	}
+
+	newRequest := func() *http.Request {
+		req, err := http.NewRequest("GET", "https://api.example.com/v2/apps", nil)
+		Expect(err).NotTo(HaveOccurred())
+		req.Header.Set("Authorization", "bearer stale-token")
+		return req
+	}
+
+	BeforeEach(func() {
+		fakeCloudControllerClient = new(apifakes.FakeCloudControllerClient)
+		fakeConnection = new(apifakes.FakeConnection)
+		fakeConnection.AccessTokenReturns("bearer fresh-token", nil)
+
+		fakeCloudControllerClient.DoStub = func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("Authorization") == "bearer fresh-token" {
+				return respond(http.StatusOK, "some-body"), nil
+			}
+			return respond(http.StatusUnauthorized, `{"code":1000,"error_code":"CF-InvalidAuthToken"}`), nil
+		}
+
+		client = api.NewTokenRefreshingClient(fakeCloudControllerClient, fakeConnection)
+		request = newRequest()
+	})
+
+	JustBeforeEach(func() {
+		response, err = client.Do(request)
+	})
+
+	Context("when the token is still valid", func() {
+		BeforeEach(func() {
+			request.Header.Set("Authorization", "bearer fresh-token")
+		})
+
+		It("sends the request once", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.StatusCode).To(Equal(http.StatusOK))
+			Expect(fakeCloudControllerClient.DoCallCount()).To(Equal(1))
+			Expect(fakeConnection.AccessTokenCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when the token expired", func() {
+		It("replays the request with a fresh token", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.StatusCode).To(Equal(http.StatusOK))
+			Expect(fakeConnection.AccessTokenCallCount()).To(Equal(1))
+			Expect(fakeCloudControllerClient.DoCallCount()).To(Equal(2))
+
+			body, err := ioutil.ReadAll(response.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(body)).To(Equal("some-body"))
+		})
+
+		It("uses the fresh token for later requests", func() {
+			response, err = client.Do(newRequest())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.StatusCode).To(Equal(http.StatusOK))
+
+			Expect(fakeConnection.AccessTokenCallCount()).To(Equal(1))
+			Expect(fakeCloudControllerClient.DoCallCount()).To(Equal(3))
+			Expect(fakeCloudControllerClient.DoArgsForCall(2).Header.Get("Authorization")).To(Equal("bearer fresh-token"))
+		})
+	})
+
+	Context("when the CLI hands out the same token", func() {
+		BeforeEach(func() {
+			fakeConnection.AccessTokenReturns("bearer stale-token", nil)
+		})
+
+		It("returns the unauthorized response without replaying it", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.StatusCode).To(Equal(http.StatusUnauthorized))
+			Expect(fakeCloudControllerClient.DoCallCount()).To(Equal(1))
+		})
+	})
+
+	Context("when the token cannot be refreshed", func() {
+		BeforeEach(func() {
+			fakeConnection.AccessTokenReturns("", errors.New("not logged in"))
+		})
+
+		It("returns the unauthorized response", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.StatusCode).To(Equal(http.StatusUnauthorized))
+			Expect(fakeCloudControllerClient.DoCallCount()).To(Equal(1))
+		})
+	})
+
+	Context("when the request fails", func() {
+		BeforeEach(func() {
+			fakeCloudControllerClient.DoStub = nil
+			fakeCloudControllerClient.DoReturns(nil, errors.New("disaster"))
+		})
+
+		It("returns the error", func() {
+			Expect(err).To(MatchError("disaster"))
+			Expect(fakeConnection.AccessTokenCallCount()).To(Equal(0))
+		})
+	})
+})
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry/cli/cf/terminal"
+	"github.com/cloudfoundry/cli/cf/trace"
+)
+
+const PrivateDataPlaceholder = "[PRIVATE DATA HIDDEN]"
+
+var (
+	traceLogger     trace.Printer
+	traceLoggerOnce sync.Once
+)
+
+// TraceLogger writes to stdout or to the file CF_TRACE names, the way the cf
+// CLI does. It is nil when CF_TRACE is not set, or set to false.
+func TraceLogger() trace.Printer {
+	traceLoggerOnce.Do(func() {
+		cfTrace := os.Getenv("CF_TRACE")
+		if enabled, err := strconv.ParseBool(cfTrace); cfTrace == "" || (err == nil && !enabled) {
+			return
+		}
+
+		traceLogger = trace.NewLogger(false, cfTrace, "")
+	})
+
+	return traceLogger
+}
+
+// TracingClient dumps every request and response it passes on, with the
+// credentials in them hidden.
+type TracingClient struct {
+	Client CloudControllerClient
+	Logger trace.Printer
+}
+
+// NewTracingClient only wraps client when there is a logger to trace to.
+func NewTracingClient(client CloudControllerClient, logger trace.Printer) CloudControllerClient {
+	if logger == nil {
+		return client
+	}
+
+	return &TracingClient{
+		Client: client,
+		Logger: logger,
+	}
+}
+
+func (c *TracingClient) Do(req *http.Request) (*http.Response, error) {
+	dumpedRequest, err := httputil.DumpRequestOut(req, true)
+	if err != nil {
+		c.Logger.Printf("Error dumping request\n%s\n", err)
+	} else {
+		c.Logger.Printf("\n%s [%s]\n%s\n", terminal.HeaderColor("REQUEST:"), time.Now().Format(time.RFC3339), Redact(string(dumpedRequest)))
+	}
+
+	start := time.Now()
+	res, err := c.Client.Do(req)
+	elapsed := time.Since(start)
+	if err != nil {
+		c.Logger.Printf("\n%s [%s] (%s)\n%s\n", terminal.HeaderColor("RESPONSE:"), time.Now().Format(time.RFC3339), elapsed, err)
+		return res, err
+	}
+
+	dumpedResponse, dumpErr := httputil.DumpResponse(res, true)
+	if dumpErr != nil {
+		c.Logger.Printf("Error dumping response\n%s\n", dumpErr)
+	} else {
+		c.Logger.Printf("\n%s [%s] (%s)\n%s\n", terminal.HeaderColor("RESPONSE:"), time.Now().Format(time.RFC3339), elapsed, Redact(string(dumpedResponse)))
+	}
+
+	return res, nil
+}
+
+var (
+	authorizationHeader = regexp.MustCompile(`(?mi)^Authorization: [^\r\n]*`)
+	secretProperties    = regexp.MustCompile(`"(access_token|refresh_token|token|password)":\s*"[^"]*"`)
+)
+
+// Redact hides the Authorization header and any tokens or passwords in JSON
+// bodies of a dumped request or response.
+func Redact(dump string) string {
+	redacted := authorizationHeader.ReplaceAllString(dump, "Authorization: "+PrivateDataPlaceholder)
+	return secretProperties.ReplaceAllString(redacted, `"$1":"`+PrivateDataPlaceholder+`"`)
+}
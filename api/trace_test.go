@@ -0,0 +1,93 @@
+package api_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/diego-enabler/api"
+	"github.com/cloudfoundry-incubator/diego-enabler/api/apifakes"
+	"github.com/cloudfoundry/cli/cf/trace"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TracingClient", func() {
+	var (
+		fakeClient *apifakes.FakeCloudControllerClient
+		output     *bytes.Buffer
+		client     api.CloudControllerClient
+		req        *http.Request
+	)
+
+	BeforeEach(func() {
+		fakeClient = new(apifakes.FakeCloudControllerClient)
+		fakeClient.DoReturns(&http.Response{
+			StatusCode: http.StatusOK,
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"access_token": "some-secret", "total_results": 1}`)),
+		}, nil)
+
+		output = new(bytes.Buffer)
+		client = api.NewTracingClient(fakeClient, trace.NewWriterPrinter(output, false))
+
+		var err error
+		req, err = http.NewRequest("PUT", "https://api.example.com/v2/apps/some-app-guid", strings.NewReader(`{"diego":true,"password":"hunter2"}`))
+		Expect(err).NotTo(HaveOccurred())
+		req.Header.Set("Authorization", "bearer some-token")
+	})
+
+	It("passes the request on", func() {
+		_, err := client.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fakeClient.DoCallCount()).To(Equal(1))
+
+		body, err := ioutil.ReadAll(fakeClient.DoArgsForCall(0).Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(Equal(`{"diego":true,"password":"hunter2"}`))
+	})
+
+	It("traces the request and the response", func() {
+		res, err := client.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(output.String()).To(ContainSubstring("REQUEST:"))
+		Expect(output.String()).To(ContainSubstring("PUT /v2/apps/some-app-guid HTTP/1.1"))
+		Expect(output.String()).To(ContainSubstring(`"diego":true`))
+		Expect(output.String()).To(ContainSubstring("RESPONSE:"))
+		Expect(output.String()).To(ContainSubstring("HTTP/1.1 200 OK"))
+		Expect(output.String()).To(ContainSubstring(`"total_results": 1`))
+
+		body, err := ioutil.ReadAll(res.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(ContainSubstring("some-secret"))
+	})
+
+	It("hides credentials", func() {
+		_, err := client.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(output.String()).To(ContainSubstring("Authorization: [PRIVATE DATA HIDDEN]"))
+		Expect(output.String()).To(ContainSubstring(`"password":"[PRIVATE DATA HIDDEN]"`))
+		Expect(output.String()).To(ContainSubstring(`"access_token":"[PRIVATE DATA HIDDEN]"`))
+		Expect(output.String()).NotTo(ContainSubstring("some-token"))
+		Expect(output.String()).NotTo(ContainSubstring("hunter2"))
+		Expect(output.String()).NotTo(ContainSubstring("some-secret"))
+	})
+
+	It("traces requests that fail", func() {
+		fakeClient.DoReturns(nil, errors.New("connection refused"))
+
+		_, err := client.Do(req)
+		Expect(err).To(MatchError("connection refused"))
+		Expect(output.String()).To(ContainSubstring("RESPONSE:"))
+		Expect(output.String()).To(ContainSubstring("connection refused"))
+	})
+
+	It("does not wrap the client without a logger", func() {
+		Expect(api.NewTracingClient(fakeClient, nil)).To(BeIdenticalTo(fakeClient))
+	})
+})
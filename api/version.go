@@ -0,0 +1,96 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Backend is the Cloud Controller API the plugin reads apps from.
+type Backend string
+
+const (
+	BackendV2 Backend = "v2"
+	BackendV3 Backend = "v3"
+)
+
+// MinV3ApiVersion is the first Cloud Controller API version the plugin reads
+// apps from /v3/apps for. The v3 API does not know about the DEAs, so the v3
+// backend still reads and sets which runtime an app runs on through the diego
+// flag of /v2/apps, which those foundations keep serving for as long as they
+// have DEAs.
+const MinV3ApiVersion = "2.100.0"
+
+// DetectBackend picks the backend from the API version the cf CLI got from
+// /v2/info when logging in. A CLI that does not know the version, or a
+// version that cannot be read, gets the v2 backend.
+func DetectBackend(connection Connection) (Backend, error) {
+	rawVersion, err := connection.ApiVersion()
+	if err != nil {
+		return "", err
+	}
+
+	if rawVersion == "" {
+		return BackendV2, nil
+	}
+
+	version, err := ParseApiVersion(rawVersion)
+	if err != nil {
+		return BackendV2, nil
+	}
+
+	minV3, _ := ParseApiVersion(MinV3ApiVersion)
+	if version.AtLeast(minV3) {
+		return BackendV3, nil
+	}
+	return BackendV2, nil
+}
+
+type ApiVersion struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// ParseApiVersion reads a semantic version, ignoring any pre-release or build
+// suffix such as the -rc1 of 2.128.0-rc1.
+func ParseApiVersion(version string) (ApiVersion, error) {
+	release := strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(release, "-+"); i >= 0 {
+		release = release[:i]
+	}
+	parts := strings.SplitN(release, ".", 3)
+
+	var numbers [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return ApiVersion{}, InvalidApiVersionError{Version: version}
+		}
+		numbers[i] = n
+	}
+
+	return ApiVersion{Major: numbers[0], Minor: numbers[1], Patch: numbers[2]}, nil
+}
+
+func (v ApiVersion) AtLeast(other ApiVersion) bool {
+	if v.Major != other.Major {
+		return v.Major > other.Major
+	}
+	if v.Minor != other.Minor {
+		return v.Minor > other.Minor
+	}
+	return v.Patch >= other.Patch
+}
+
+func (v ApiVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+type InvalidApiVersionError struct {
+	Version string
+}
+
+func (e InvalidApiVersionError) Error() string {
+	return fmt.Sprintf("Unable to parse the Cloud Controller API version: %s", e.Version)
+}
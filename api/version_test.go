@@ -0,0 +1,114 @@
+package api_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/diego-enabler/api"
+	"github.com/cloudfoundry-incubator/diego-enabler/api/apifakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Version", func() {
+	Describe("DetectBackend", func() {
+		var fakeConnection *apifakes.FakeConnection
+
+		BeforeEach(func() {
+			fakeConnection = new(apifakes.FakeConnection)
+		})
+
+		It("uses v2 before the v3 apps endpoint was available", func() {
+			fakeConnection.ApiVersionReturns("2.54.0", nil)
+
+			backend, err := api.DetectBackend(fakeConnection)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(backend).To(Equal(api.BackendV2))
+		})
+
+		It("uses v3 from MinV3ApiVersion on", func() {
+			fakeConnection.ApiVersionReturns(api.MinV3ApiVersion, nil)
+
+			backend, err := api.DetectBackend(fakeConnection)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(backend).To(Equal(api.BackendV3))
+
+			fakeConnection.ApiVersionReturns("3.0.0", nil)
+
+			backend, err = api.DetectBackend(fakeConnection)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(backend).To(Equal(api.BackendV3))
+		})
+
+		It("uses v2 when the version is not known", func() {
+			fakeConnection.ApiVersionReturns("", nil)
+
+			backend, err := api.DetectBackend(fakeConnection)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(backend).To(Equal(api.BackendV2))
+		})
+
+		It("returns the error getting the version", func() {
+			fakeConnection.ApiVersionReturns("", errors.New("not logged in"))
+
+			_, err := api.DetectBackend(fakeConnection)
+			Expect(err).To(MatchError("not logged in"))
+		})
+
+		It("uses v3 for pre-release and build versions from MinV3ApiVersion on", func() {
+			fakeConnection.ApiVersionReturns("2.128.0-rc1", nil)
+
+			backend, err := api.DetectBackend(fakeConnection)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(backend).To(Equal(api.BackendV3))
+
+			fakeConnection.ApiVersionReturns("2.128.0+build.7", nil)
+
+			backend, err = api.DetectBackend(fakeConnection)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(backend).To(Equal(api.BackendV3))
+		})
+
+		It("uses v2 for versions it cannot read", func() {
+			fakeConnection.ApiVersionReturns("two", nil)
+
+			backend, err := api.DetectBackend(fakeConnection)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(backend).To(Equal(api.BackendV2))
+		})
+	})
+
+	Describe("ParseApiVersion", func() {
+		It("accepts a leading v and missing parts", func() {
+			version, err := api.ParseApiVersion("v2.65")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version.String()).To(Equal("2.65.0"))
+		})
+
+		It("ignores a pre-release or build suffix", func() {
+			version, err := api.ParseApiVersion("2.128.0-rc1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version.String()).To(Equal("2.128.0"))
+
+			version, err = api.ParseApiVersion("v2.128.0+build.7")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version.String()).To(Equal("2.128.0"))
+
+			version, err = api.ParseApiVersion("2.128-beta.1+exp")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version.String()).To(Equal("2.128.0"))
+		})
+
+		It("refuses versions it cannot read", func() {
+			_, err := api.ParseApiVersion("two")
+			Expect(err).To(MatchError(api.InvalidApiVersionError{Version: "two"}))
+		})
+
+		It("compares every part numerically", func() {
+			older, _ := api.ParseApiVersion("2.99.12")
+			newer, _ := api.ParseApiVersion("2.100.0")
+			Expect(newer.AtLeast(older)).To(BeTrue())
+			Expect(older.AtLeast(newer)).To(BeFalse())
+			Expect(newer.AtLeast(newer)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"github.com/cloudfoundry-incubator/diego-enabler/commands/diegohelpers"
+	"github.com/cloudfoundry-incubator/diego-enabler/commands/flaghelpers"
+	"github.com/cloudfoundry-incubator/diego-enabler/commands/listhelpers"
+	"github.com/cloudfoundry-incubator/diego-enabler/ui"
+)
+
+type AppsByRuntimeCommand struct {
+	Organizations []string                     `short:"o" value-name:"ORG" description:"Organization to restrict the app listing to (can be repeated)"`
+	Spaces        []flaghelpers.SpaceFlag      `short:"s" value-name:"SPACE" description:"Space to restrict the app listing to, in the targeted organization or given as ORG/SPACE (can be repeated)"`
+	State         flaghelpers.StateFlag        `long:"state" value-name:"STATE" description:"Only include apps in STATE (started or stopped)"`
+	UpdatedSince  flaghelpers.TimeFlag         `long:"updated-since" value-name:"TIME" description:"Only include apps updated at or after TIME, a date or an RFC 3339 timestamp"`
+	CreatedBefore flaghelpers.TimeFlag         `long:"created-before" value-name:"TIME" description:"Only include apps created before TIME, a date or an RFC 3339 timestamp"`
+	Include       []flaghelpers.AppNamePattern `long:"include" value-name:"PATTERN" description:"Only include apps whose name matches PATTERN, a glob or a /regex/ (can be repeated)"`
+	Exclude       []flaghelpers.AppNamePattern `long:"exclude" value-name:"PATTERN" description:"Exclude apps whose name matches PATTERN, a glob or a /regex/ (can be repeated)"`
+	Output        flaghelpers.OutputFormatFlag `long:"output" value-name:"FORMAT" default:"table" description:"Output format: table, json, csv or yaml"`
+	Columns       flaghelpers.ColumnsFlag      `long:"columns" value-name:"COLUMNS" description:"Also show COLUMNS, a comma separated list of state, instances, memory, disk, stack, buildpack, routes, health-check and package-state"`
+	SortBy        flaghelpers.SortKeyFlag      `long:"sort-by" value-name:"KEY" default:"name" description:"Sort apps by name, org, space or runtime"`
+	GroupBy       flaghelpers.GroupKeyFlag     `long:"group-by" value-name:"KEY" description:"Group apps by org, space or runtime"`
+}
+
+func (command AppsByRuntimeCommand) Execute([]string) error {
+	cliConnection := DiegoEnabler.CLIConnection
+
+	scope, err := diegohelpers.AppsScope{Organizations: command.Organizations, Spaces: command.Spaces}.Resolve(cliConnection)
+	if err != nil {
+		return err
+	}
+
+	filter := diegohelpers.AppsFilter{
+		State:         command.State,
+		UpdatedSince:  command.UpdatedSince,
+		CreatedBefore: command.CreatedBefore,
+	}
+
+	appsGetter, err := diegohelpers.NewAllAppsGetterFunc(cliConnection, scope, filter, command.Columns.Includes(ui.ColumnRoutes))
+	if err != nil {
+		return err
+	}
+
+	listAppsCommand, err := listhelpers.NewListAppsCommand(cliConnection, scope.Scopes, "")
+	if err != nil {
+		return err
+	}
+
+	listAppsCommand.Output = command.Output.Value
+	listAppsCommand.Columns = command.Columns.Columns
+	listAppsCommand.SortBy = command.SortBy.Value
+	listAppsCommand.GroupBy = command.GroupBy.Value
+
+	nameFilter := diegohelpers.NewAppNameFilter(command.Include, command.Exclude)
+
+	return listhelpers.ListApps(cliConnection, appsGetter, nameFilter, &listAppsCommand)
+}
@@ -0,0 +1,54 @@
+package commands_test
+
+import (
+	. "github.com/cloudfoundry-incubator/diego-enabler/commands"
+	"github.com/cloudfoundry-incubator/diego-enabler/commands/diegohelpers"
+	"github.com/cloudfoundry-incubator/diego-enabler/commands/flaghelpers"
+	"github.com/cloudfoundry/cli/plugin/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AppsByRuntime", func() {
+	var (
+		command AppsByRuntimeCommand
+
+		err error
+	)
+
+	JustBeforeEach(func() {
+		err = command.Execute([]string{})
+	})
+
+	Context("when a space is not in the organization it is given with", func() {
+		BeforeEach(func() {
+			fakeConnection.GetOrgReturns(plugin_models.GetOrg_Model{
+				Guid:   "some-organization-guid",
+				Name:   "some-organization",
+				Spaces: []plugin_models.GetOrg_Space{{Guid: "other-space-guid", Name: "other-space"}},
+			}, nil)
+
+			command = AppsByRuntimeCommand{
+				Organizations: []string{"some-organization"},
+				Spaces:        []flaghelpers.SpaceFlag{{Organization: "some-organization", Space: "some-space"}},
+			}
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(Equal(diegohelpers.SpaceNotFoundErr{SpaceName: "some-organization/some-space"}))
+		})
+	})
+
+	Context("when an organization cannot be found", func() {
+		BeforeEach(func() {
+			command = AppsByRuntimeCommand{
+				Organizations: []string{"some-organization"},
+			}
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(Equal(diegohelpers.OrgNotFoundErr{OrganizationName: "some-organization"}))
+		})
+	})
+})
@@ -2,36 +2,54 @@ package commands
 
 import (
 	"github.com/cloudfoundry-incubator/diego-enabler/commands/diegohelpers"
-	"github.com/cloudfoundry-incubator/diego-enabler/commands/errorhelpers"
+	"github.com/cloudfoundry-incubator/diego-enabler/commands/flaghelpers"
 	"github.com/cloudfoundry-incubator/diego-enabler/commands/listhelpers"
 	"github.com/cloudfoundry-incubator/diego-enabler/ui"
 )
 
 type DeaAppsCommand struct {
-	Organization string `short:"o" value-name:"ORG" description:"Organization to restrict the app migration to"`
-	Space        string `short:"s" value-name:"SPACE" description:"Space in the targeted organization to limit results to"`
+	Organizations []string                     `short:"o" value-name:"ORG" description:"Organization to restrict the app listing to (can be repeated)"`
+	Spaces        []flaghelpers.SpaceFlag      `short:"s" value-name:"SPACE" description:"Space to restrict the app listing to, in the targeted organization or given as ORG/SPACE (can be repeated)"`
+	State         flaghelpers.StateFlag        `long:"state" value-name:"STATE" description:"Only include apps in STATE (started or stopped)"`
+	UpdatedSince  flaghelpers.TimeFlag         `long:"updated-since" value-name:"TIME" description:"Only include apps updated at or after TIME, a date or an RFC 3339 timestamp"`
+	CreatedBefore flaghelpers.TimeFlag         `long:"created-before" value-name:"TIME" description:"Only include apps created before TIME, a date or an RFC 3339 timestamp"`
+	Include       []flaghelpers.AppNamePattern `long:"include" value-name:"PATTERN" description:"Only include apps whose name matches PATTERN, a glob or a /regex/ (can be repeated)"`
+	Exclude       []flaghelpers.AppNamePattern `long:"exclude" value-name:"PATTERN" description:"Exclude apps whose name matches PATTERN, a glob or a /regex/ (can be repeated)"`
+	Output        flaghelpers.OutputFormatFlag `long:"output" value-name:"FORMAT" default:"table" description:"Output format: table, json, csv or yaml"`
+	Columns       flaghelpers.ColumnsFlag      `long:"columns" value-name:"COLUMNS" description:"Also show COLUMNS, a comma separated list of state, instances, memory, disk, stack, buildpack, routes, health-check and package-state"`
 }
 
 func (command DeaAppsCommand) Execute([]string) error {
 	cliConnection := DiegoEnabler.CLIConnection
 	runtime := ui.DEA
 
-	err := errorhelpers.ErrorIfOrgAndSpacesSet(command.Organization, command.Space)
+	scope, err := diegohelpers.AppsScope{Organizations: command.Organizations, Spaces: command.Spaces}.Resolve(cliConnection)
 	if err != nil {
 		return err
 	}
 
-	appsGetter, err := diegohelpers.NewAppsGetterFunc(cliConnection, command.Organization, command.Space, runtime)
+	filter := diegohelpers.AppsFilter{
+		State:         command.State,
+		UpdatedSince:  command.UpdatedSince,
+		CreatedBefore: command.CreatedBefore,
+	}
+
+	appsGetter, err := diegohelpers.NewAppsGetterFunc(cliConnection, scope, filter, runtime, command.Columns.Includes(ui.ColumnRoutes))
 	if err != nil {
 		return err
 	}
 
-	listAppsCommand, err := listhelpers.NewListAppsCommand(cliConnection, command.Organization, command.Space, runtime)
+	listAppsCommand, err := listhelpers.NewListAppsCommand(cliConnection, scope.Scopes, runtime)
 	if err != nil {
 		return err
 	}
 
-	err = listhelpers.ListApps(cliConnection, appsGetter, &listAppsCommand)
+	listAppsCommand.Output = command.Output.Value
+	listAppsCommand.Columns = command.Columns.Columns
+
+	nameFilter := diegohelpers.NewAppNameFilter(command.Include, command.Exclude)
+
+	err = listhelpers.ListApps(cliConnection, appsGetter, nameFilter, &listAppsCommand)
 	if err != nil {
 		return err
 	}
@@ -2,7 +2,9 @@ package commands_test
 
 import (
 	. "github.com/cloudfoundry-incubator/diego-enabler/commands"
-	"github.com/cloudfoundry-incubator/diego-enabler/commands/errorhelpers"
+	"github.com/cloudfoundry-incubator/diego-enabler/commands/diegohelpers"
+	"github.com/cloudfoundry-incubator/diego-enabler/commands/flaghelpers"
+	"github.com/cloudfoundry/cli/plugin/models"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -19,16 +21,34 @@ var _ = Describe("DiegoApps", func() {
 		err = command.Execute([]string{})
 	})
 
-	Context("when both organization and space are passed", func() {
+	Context("when a space is not in the organization it is given with", func() {
+		BeforeEach(func() {
+			fakeConnection.GetOrgReturns(plugin_models.GetOrg_Model{
+				Guid:   "some-organization-guid",
+				Name:   "some-organization",
+				Spaces: []plugin_models.GetOrg_Space{{Guid: "other-space-guid", Name: "other-space"}},
+			}, nil)
+
+			command = DiegoAppsCommand{
+				Organizations: []string{"some-organization"},
+				Spaces:        []flaghelpers.SpaceFlag{{Organization: "some-organization", Space: "some-space"}},
+			}
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(Equal(diegohelpers.SpaceNotFoundErr{SpaceName: "some-organization/some-space"}))
+		})
+	})
+
+	Context("when an organization cannot be found", func() {
 		BeforeEach(func() {
 			command = DiegoAppsCommand{
-				Space:        "some-space",
-				Organization: "some-organization",
+				Organizations: []string{"some-organization"},
 			}
 		})
 
 		It("returns an error", func() {
-			Expect(err).To(Equal(errorhelpers.SpecifyOrgOrSpaceError))
+			Expect(err).To(Equal(diegohelpers.OrgNotFoundErr{OrganizationName: "some-organization"}))
 		})
 	})
 })
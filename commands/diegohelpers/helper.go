@@ -6,34 +6,54 @@ import (
 	"strings"
 
 	"github.com/cloudfoundry-incubator/diego-enabler/api"
+	"github.com/cloudfoundry-incubator/diego-enabler/commands/flaghelpers"
+	"github.com/cloudfoundry-incubator/diego-enabler/commands/migratehelpers"
 	"github.com/cloudfoundry-incubator/diego-enabler/diegosupport"
+	"github.com/cloudfoundry-incubator/diego-enabler/models"
 	"github.com/cloudfoundry-incubator/diego-enabler/thingdoer"
 	"github.com/cloudfoundry-incubator/diego-enabler/ui"
+	"github.com/cloudfoundry/cli/plugin/models"
 )
 
-func ToggleDiegoSupport(on bool, cliConnection api.Connection, appName string) error {
-	d := diegosupport.NewDiegoSupport(cliConnection)
+type ToggleOptions struct {
+	RollbackOnFailure bool
+	DryRun            bool
+}
+
+func ToggleDiegoSupport(on bool, cliConnection api.Connection, cc diegosupport.CloudController, appName string, options ToggleOptions) error {
+	d := diegosupport.NewDiegoSupport(cliConnection, cc)
 
-	fmt.Printf("Setting %s Diego support to %t\n", appName, on)
-	app, err := cliConnection.GetApp(appName)
+	if options.DryRun {
+		fmt.Printf("Checking what setting %s Diego support to %t would do\n", appName, on)
+	} else {
+		fmt.Printf("Setting %s Diego support to %t\n", appName, on)
+	}
+	app, err := d.FindApp(appName)
 	if err != nil {
 		return err
 	}
 
+	wasOn := app.Diego
+
 	if on {
-		err = d.WarnNoRoutes(appName, os.Stdout)
+		err = d.WarnNoRoutes(app, os.Stdout)
 		if err != nil {
 			return err
 		}
 	}
 
-	if output, err := d.SetDiegoFlag(app.Guid, on); err != nil {
-		return fmt.Errorf("%s\n%s", err, strings.Join(output, "\n"))
+	if options.DryRun {
+		sayDryRun(app, on)
+		return nil
+	}
+
+	if err := d.SetDiegoFlag(app.Guid, on); err != nil {
+		return err
 	}
 	ui.SayOK()
 
 	fmt.Printf("Verifying %s Diego support is set to %t\n", appName, on)
-	app, err = cliConnection.GetApp(appName)
+	app, err = d.FindApp(appName)
 	if err != nil {
 		return err
 	}
@@ -44,17 +64,56 @@ func ToggleDiegoSupport(on bool, cliConnection api.Connection, appName string) e
 		return fmt.Errorf("Diego support for %s is NOT set to %t\n\n", appName, on)
 	}
 
+	if options.RollbackOnFailure && wasOn != on && strings.EqualFold(app.State, models.Started) {
+		return waitOrRollback(d, app, wasOn)
+	}
+
 	return nil
 }
 
-func IsDiegoEnabled(cliConnection api.Connection, appName string) error {
-	app, err := cliConnection.GetApp(appName)
-	if err != nil {
+func sayDryRun(app models.Application, on bool) {
+	switch {
+	case app.Diego == on:
+		fmt.Printf("Dry run: Diego support for %s is already set to %t, nothing would change\n", app.Name, on)
+	case strings.EqualFold(app.State, models.Started):
+		fmt.Printf("Dry run: Diego support for %s would be set to %t and the app would be restarted\n", app.Name, on)
+	default:
+		fmt.Printf("Dry run: Diego support for %s would be set to %t; the app is stopped and would not be started\n", app.Name, on)
+	}
+	ui.SayOK()
+}
+
+func waitOrRollback(d *diegosupport.DiegoSupport, app models.Application, wasOn bool) error {
+	startupTimeout := migratehelpers.StartupTimeout()
+	printDot := func() { fmt.Print(".") }
+
+	fmt.Printf("Waiting for %s to start\n", app.Name)
+	result := migratehelpers.WaitForRunning(d, app.Guid, app.InstanceCount, startupTimeout, migratehelpers.DefaultPollInterval, printDot)
+	if result == migratehelpers.Success {
+		fmt.Println()
+		ui.SayOK()
+		return nil
+	}
+
+	fmt.Println()
+	fmt.Printf("%s failed to start, rolling back Diego support to %t\n", app.Name, wasOn)
+	if err := d.SetDiegoFlag(app.Guid, wasOn); err != nil {
 		return err
 	}
 
-	if app.Guid == "" {
-		return fmt.Errorf("App %s not found\n\n", appName)
+	result = migratehelpers.WaitForRunning(d, app.Guid, app.InstanceCount, startupTimeout, migratehelpers.DefaultPollInterval, printDot)
+	fmt.Println()
+	if result != migratehelpers.Success {
+		return fmt.Errorf("App %s failed to start after rolling back Diego support to %t\n\n", app.Name, wasOn)
+	}
+
+	return fmt.Errorf("App %s failed to start; Diego support was rolled back to %t\n\n", app.Name, wasOn)
+}
+
+func IsDiegoEnabled(cliConnection api.Connection, cc diegosupport.CloudController, appName string) error {
+	app, err := diegosupport.NewDiegoSupport(cliConnection, cc).FindApp(appName)
+	if err != nil {
+		return err
 	}
 
 	fmt.Println(app.Diego)
@@ -78,28 +137,149 @@ func (e SpaceNotFoundErr) Error() string {
 	return fmt.Sprintf("Space not found: %s", e.SpaceName)
 }
 
-func NewAppsGetterFunc(
-	cliConnection api.Connection,
-	orgName string,
-	spaceName string,
-	runtime ui.Runtime,
-) (thingdoer.AppsGetterFunc, error) {
-	diegoAppsCommand := thingdoer.AppsGetter{CliConnection: cliConnection}
+func NewAppNameFilter(include []flaghelpers.AppNamePattern, exclude []flaghelpers.AppNamePattern) thingdoer.AppNameFilter {
+	var filter thingdoer.AppNameFilter
+
+	for _, pattern := range include {
+		filter.Include = append(filter.Include, pattern)
+	}
+
+	for _, pattern := range exclude {
+		filter.Exclude = append(filter.Exclude, pattern)
+	}
+
+	return filter
+}
+
+// AppsFilter narrows down the apps the Cloud Controller returns, on top of
+// the org or space.
+type AppsFilter struct {
+	State         flaghelpers.StateFlag
+	UpdatedSince  flaghelpers.TimeFlag
+	CreatedBefore flaghelpers.TimeFlag
+}
+
+func (f AppsFilter) apply(appsGetter *thingdoer.AppsGetter) {
+	appsGetter.State = f.State.Value
+	appsGetter.UpdatedSince = f.UpdatedSince.Value
+	appsGetter.CreatedBefore = f.CreatedBefore.Value
+}
+
+// AppsScope is the orgs and spaces given with -o and -s to get apps from.
+// All apps visible to the user are in scope when it is empty.
+type AppsScope struct {
+	Organizations []string
+	Spaces        []flaghelpers.SpaceFlag
+}
+
+// ResolvedScope holds the GUIDs of the orgs and spaces of an AppsScope, and
+// their names to show the user.
+type ResolvedScope struct {
+	OrganizationGuids []string
+	SpaceGuids        []string
+	Scopes            []ui.Scope
+}
+
+// Resolve looks up the orgs and spaces of the scope. A space without an org
+// is a space in the targeted org.
+func (s AppsScope) Resolve(cliConnection api.Connection) (ResolvedScope, error) {
+	var resolved ResolvedScope
+	orgs := map[string]plugin_models.GetOrg_Model{}
+
+	getOrg := func(orgName string) (plugin_models.GetOrg_Model, error) {
+		if org, ok := orgs[orgName]; ok {
+			return org, nil
+		}
 
-	if orgName != "" {
 		org, err := cliConnection.GetOrg(orgName)
 		if err != nil || org.Guid == "" {
-			return nil, OrgNotFoundErr{OrganizationName: orgName}
+			return org, OrgNotFoundErr{OrganizationName: orgName}
 		}
-		diegoAppsCommand.OrganizationGuid = org.Guid
-	} else if spaceName != "" {
-		space, err := cliConnection.GetSpace(spaceName)
-		if err != nil || space.Guid == "" {
-			return nil, SpaceNotFoundErr{SpaceName: spaceName}
+		orgs[orgName] = org
+		return org, nil
+	}
+
+	for _, orgName := range s.Organizations {
+		org, err := getOrg(orgName)
+		if err != nil {
+			return resolved, err
+		}
+		resolved.OrganizationGuids = append(resolved.OrganizationGuids, org.Guid)
+		resolved.Scopes = append(resolved.Scopes, ui.Scope{Organization: org.Name})
+	}
+
+	for _, spaceFlag := range s.Spaces {
+		if spaceFlag.Organization == "" {
+			space, err := cliConnection.GetSpace(spaceFlag.Space)
+			if err != nil || space.Guid == "" {
+				return resolved, SpaceNotFoundErr{SpaceName: spaceFlag.String()}
+			}
+			resolved.SpaceGuids = append(resolved.SpaceGuids, space.Guid)
+			resolved.Scopes = append(resolved.Scopes, ui.Scope{Organization: space.Organization.Name, Space: space.Name})
+			continue
+		}
+
+		org, err := getOrg(spaceFlag.Organization)
+		if err != nil {
+			return resolved, err
+		}
+
+		found := false
+		for _, space := range org.Spaces {
+			if space.Name == spaceFlag.Space {
+				resolved.SpaceGuids = append(resolved.SpaceGuids, space.Guid)
+				resolved.Scopes = append(resolved.Scopes, ui.Scope{Organization: org.Name, Space: space.Name})
+				found = true
+				break
+			}
+		}
+		if !found {
+			return resolved, SpaceNotFoundErr{SpaceName: spaceFlag.String()}
 		}
-		diegoAppsCommand.SpaceGuid = space.Guid
 	}
 
+	return resolved, nil
+}
+
+// NoSpaceTargetedErr is returned for commands that default to the targeted
+// space when no space is targeted.
+type NoSpaceTargetedErr struct{}
+
+func (e NoSpaceTargetedErr) Error() string {
+	return "No space targeted, use 'cf target -s SPACE' to target a space"
+}
+
+// TargetedSpaceScope resolves the scope of the space the user targeted with
+// cf target.
+func TargetedSpaceScope(cliConnection api.Connection) (ResolvedScope, error) {
+	currentSpace, err := cliConnection.GetCurrentSpace()
+	if err != nil {
+		return ResolvedScope{}, err
+	}
+
+	if currentSpace.Name == "" {
+		return ResolvedScope{}, NoSpaceTargetedErr{}
+	}
+
+	return AppsScope{
+		Spaces: []flaghelpers.SpaceFlag{{Space: currentSpace.Name}},
+	}.Resolve(cliConnection)
+}
+
+func NewAppsGetterFunc(
+	cliConnection api.Connection,
+	scope ResolvedScope,
+	filter AppsFilter,
+	runtime ui.Runtime,
+	lookupRoutes bool,
+) (thingdoer.AppsGetterFunc, error) {
+	diegoAppsCommand, err := newAppsGetter(cliConnection, scope)
+	if err != nil {
+		return nil, err
+	}
+	filter.apply(&diegoAppsCommand)
+	diegoAppsCommand.LookupRoutes = lookupRoutes
+
 	var appsGetterFunc = diegoAppsCommand.DiegoApps
 	if runtime == ui.DEA {
 		appsGetterFunc = diegoAppsCommand.DeaApps
@@ -107,3 +287,43 @@ func NewAppsGetterFunc(
 
 	return appsGetterFunc, nil
 }
+
+// NewAllAppsGetterFunc gets the apps on both runtimes.
+func NewAllAppsGetterFunc(
+	cliConnection api.Connection,
+	scope ResolvedScope,
+	filter AppsFilter,
+	lookupRoutes bool,
+) (thingdoer.AppsGetterFunc, error) {
+	appsGetter, err := newAppsGetter(cliConnection, scope)
+	if err != nil {
+		return nil, err
+	}
+	filter.apply(&appsGetter)
+	appsGetter.LookupRoutes = lookupRoutes
+
+	return appsGetter.AllApps, nil
+}
+
+func newAppsGetter(cliConnection api.Connection, scope ResolvedScope) (thingdoer.AppsGetter, error) {
+	cc, err := api.NewCloudController(cliConnection)
+	if err != nil {
+		return thingdoer.AppsGetter{}, err
+	}
+
+	runtimeRequester, err := api.NewPaginatedRequester(cliConnection, cc.Requests.HandleFiltersAndParameters(
+		cc.Requests.Authorize(cc.Requests.NewGetV2AppsRequest),
+	))
+	if err != nil {
+		return thingdoer.AppsGetter{}, err
+	}
+
+	return thingdoer.AppsGetter{
+		OrganizationGuids: scope.OrganizationGuids,
+		SpaceGuids:        scope.SpaceGuids,
+		RouteCounter:      cc,
+		Backend:           cc.Requests.Backend,
+		RuntimeRequester:  runtimeRequester,
+		Processes:         cc,
+	}, nil
+}
@@ -1,29 +1,37 @@
 package diegohelpers_test
 
 import (
+	"github.com/cloudfoundry-incubator/diego-enabler/api"
 	"github.com/cloudfoundry-incubator/diego-enabler/api/apifakes"
 	. "github.com/cloudfoundry-incubator/diego-enabler/commands/diegohelpers"
+	"github.com/cloudfoundry-incubator/diego-enabler/commands/flaghelpers"
+	"github.com/cloudfoundry-incubator/diego-enabler/diegosupport/diegosupportfakes"
+	"github.com/cloudfoundry-incubator/diego-enabler/models"
+	"github.com/cloudfoundry-incubator/diego-enabler/ui"
+	"github.com/cloudfoundry/cli/plugin/models"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
 
 var _ = Describe("DiegoHelpers", func() {
 	var (
-		fakeApi *apifakes.FakeConnection
+		fakeApi             *apifakes.FakeConnection
+		fakeCloudController *diegosupportfakes.FakeCloudController
 	)
 
 	BeforeEach(func() {
 		fakeApi = new(apifakes.FakeConnection)
+		fakeCloudController = new(diegosupportfakes.FakeCloudController)
 	})
 
 	Describe("ToggleDiegoSupport", func() {
 		Context("when disabling diego", func() {
 			BeforeEach(func() {
-				ToggleDiegoSupport(false, fakeApi, "some-app")
+				ToggleDiegoSupport(false, fakeApi, fakeCloudController, "some-app", ToggleOptions{})
 			})
 
 			It("should not check that there are no routes", func() {
-				Expect(fakeApi.GetCurrentSpaceCallCount()).To(Equal(0))
+				Expect(fakeCloudController.CountAppRoutesCallCount()).To(Equal(0))
 				Expect(fakeApi.GetSpaceCallCount()).To(Equal(0))
 				Expect(fakeApi.UsernameCallCount()).To(Equal(0))
 			})
@@ -31,14 +39,132 @@ var _ = Describe("DiegoHelpers", func() {
 
 		Context("when enabling diego", func() {
 			BeforeEach(func() {
-				ToggleDiegoSupport(true, fakeApi, "some-app")
+				ToggleDiegoSupport(true, fakeApi, fakeCloudController, "some-app", ToggleOptions{})
 			})
 
-			It("should not check that there are no routes", func() {
-				Expect(fakeApi.GetCurrentSpaceCallCount()).To(Equal(1))
+			It("should check that there are no routes", func() {
+				Expect(fakeCloudController.CountAppRoutesCallCount()).To(Equal(1))
+				Expect(fakeApi.GetAppCallCount()).To(BeZero())
 				Expect(fakeApi.GetSpaceCallCount()).To(Equal(1))
 				Expect(fakeApi.UsernameCallCount()).To(Equal(1))
 			})
 		})
+
+		Context("when doing a dry run", func() {
+			var err error
+
+			BeforeEach(func() {
+				fakeCloudController.FindAppReturns(models.Application{
+					ApplicationEntity:   models.ApplicationEntity{Name: "some-app", State: "started"},
+					ApplicationMetadata: models.ApplicationMetadata{Guid: "some-app-guid"},
+				}, nil)
+				fakeCloudController.CountAppRoutesReturns(1, nil)
+
+				err = ToggleDiegoSupport(true, fakeApi, fakeCloudController, "some-app", ToggleOptions{DryRun: true})
+			})
+
+			It("does not set the diego flag", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeCloudController.UpdateAppCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when rolling back on failure", func() {
+			var (
+				diegoFlag bool
+				err       error
+			)
+
+			BeforeEach(func() {
+				diegoFlag = false
+				fakeCloudController.FindAppStub = func(string, string) (models.Application, error) {
+					return models.Application{
+						ApplicationEntity: models.ApplicationEntity{
+							Name:          "some-app",
+							Diego:         diegoFlag,
+							State:         "started",
+							InstanceCount: 1,
+						},
+						ApplicationMetadata: models.ApplicationMetadata{Guid: "some-app-guid"},
+					}, nil
+				}
+				fakeCloudController.CountAppRoutesReturns(1, nil)
+				fakeCloudController.UpdateAppStub = func(appGUID string, update api.AppUpdate) (models.Application, error) {
+					diegoFlag = *update.Diego
+					return models.Application{}, nil
+				}
+				fakeCloudController.GetAppInstancesStub = func(string) (map[string]api.AppInstance, error) {
+					if diegoFlag {
+						return map[string]api.AppInstance{"0": {State: "CRASHED"}}, nil
+					}
+					return map[string]api.AppInstance{"0": {State: "RUNNING"}}, nil
+				}
+			})
+
+			JustBeforeEach(func() {
+				err = ToggleDiegoSupport(true, fakeApi, fakeCloudController, "some-app", ToggleOptions{RollbackOnFailure: true})
+			})
+
+			It("sets the diego flag back to its original value", func() {
+				Expect(err).To(MatchError(ContainSubstring("Diego support was rolled back to false")))
+				Expect(diegoFlag).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("AppsScope", func() {
+		BeforeEach(func() {
+			fakeApi.GetOrgStub = func(name string) (plugin_models.GetOrg_Model, error) {
+				return plugin_models.GetOrg_Model{
+					Guid: name + "-guid",
+					Name: name,
+					Spaces: []plugin_models.GetOrg_Space{
+						{Guid: name + "-dev-guid", Name: "dev"},
+						{Guid: name + "-prod-guid", Name: "prod"},
+					},
+				}, nil
+			}
+			fakeApi.GetSpaceReturns(plugin_models.GetSpace_Model{
+				GetSpaces_Model: plugin_models.GetSpaces_Model{Guid: "targeted-space-guid", Name: "staging"},
+				Organization:    plugin_models.GetSpace_Orgs{Name: "targeted-org"},
+			}, nil)
+		})
+
+		It("looks up the orgs and the spaces in any org", func() {
+			scope, err := AppsScope{
+				Organizations: []string{"org-a"},
+				Spaces: []flaghelpers.SpaceFlag{
+					{Organization: "org-b", Space: "dev"},
+					{Organization: "org-b", Space: "prod"},
+					{Space: "staging"},
+				},
+			}.Resolve(fakeApi)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(scope.OrganizationGuids).To(Equal([]string{"org-a-guid"}))
+			Expect(scope.SpaceGuids).To(Equal([]string{"org-b-dev-guid", "org-b-prod-guid", "targeted-space-guid"}))
+			Expect(scope.Scopes).To(Equal([]ui.Scope{
+				{Organization: "org-a"},
+				{Organization: "org-b", Space: "dev"},
+				{Organization: "org-b", Space: "prod"},
+				{Organization: "targeted-org", Space: "staging"},
+			}))
+
+			Expect(fakeApi.GetOrgCallCount()).To(Equal(2))
+			Expect(fakeApi.GetSpaceArgsForCall(0)).To(Equal("staging"))
+		})
+
+		It("is empty without orgs and spaces", func() {
+			scope, err := AppsScope{}.Resolve(fakeApi)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(scope).To(Equal(ResolvedScope{}))
+		})
+
+		It("returns an error for a space that is not in its org", func() {
+			_, err := AppsScope{
+				Spaces: []flaghelpers.SpaceFlag{{Organization: "org-b", Space: "qa"}},
+			}.Resolve(fakeApi)
+			Expect(err).To(Equal(SpaceNotFoundErr{SpaceName: "org-b/qa"}))
+		})
 	})
 })
@@ -1,9 +1,14 @@
 package commands
 
-import "github.com/cloudfoundry-incubator/diego-enabler/commands/diegohelpers"
+import (
+	"github.com/cloudfoundry-incubator/diego-enabler/api"
+	"github.com/cloudfoundry-incubator/diego-enabler/commands/diegohelpers"
+)
 
 type DisableDiegoCommand struct {
 	RequiredOptions DisableDiegoPositionalArgs `positional-args:"yes"`
+	Rollback        bool                       `long:"rollback-on-failure" description:"Migrate the app back to its original runtime if it fails to start"`
+	DryRun          bool                       `long:"dry-run" description:"Report what would change without changing the app"`
 }
 
 type DisableDiegoPositionalArgs struct {
@@ -11,5 +16,13 @@ type DisableDiegoPositionalArgs struct {
 }
 
 func (command DisableDiegoCommand) Execute([]string) error {
-	return diegohelpers.ToggleDiegoSupport(false, DiegoEnabler.CLIConnection, command.RequiredOptions.AppName)
+	cc, err := api.NewCloudController(DiegoEnabler.CLIConnection)
+	if err != nil {
+		return err
+	}
+
+	return diegohelpers.ToggleDiegoSupport(false, DiegoEnabler.CLIConnection, cc, command.RequiredOptions.AppName, diegohelpers.ToggleOptions{
+		RollbackOnFailure: command.Rollback,
+		DryRun:            command.DryRun,
+	})
 }
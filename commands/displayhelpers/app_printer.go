@@ -1,10 +1,17 @@
 package displayhelpers
 
-import "github.com/cloudfoundry-incubator/diego-enabler/models"
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/cloudfoundry-incubator/diego-enabler/models"
+	"github.com/cloudfoundry-incubator/diego-enabler/ui"
+)
 
 type AppPrinter struct {
 	App    models.Application
 	Spaces map[string]models.Space
+	Stacks map[string]models.Stack
 }
 
 func (a *AppPrinter) Name() string {
@@ -49,3 +56,71 @@ func (a *AppPrinter) Space() string {
 
 	return display
 }
+
+func (a *AppPrinter) Guid() string {
+	return a.App.Guid
+}
+
+func (a *AppPrinter) OrganizationGuid() string {
+	space, ok := a.Spaces[a.App.SpaceGuid]
+	if !ok {
+		return ""
+	}
+
+	if space.OrganizationGuid != "" {
+		return space.OrganizationGuid
+	}
+
+	return space.Organization.Guid
+}
+
+func (a *AppPrinter) SpaceGuid() string {
+	return a.App.SpaceGuid
+}
+
+func (a *AppPrinter) Runtime() ui.Runtime {
+	if a.App.Diego {
+		return ui.Diego
+	}
+	return ui.DEA
+}
+
+// Column renders one of the extra listing columns in ui.Columns.
+func (a *AppPrinter) Column(name string) string {
+	app := a.App
+
+	switch name {
+	case ui.ColumnState:
+		return app.State
+	case ui.ColumnInstances:
+		return strconv.Itoa(app.InstanceCount)
+	case ui.ColumnMemory:
+		return ui.FormatMegabytes(app.Memory)
+	case ui.ColumnDisk:
+		return ui.FormatMegabytes(app.DiskQuota)
+	case ui.ColumnStack:
+		if stack, ok := a.Stacks[app.StackGuid]; ok {
+			return stack.Name
+		}
+		if app.StackName != "" {
+			return app.StackName
+		}
+		return app.StackGuid
+	case ui.ColumnBuildpack:
+		if app.Buildpack != "" {
+			return app.Buildpack
+		}
+		return app.DetectedBuildpack
+	case ui.ColumnRoutes:
+		return strconv.Itoa(app.RouteCount)
+	case ui.ColumnHealthCheck:
+		if app.HealthCheckTimeout > 0 {
+			return fmt.Sprintf("%s (%ds)", app.HealthCheckType, app.HealthCheckTimeout)
+		}
+		return app.HealthCheckType
+	case ui.ColumnPackageState:
+		return app.PackageState
+	default:
+		return ""
+	}
+}
@@ -10,7 +10,9 @@ type Enabler struct {
 	HasDiegoEnabled HasDiegoEnabledCommand `command:"has-diego-enabled" description:"Check if Diego support is enabled for an app"`
 	DiegoApps       DiegoAppsCommand       `command:"diego-apps" description:"Lists all apps running on the Diego runtime that are visible to the user"`
 	DeaApps         DeaAppsCommand         `command:"dea-apps" description:"Lists all apps running on the DEA runtime that are visible to the user"`
-	MigrateApps     MigrateAppsCommand     `command:"migrate-apps" description:"Migrate all apps to Diego/DEA"`
+	AppsByRuntime   AppsByRuntimeCommand   `command:"apps-by-runtime" description:"Lists all apps visible to the user with the runtime each one runs on"`
+	MigrateApps     MigrateAppsCommand     `command:"migrate-apps" description:"Migrate the apps in the targeted space, or in the given orgs and spaces, to Diego/DEA"`
+	RuntimeSummary  RuntimeSummaryCommand  `command:"runtime-summary" description:"Summarize the apps, instances and memory on each runtime per org and space"`
 	UninstallPlugin UninstallHook          `command:"CLI-MESSAGE-UNINSTALL"`
 }
 
@@ -1,12 +1,25 @@
 package errorhelpers
 
-import "errors"
+import (
+	"errors"
 
-var SpecifyOrgOrSpaceError = errors.New("Cannot specify org together with space.")
+	"github.com/cloudfoundry-incubator/diego-enabler/commands/flaghelpers"
+)
 
-func ErrorIfOrgAndSpacesSet(orgName, spaceName string) error {
-	if orgName != "" && spaceName != "" {
-		return SpecifyOrgOrSpaceError
+var SpecifyAllOrgsOrScopeError = errors.New("Cannot specify all-orgs together with org or space.")
+
+var SpecifyPlanOrWritePlanError = errors.New("Cannot specify plan together with write-plan.")
+
+func ErrorIfPlanAndWritePlanSet(plan, writePlan string) error {
+	if plan != "" && writePlan != "" {
+		return SpecifyPlanOrWritePlanError
+	}
+	return nil
+}
+
+func ErrorIfAllOrgsAndScopeSet(allOrgs bool, orgNames []string, spaces []flaghelpers.SpaceFlag) error {
+	if allOrgs && (len(orgNames) > 0 || len(spaces) > 0) {
+		return SpecifyAllOrgsOrScopeError
 	}
 	return nil
 }
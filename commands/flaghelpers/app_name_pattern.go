@@ -0,0 +1,54 @@
+package flaghelpers
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// AppNamePattern matches app names against a glob such as 'payments-*', or
+// against a regular expression when the pattern is wrapped in slashes, such
+// as '/-legacy$/'.
+type AppNamePattern struct {
+	Pattern string
+	regexp  *regexp.Regexp
+}
+
+func (flag *AppNamePattern) UnmarshalFlag(value string) error {
+	if len(value) > 1 && strings.HasPrefix(value, "/") && strings.HasSuffix(value, "/") {
+		re, err := regexp.Compile(value[1 : len(value)-1])
+		if err != nil {
+			return InvalidAppNamePatternError{PassedValue: value, Err: err}
+		}
+
+		flag.Pattern = value
+		flag.regexp = re
+		return nil
+	}
+
+	if _, err := path.Match(value, ""); err != nil {
+		return InvalidAppNamePatternError{PassedValue: value, Err: err}
+	}
+
+	flag.Pattern = value
+	return nil
+}
+
+func (flag AppNamePattern) Match(name string) bool {
+	if flag.regexp != nil {
+		return flag.regexp.MatchString(name)
+	}
+
+	matched, _ := path.Match(flag.Pattern, name)
+	return matched
+}
+
+type InvalidAppNamePatternError struct {
+	PassedValue string
+	Err         error
+}
+
+func (e InvalidAppNamePatternError) Error() string {
+	return fmt.Sprintf("Invalid app name pattern: %s\n%s", e.PassedValue, e.Err)
+}
@@ -0,0 +1,49 @@
+package flaghelpers_test
+
+import (
+	. "github.com/cloudfoundry-incubator/diego-enabler/commands/flaghelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AppNamePattern", func() {
+	var pattern AppNamePattern
+	BeforeEach(func() {
+		pattern = AppNamePattern{}
+	})
+
+	Describe("globs", func() {
+		It("matches app names", func() {
+			Expect(pattern.UnmarshalFlag("*-legacy")).ToNot(HaveOccurred())
+			Expect(pattern.Match("billing-legacy")).To(BeTrue())
+			Expect(pattern.Match("billing")).To(BeFalse())
+		})
+
+		It("matches whole app names", func() {
+			Expect(pattern.UnmarshalFlag("payments")).ToNot(HaveOccurred())
+			Expect(pattern.Match("payments")).To(BeTrue())
+			Expect(pattern.Match("payments-api")).To(BeFalse())
+		})
+
+		It("returns an error for malformed globs", func() {
+			err := pattern.UnmarshalFlag("payments-[")
+			_, ok := err.(InvalidAppNamePatternError)
+			Expect(ok).To(BeTrue())
+		})
+	})
+
+	Describe("regular expressions", func() {
+		It("matches app names", func() {
+			Expect(pattern.UnmarshalFlag("/^payments-(api|worker)$/")).ToNot(HaveOccurred())
+			Expect(pattern.Match("payments-api")).To(BeTrue())
+			Expect(pattern.Match("payments-ui")).To(BeFalse())
+		})
+
+		It("returns an error for malformed regular expressions", func() {
+			err := pattern.UnmarshalFlag("/payments-(/")
+			_, ok := err.(InvalidAppNamePatternError)
+			Expect(ok).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,58 @@
+package flaghelpers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CanaryFlag is either a number of apps or, when it ends in %, a percentage
+// of all apps.
+type CanaryFlag struct {
+	Count   int
+	Percent int
+}
+
+func (flag *CanaryFlag) UnmarshalFlag(value string) error {
+	if strings.HasSuffix(value, "%") {
+		percent, err := strconv.Atoi(strings.TrimSuffix(value, "%"))
+		if err != nil || percent <= 0 || percent > 100 {
+			return InvalidCanaryValueError{PassedValue: value}
+		}
+
+		flag.Percent = percent
+		return nil
+	}
+
+	count, err := strconv.Atoi(value)
+	if err != nil || count <= 0 {
+		return InvalidCanaryValueError{PassedValue: value}
+	}
+
+	flag.Count = count
+	return nil
+}
+
+// Size returns how many of total apps belong to the canary, rounding
+// percentages up so a canary is never empty.
+func (flag CanaryFlag) Size(total int) int {
+	if flag.Percent > 0 {
+		return (total*flag.Percent + 99) / 100
+	}
+
+	if flag.Count > total {
+		return total
+	}
+	return flag.Count
+}
+
+type InvalidCanaryValueError struct {
+	PassedValue string
+}
+
+func (e InvalidCanaryValueError) Error() string {
+	return fmt.Sprintf(
+		"Invalid canary: %s\nValue for CANARY must be a positive integer or a percentage between 1%% and 100%%",
+		e.PassedValue,
+	)
+}
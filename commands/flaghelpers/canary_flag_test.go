@@ -0,0 +1,55 @@
+package flaghelpers_test
+
+import (
+	. "github.com/cloudfoundry-incubator/diego-enabler/commands/flaghelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CanaryFlag", func() {
+	var canaryFlag CanaryFlag
+	BeforeEach(func() {
+		canaryFlag = CanaryFlag{}
+	})
+
+	Describe("a number of apps", func() {
+		It("does not error", func() {
+			Expect(canaryFlag.UnmarshalFlag("5")).ToNot(HaveOccurred())
+			Expect(canaryFlag.Size(100)).To(Equal(5))
+		})
+
+		It("is never larger than the number of apps", func() {
+			Expect(canaryFlag.UnmarshalFlag("5")).ToNot(HaveOccurred())
+			Expect(canaryFlag.Size(3)).To(Equal(3))
+		})
+	})
+
+	Describe("a percentage of apps", func() {
+		It("does not error", func() {
+			Expect(canaryFlag.UnmarshalFlag("10%")).ToNot(HaveOccurred())
+			Expect(canaryFlag.Size(200)).To(Equal(20))
+		})
+
+		It("rounds up", func() {
+			Expect(canaryFlag.UnmarshalFlag("10%")).ToNot(HaveOccurred())
+			Expect(canaryFlag.Size(5)).To(Equal(1))
+		})
+	})
+
+	Describe("no canary", func() {
+		It("has a size of zero", func() {
+			Expect(canaryFlag.Size(100)).To(Equal(0))
+		})
+	})
+
+	Describe("invalid values", func() {
+		It("returns an error", func() {
+			for _, value := range []string{"0", "-1", "banana", "0%", "101%", "%"} {
+				err := canaryFlag.UnmarshalFlag(value)
+				_, ok := err.(InvalidCanaryValueError)
+				Expect(ok).To(BeTrue(), value)
+			}
+		})
+	})
+})
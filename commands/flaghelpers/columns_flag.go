@@ -0,0 +1,60 @@
+package flaghelpers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/diego-enabler/ui"
+)
+
+type ColumnsFlag struct {
+	Columns []string
+}
+
+func (flag *ColumnsFlag) UnmarshalFlag(value string) error {
+	flag.Columns = nil
+
+	for _, column := range strings.Split(value, ",") {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if column == "" || flag.Includes(column) {
+			continue
+		}
+
+		if !isColumn(column) {
+			return InvalidColumnError{PassedValue: column}
+		}
+		flag.Columns = append(flag.Columns, column)
+	}
+
+	return nil
+}
+
+func (flag ColumnsFlag) Includes(column string) bool {
+	for _, c := range flag.Columns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+func isColumn(column string) bool {
+	for _, c := range ui.Columns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+type InvalidColumnError struct {
+	PassedValue string
+}
+
+func (e InvalidColumnError) Error() string {
+	return fmt.Sprintf(
+		"Invalid column: %s\nValue for COLUMNS must be a comma separated list of %s",
+		e.PassedValue,
+		strings.Join(ui.Columns, ", "),
+	)
+}
@@ -0,0 +1,32 @@
+package flaghelpers_test
+
+import (
+	. "github.com/cloudfoundry-incubator/diego-enabler/commands/flaghelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ColumnsFlag", func() {
+	var columnsFlag ColumnsFlag
+	BeforeEach(func() {
+		columnsFlag = ColumnsFlag{}
+	})
+
+	It("accepts a comma separated list of columns", func() {
+		Expect(columnsFlag.UnmarshalFlag("state, Instances,memory,stack,routes,health-check")).ToNot(HaveOccurred())
+		Expect(columnsFlag.Columns).To(Equal([]string{"state", "instances", "memory", "stack", "routes", "health-check"}))
+		Expect(columnsFlag.Includes("stack")).To(BeTrue())
+		Expect(columnsFlag.Includes("disk")).To(BeFalse())
+	})
+
+	It("ignores empty and repeated columns", func() {
+		Expect(columnsFlag.UnmarshalFlag("state,,state")).ToNot(HaveOccurred())
+		Expect(columnsFlag.Columns).To(Equal([]string{"state"}))
+	})
+
+	It("returns an error for an unknown column", func() {
+		err := columnsFlag.UnmarshalFlag("state,color")
+		Expect(err).To(Equal(InvalidColumnError{PassedValue: "color"}))
+	})
+})
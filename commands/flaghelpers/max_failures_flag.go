@@ -0,0 +1,33 @@
+package flaghelpers
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// MaxFailuresFlag is the number of failed apps after which no new apps are
+// migrated. Zero never stops.
+type MaxFailuresFlag struct {
+	Value int
+}
+
+func (flag *MaxFailuresFlag) UnmarshalFlag(value string) error {
+	val, err := strconv.Atoi(value)
+	if err != nil || val < 0 {
+		return InvalidMaxFailuresValueError{PassedValue: value}
+	}
+
+	flag.Value = val
+	return nil
+}
+
+type InvalidMaxFailuresValueError struct {
+	PassedValue string
+}
+
+func (e InvalidMaxFailuresValueError) Error() string {
+	return fmt.Sprintf(
+		"Invalid maximum failures: %s\nValue for K must be a positive integer, or 0 to never stop",
+		e.PassedValue,
+	)
+}
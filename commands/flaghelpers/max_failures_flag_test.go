@@ -0,0 +1,45 @@
+package flaghelpers_test
+
+import (
+	. "github.com/cloudfoundry-incubator/diego-enabler/commands/flaghelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MaxFailuresFlag", func() {
+	var maxFailuresFlag MaxFailuresFlag
+	BeforeEach(func() {
+		maxFailuresFlag = MaxFailuresFlag{}
+	})
+
+	Describe("positive values", func() {
+		It("does not error", func() {
+			Expect(maxFailuresFlag.UnmarshalFlag("3")).ToNot(HaveOccurred())
+			Expect(maxFailuresFlag.Value).To(Equal(3))
+		})
+	})
+
+	Describe("zero", func() {
+		It("does not error", func() {
+			Expect(maxFailuresFlag.UnmarshalFlag("0")).ToNot(HaveOccurred())
+			Expect(maxFailuresFlag.Value).To(Equal(0))
+		})
+	})
+
+	Describe("negative values", func() {
+		It("returns an error", func() {
+			err := maxFailuresFlag.UnmarshalFlag("-1")
+			Expect(err).To(MatchError(InvalidMaxFailuresValueError{PassedValue: "-1"}))
+			Expect(err.Error()).To(ContainSubstring("Invalid maximum failures: -1"))
+		})
+	})
+
+	Describe("non-number values", func() {
+		It("returns an error", func() {
+			err := maxFailuresFlag.UnmarshalFlag("banana")
+			_, ok := err.(InvalidMaxFailuresValueError)
+			Expect(ok).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,34 @@
+package flaghelpers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/diego-enabler/ui"
+)
+
+type OutputFormatFlag struct {
+	Value string
+}
+
+func (flag *OutputFormatFlag) UnmarshalFlag(value string) error {
+	switch format := strings.ToLower(value); format {
+	case ui.OutputTable, ui.OutputJSON, ui.OutputCSV, ui.OutputYAML:
+		flag.Value = format
+	default:
+		return InvalidOutputFormatError{PassedValue: value}
+	}
+
+	return nil
+}
+
+type InvalidOutputFormatError struct {
+	PassedValue string
+}
+
+func (e InvalidOutputFormatError) Error() string {
+	return fmt.Sprintf(
+		"Invalid output format: %s\nValue for FORMAT must be table, json, csv or yaml",
+		e.PassedValue,
+	)
+}
@@ -0,0 +1,33 @@
+package flaghelpers_test
+
+import (
+	. "github.com/cloudfoundry-incubator/diego-enabler/commands/flaghelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OutputFormatFlag", func() {
+	var formatFlag OutputFormatFlag
+	BeforeEach(func() {
+		formatFlag = OutputFormatFlag{}
+	})
+
+	It("accepts table, json, csv and yaml", func() {
+		for _, format := range []string{"table", "json", "csv", "yaml"} {
+			Expect(formatFlag.UnmarshalFlag(format)).ToNot(HaveOccurred())
+			Expect(formatFlag.Value).To(Equal(format))
+		}
+	})
+
+	It("accepts formats in any case", func() {
+		Expect(formatFlag.UnmarshalFlag("JSON")).ToNot(HaveOccurred())
+		Expect(formatFlag.Value).To(Equal("json"))
+	})
+
+	It("returns an error for any other format", func() {
+		err := formatFlag.UnmarshalFlag("xml")
+		_, ok := err.(InvalidOutputFormatError)
+		Expect(ok).To(BeTrue())
+	})
+})
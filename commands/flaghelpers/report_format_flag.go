@@ -0,0 +1,38 @@
+package flaghelpers
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	ReportJSON  = "json"
+	ReportCSV   = "csv"
+	ReportJUnit = "junit"
+)
+
+type ReportFormatFlag struct {
+	Value string
+}
+
+func (flag *ReportFormatFlag) UnmarshalFlag(value string) error {
+	switch format := strings.ToLower(value); format {
+	case ReportJSON, ReportCSV, ReportJUnit:
+		flag.Value = format
+	default:
+		return InvalidReportFormatError{PassedValue: value}
+	}
+
+	return nil
+}
+
+type InvalidReportFormatError struct {
+	PassedValue string
+}
+
+func (e InvalidReportFormatError) Error() string {
+	return fmt.Sprintf(
+		"Invalid report format: %s\nValue for FORMAT must be json, csv or junit",
+		e.PassedValue,
+	)
+}
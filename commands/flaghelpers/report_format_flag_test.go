@@ -0,0 +1,33 @@
+package flaghelpers_test
+
+import (
+	. "github.com/cloudfoundry-incubator/diego-enabler/commands/flaghelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ReportFormatFlag", func() {
+	var formatFlag ReportFormatFlag
+	BeforeEach(func() {
+		formatFlag = ReportFormatFlag{}
+	})
+
+	It("accepts json, csv and junit", func() {
+		for _, format := range []string{"json", "csv", "junit"} {
+			Expect(formatFlag.UnmarshalFlag(format)).ToNot(HaveOccurred())
+			Expect(formatFlag.Value).To(Equal(format))
+		}
+	})
+
+	It("accepts formats in any case", func() {
+		Expect(formatFlag.UnmarshalFlag("JUnit")).ToNot(HaveOccurred())
+		Expect(formatFlag.Value).To(Equal(ReportJUnit))
+	})
+
+	It("returns an error for any other format", func() {
+		err := formatFlag.UnmarshalFlag("xml")
+		_, ok := err.(InvalidReportFormatError)
+		Expect(ok).To(BeTrue())
+	})
+})
@@ -0,0 +1,60 @@
+package flaghelpers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/diego-enabler/ui"
+)
+
+type SortKeyFlag struct {
+	Value string
+}
+
+func (flag *SortKeyFlag) UnmarshalFlag(value string) error {
+	switch key := strings.ToLower(value); key {
+	case ui.SortByName, ui.SortByOrg, ui.SortBySpace, ui.SortByRuntime:
+		flag.Value = key
+	default:
+		return InvalidSortKeyError{PassedValue: value}
+	}
+
+	return nil
+}
+
+type InvalidSortKeyError struct {
+	PassedValue string
+}
+
+func (e InvalidSortKeyError) Error() string {
+	return fmt.Sprintf(
+		"Invalid sort key: %s\nValue for KEY must be name, org, space or runtime",
+		e.PassedValue,
+	)
+}
+
+type GroupKeyFlag struct {
+	Value string
+}
+
+func (flag *GroupKeyFlag) UnmarshalFlag(value string) error {
+	switch key := strings.ToLower(value); key {
+	case ui.SortByOrg, ui.SortBySpace, ui.SortByRuntime:
+		flag.Value = key
+	default:
+		return InvalidGroupKeyError{PassedValue: value}
+	}
+
+	return nil
+}
+
+type InvalidGroupKeyError struct {
+	PassedValue string
+}
+
+func (e InvalidGroupKeyError) Error() string {
+	return fmt.Sprintf(
+		"Invalid group key: %s\nValue for KEY must be org, space or runtime",
+		e.PassedValue,
+	)
+}
@@ -0,0 +1,49 @@
+package flaghelpers_test
+
+import (
+	. "github.com/cloudfoundry-incubator/diego-enabler/commands/flaghelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SortKeyFlag", func() {
+	var sortFlag SortKeyFlag
+	BeforeEach(func() {
+		sortFlag = SortKeyFlag{}
+	})
+
+	It("accepts name, org, space and runtime in any case", func() {
+		for _, key := range []string{"name", "org", "space", "runtime"} {
+			Expect(sortFlag.UnmarshalFlag(key)).ToNot(HaveOccurred())
+			Expect(sortFlag.Value).To(Equal(key))
+		}
+
+		Expect(sortFlag.UnmarshalFlag("Runtime")).ToNot(HaveOccurred())
+		Expect(sortFlag.Value).To(Equal("runtime"))
+	})
+
+	It("returns an error for any other key", func() {
+		err := sortFlag.UnmarshalFlag("memory")
+		Expect(err).To(Equal(InvalidSortKeyError{PassedValue: "memory"}))
+	})
+})
+
+var _ = Describe("GroupKeyFlag", func() {
+	var groupFlag GroupKeyFlag
+	BeforeEach(func() {
+		groupFlag = GroupKeyFlag{}
+	})
+
+	It("accepts org, space and runtime", func() {
+		for _, key := range []string{"org", "space", "runtime"} {
+			Expect(groupFlag.UnmarshalFlag(key)).ToNot(HaveOccurred())
+			Expect(groupFlag.Value).To(Equal(key))
+		}
+	})
+
+	It("does not group by name", func() {
+		err := groupFlag.UnmarshalFlag("name")
+		Expect(err).To(Equal(InvalidGroupKeyError{PassedValue: "name"}))
+	})
+})
@@ -0,0 +1,48 @@
+package flaghelpers
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SpaceFlag is a space given as SPACE, in the targeted org, or as ORG/SPACE.
+type SpaceFlag struct {
+	Organization string
+	Space        string
+}
+
+func (flag *SpaceFlag) UnmarshalFlag(value string) error {
+	org, space := "", value
+	if i := strings.Index(value, "/"); i >= 0 {
+		org, space = value[:i], value[i+1:]
+		if org == "" {
+			return InvalidSpaceError{PassedValue: value}
+		}
+	}
+
+	if space == "" || strings.Contains(space, "/") {
+		return InvalidSpaceError{PassedValue: value}
+	}
+
+	flag.Organization = org
+	flag.Space = space
+	return nil
+}
+
+func (flag SpaceFlag) String() string {
+	if flag.Organization == "" {
+		return flag.Space
+	}
+	return flag.Organization + "/" + flag.Space
+}
+
+type InvalidSpaceError struct {
+	PassedValue string
+}
+
+func (e InvalidSpaceError) Error() string {
+	return fmt.Sprintf(
+		"Invalid space: %s\nValue for SPACE must be a space in the targeted org or ORG/SPACE",
+		e.PassedValue,
+	)
+}
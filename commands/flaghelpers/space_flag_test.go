@@ -0,0 +1,37 @@
+package flaghelpers_test
+
+import (
+	. "github.com/cloudfoundry-incubator/diego-enabler/commands/flaghelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SpaceFlag", func() {
+	var spaceFlag SpaceFlag
+	BeforeEach(func() {
+		spaceFlag = SpaceFlag{}
+	})
+
+	It("accepts a space in the targeted org", func() {
+		Expect(spaceFlag.UnmarshalFlag("dev")).ToNot(HaveOccurred())
+		Expect(spaceFlag.Organization).To(BeEmpty())
+		Expect(spaceFlag.Space).To(Equal("dev"))
+		Expect(spaceFlag.String()).To(Equal("dev"))
+	})
+
+	It("accepts a space in another org", func() {
+		Expect(spaceFlag.UnmarshalFlag("some-org/dev")).ToNot(HaveOccurred())
+		Expect(spaceFlag.Organization).To(Equal("some-org"))
+		Expect(spaceFlag.Space).To(Equal("dev"))
+		Expect(spaceFlag.String()).To(Equal("some-org/dev"))
+	})
+
+	It("returns an error when the org or the space is missing", func() {
+		for _, value := range []string{"", "/dev", "some-org/", "some-org/dev/more"} {
+			err := spaceFlag.UnmarshalFlag(value)
+			_, ok := err.(InvalidSpaceError)
+			Expect(ok).To(BeTrue(), value)
+		}
+	})
+})
@@ -0,0 +1,36 @@
+package flaghelpers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/diego-enabler/models"
+)
+
+type StateFlag struct {
+	Value string
+}
+
+func (flag *StateFlag) UnmarshalFlag(value string) error {
+	switch strings.ToUpper(value) {
+	case models.Started:
+		flag.Value = models.Started
+	case models.Stopped:
+		flag.Value = models.Stopped
+	default:
+		return InvalidStateValueError{PassedValue: value}
+	}
+
+	return nil
+}
+
+type InvalidStateValueError struct {
+	PassedValue string
+}
+
+func (e InvalidStateValueError) Error() string {
+	return fmt.Sprintf(
+		"Invalid app state: %s\nValue for STATE must be started or stopped",
+		e.PassedValue,
+	)
+}
@@ -0,0 +1,31 @@
+package flaghelpers_test
+
+import (
+	. "github.com/cloudfoundry-incubator/diego-enabler/commands/flaghelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("StateFlag", func() {
+	var stateFlag StateFlag
+	BeforeEach(func() {
+		stateFlag = StateFlag{}
+	})
+
+	It("accepts started", func() {
+		Expect(stateFlag.UnmarshalFlag("started")).ToNot(HaveOccurred())
+		Expect(stateFlag.Value).To(Equal("STARTED"))
+	})
+
+	It("accepts stopped in any case", func() {
+		Expect(stateFlag.UnmarshalFlag("Stopped")).ToNot(HaveOccurred())
+		Expect(stateFlag.Value).To(Equal("STOPPED"))
+	})
+
+	It("returns an error for any other state", func() {
+		err := stateFlag.UnmarshalFlag("crashed")
+		_, ok := err.(InvalidStateValueError)
+		Expect(ok).To(BeTrue())
+	})
+})
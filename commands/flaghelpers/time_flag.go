@@ -0,0 +1,34 @@
+package flaghelpers
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeFlag takes a date, which means midnight UTC, or an RFC 3339 timestamp.
+type TimeFlag struct {
+	Value time.Time
+}
+
+func (flag *TimeFlag) UnmarshalFlag(value string) error {
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		t, err := time.Parse(layout, value)
+		if err == nil {
+			flag.Value = t
+			return nil
+		}
+	}
+
+	return InvalidTimeError{PassedValue: value}
+}
+
+type InvalidTimeError struct {
+	PassedValue string
+}
+
+func (e InvalidTimeError) Error() string {
+	return fmt.Sprintf(
+		"Invalid time: %s\nValue for TIME must be a date such as 2016-06-01 or a timestamp such as 2016-06-01T12:00:00Z",
+		e.PassedValue,
+	)
+}
@@ -0,0 +1,33 @@
+package flaghelpers_test
+
+import (
+	"time"
+
+	. "github.com/cloudfoundry-incubator/diego-enabler/commands/flaghelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TimeFlag", func() {
+	var timeFlag TimeFlag
+	BeforeEach(func() {
+		timeFlag = TimeFlag{}
+	})
+
+	It("accepts a date as midnight UTC", func() {
+		Expect(timeFlag.UnmarshalFlag("2016-06-01")).ToNot(HaveOccurred())
+		Expect(timeFlag.Value).To(Equal(time.Date(2016, time.June, 1, 0, 0, 0, 0, time.UTC)))
+	})
+
+	It("accepts a timestamp with a time zone", func() {
+		Expect(timeFlag.UnmarshalFlag("2016-06-01T14:30:00+02:00")).ToNot(HaveOccurred())
+		Expect(timeFlag.Value.Equal(time.Date(2016, time.June, 1, 12, 30, 0, 0, time.UTC))).To(BeTrue())
+	})
+
+	It("returns an error for anything else", func() {
+		err := timeFlag.UnmarshalFlag("yesterday")
+		_, ok := err.(InvalidTimeError)
+		Expect(ok).To(BeTrue())
+	})
+})
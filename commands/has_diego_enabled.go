@@ -1,6 +1,9 @@
 package commands
 
-import "github.com/cloudfoundry-incubator/diego-enabler/commands/diegohelpers"
+import (
+	"github.com/cloudfoundry-incubator/diego-enabler/api"
+	"github.com/cloudfoundry-incubator/diego-enabler/commands/diegohelpers"
+)
 
 type HasDiegoEnabledCommand struct {
 	RequiredOptions HasDiegoEnabledPositionalArgs `positional-args:"yes"`
@@ -11,5 +14,10 @@ type HasDiegoEnabledPositionalArgs struct {
 }
 
 func (command HasDiegoEnabledCommand) Execute([]string) error {
-	return diegohelpers.IsDiegoEnabled(DiegoEnabler.CLIConnection, command.RequiredOptions.AppName)
+	cc, err := api.NewCloudController(DiegoEnabler.CLIConnection)
+	if err != nil {
+		return err
+	}
+
+	return diegohelpers.IsDiegoEnabled(DiegoEnabler.CLIConnection, cc, command.RequiredOptions.AppName)
 }
@@ -12,7 +12,7 @@ import (
 	"github.com/cloudfoundry/cli/cf/trace"
 )
 
-func ListApps(cliConnection api.Connection, appsGetterFunc thingdoer.AppsGetterFunc, listAppsCommand *ui.ListAppsCommand) error {
+func ListApps(cliConnection api.Connection, appsGetterFunc thingdoer.AppsGetterFunc, nameFilter thingdoer.AppNameFilter, listAppsCommand *ui.ListAppsCommand) error {
 	listAppsCommand.BeforeAll()
 
 	appsParser := models.ApplicationsParser{}
@@ -40,6 +40,8 @@ func ListApps(cliConnection api.Connection, appsGetterFunc thingdoer.AppsGetterF
 		return err
 	}
 
+	apps, excluded := nameFilter.Apply(apps)
+
 	spaceRequestFactory := apiClient.HandleFiltersAndParameters(
 		apiClient.Authorize(apiClient.NewGetSpacesRequest),
 	)
@@ -61,43 +63,65 @@ func ListApps(cliConnection api.Connection, appsGetterFunc thingdoer.AppsGetterF
 		spaceMap[space.Guid] = space
 	}
 
+	stackMap := make(map[string]models.Stack)
+	if includesColumn(listAppsCommand.Columns, ui.ColumnStack) {
+		stackRequestFactory := apiClient.HandleFiltersAndParameters(
+			apiClient.Authorize(apiClient.NewGetStacksRequest),
+		)
+		stacksPaginatedRequester, err := api.NewPaginatedRequester(cliConnection, stackRequestFactory)
+		if err != nil {
+			return err
+		}
+
+		stacks, err := thingdoer.Stacks(
+			models.StacksParser{},
+			stacksPaginatedRequester,
+		)
+		if err != nil {
+			return err
+		}
+
+		for _, stack := range stacks {
+			stackMap[stack.Guid] = stack
+		}
+	}
+
 	var appPrinters []ui.ApplicationPrinter
 	for _, a := range apps {
 		appPrinters = append(appPrinters, &displayhelpers.AppPrinter{
 			App:    a,
 			Spaces: spaceMap,
+			Stacks: stackMap,
 		})
 	}
 
-	listAppsCommand.AfterAll(appPrinters)
+	return listAppsCommand.AfterAll(appPrinters, excluded)
+}
 
-	return nil
+func includesColumn(columns []string, column string) bool {
+	for _, c := range columns {
+		if c == column {
+			return true
+		}
+	}
+	return false
 }
 
-func NewListAppsCommand(cliConnection api.Connection, orgName string, spaceName string, runtime ui.Runtime) (ui.ListAppsCommand, error) {
+func NewListAppsCommand(cliConnection api.Connection, scopes []ui.Scope, runtime ui.Runtime) (ui.ListAppsCommand, error) {
 	username, err := cliConnection.Username()
 	if err != nil {
 		return ui.ListAppsCommand{}, err
 	}
 
-	if spaceName != "" {
-		space, err := cliConnection.GetSpace(spaceName)
-		if err != nil || space.Guid == "" {
-			return ui.ListAppsCommand{}, err
-		}
-		orgName = space.Organization.Name
-	}
-
 	traceEnv := os.Getenv("CF_TRACE")
 	traceLogger := trace.NewLogger(false, traceEnv, "")
 	tUI := terminal.NewUI(os.Stdin, terminal.NewTeePrinter(), traceLogger)
 
 	cmd := ui.ListAppsCommand{
-		Username:     username,
-		Organization: orgName,
-		Space:        spaceName,
-		UI:           tUI,
-		Runtime:      runtime,
+		Username: username,
+		Scopes:   scopes,
+		UI:       tUI,
+		Runtime:  runtime,
 	}
 	return cmd, nil
 }
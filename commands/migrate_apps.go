@@ -1,6 +1,10 @@
 package commands
 
 import (
+	"os"
+	"os/signal"
+	"syscall"
+
 	"github.com/cloudfoundry-incubator/diego-enabler/commands/diegohelpers"
 	"github.com/cloudfoundry-incubator/diego-enabler/commands/errorhelpers"
 	"github.com/cloudfoundry-incubator/diego-enabler/commands/flaghelpers"
@@ -13,10 +17,25 @@ type MigrateAppsPositionalArgs struct {
 }
 
 type MigrateAppsCommand struct {
-	RequiredOptions MigrateAppsPositionalArgs `positional-args:"yes"`
-	Organization    string                    `short:"o" value-name:"ORG" description:"Organization to restrict the app migration to"`
-	Space           string                    `short:"s" value-name:"SPACE" description:"Space in the targeted organization to restrict the app migration to"`
-	MaxInFlight     flaghelpers.ParallelFlag  `short:"p" value-name:"MAX_IN_FLIGHT" default:"1" description:"Maximum number of apps to migrate in parallel (maximum: 100)"`
+	RequiredOptions MigrateAppsPositionalArgs    `positional-args:"yes"`
+	Organizations   []string                     `short:"o" value-name:"ORG" description:"Organization to restrict the app migration to (can be repeated)"`
+	Spaces          []flaghelpers.SpaceFlag      `short:"s" value-name:"SPACE" description:"Space to restrict the app migration to, in the targeted organization or given as ORG/SPACE (can be repeated)"`
+	AllOrgs         bool                         `long:"all-orgs" description:"Migrate the apps in all orgs instead of the targeted space, after confirming the number of apps"`
+	Force           bool                         `short:"f" description:"Force migration with --all-orgs without confirmation"`
+	MaxInFlight     flaghelpers.ParallelFlag     `short:"p" value-name:"MAX_IN_FLIGHT" default:"1" description:"Maximum number of apps to migrate in parallel (maximum: 100)"`
+	Rollback        bool                         `long:"rollback-on-failure" description:"Migrate apps that fail to start back to their original runtime"`
+	DryRun          bool                         `long:"dry-run" description:"Report which apps would be migrated without changing them"`
+	WritePlan       string                       `long:"write-plan" value-name:"FILE" description:"Write the apps that would be migrated to FILE (JSON, or YAML for .yml/.yaml) without migrating them"`
+	Plan            string                       `long:"plan" value-name:"FILE" description:"Migrate exactly the apps listed in a plan written by --write-plan"`
+	SkipChanged     bool                         `long:"skip-changed" description:"Skip apps that changed since the plan was made instead of failing"`
+	Canary          flaghelpers.CanaryFlag       `long:"canary" value-name:"N" description:"Migrate N apps (or N% of all apps) first, MAX_IN_FLIGHT at a time, and only continue if all of them succeed"`
+	MaxFailures     flaghelpers.MaxFailuresFlag  `long:"max-failures" value-name:"K" description:"Stop migrating new apps once K apps have failed (0 never stops)"`
+	State           flaghelpers.StateFlag        `long:"state" value-name:"STATE" description:"Only migrate apps in STATE (started or stopped)"`
+	Include         []flaghelpers.AppNamePattern `long:"include" value-name:"PATTERN" description:"Only include apps whose name matches PATTERN, a glob or a /regex/ (can be repeated)"`
+	Exclude         []flaghelpers.AppNamePattern `long:"exclude" value-name:"PATTERN" description:"Exclude apps whose name matches PATTERN, a glob or a /regex/ (can be repeated)"`
+	Journal         string                       `long:"journal" value-name:"FILE" description:"Record the progress of each app in FILE and skip apps that a previous run already migrated"`
+	Report          string                       `long:"report" value-name:"FILE" description:"Write the result of migrating each app to FILE"`
+	ReportFormat    flaghelpers.ReportFormatFlag `long:"report-format" value-name:"FORMAT" default:"json" description:"Format of the report written by --report: json, csv or junit"`
 }
 
 //TODO: Figure out how to output this warning in the help
@@ -31,27 +50,119 @@ func (command MigrateAppsCommand) Execute([]string) error {
 		return err
 	}
 
-	err = errorhelpers.ErrorIfOrgAndSpacesSet(command.Organization, command.Space)
+	err = errorhelpers.ErrorIfAllOrgsAndScopeSet(command.AllOrgs, command.Organizations, command.Spaces)
+	if err != nil {
+		return err
+	}
+
+	err = errorhelpers.ErrorIfPlanAndWritePlanSet(command.Plan, command.WritePlan)
+	if err != nil {
+		return err
+	}
+
+	var plan *migratehelpers.Plan
+	if command.Plan != "" {
+		loaded, err := migratehelpers.LoadPlan(command.Plan)
+		if err != nil {
+			return err
+		}
+
+		if loaded.Runtime.String() != runtime.String() {
+			return migratehelpers.PlanRuntimeMismatchError{Path: command.Plan, Planned: loaded.Runtime}
+		}
+		plan = &loaded
+	}
+
+	var scope diegohelpers.ResolvedScope
+	switch {
+	case command.defaultsToTargetedSpace() && plan != nil && len(plan.Apps) > 0:
+		scope, err = diegohelpers.AppsScope{Spaces: plan.Spaces()}.Resolve(cliConnection)
+	case command.defaultsToTargetedSpace():
+		scope, err = diegohelpers.TargetedSpaceScope(cliConnection)
+	default:
+		scope, err = diegohelpers.AppsScope{Organizations: command.Organizations, Spaces: command.Spaces}.Resolve(cliConnection)
+	}
 	if err != nil {
 		return err
 	}
 
-	appsGetter, err := diegohelpers.NewAppsGetterFunc(cliConnection, command.Organization, command.Space, runtime.Flip())
+	appsGetter, err := diegohelpers.NewAppsGetterFunc(cliConnection, scope, diegohelpers.AppsFilter{State: command.State}, runtime.Flip(), false)
 	if err != nil {
 		return err
 	}
 
-	migrateAppsCommand, err := migratehelpers.NewMigrateAppsCommand(cliConnection, command.Organization, command.Space, runtime)
+	migratedAppsGetter, err := diegohelpers.NewAppsGetterFunc(cliConnection, scope, diegohelpers.AppsFilter{State: command.State}, runtime, false)
 	if err != nil {
 		return err
 	}
 
+	migrateAppsCommand, err := migratehelpers.NewMigrateAppsCommand(cliConnection, scope.Scopes, runtime)
+	if err != nil {
+		return err
+	}
+	migrateAppsCommand.DryRun = command.DryRun || command.WritePlan != ""
+
+	var journal *migratehelpers.Journal
+	if command.Journal != "" {
+		journal, err = migratehelpers.OpenJournal(command.Journal)
+		if err != nil {
+			return err
+		}
+		defer journal.Close()
+	}
+
 	cmd := migratehelpers.MigrateApps{
 		MaxInFlight:        command.MaxInFlight.Value,
 		Runtime:            runtime,
+		RollbackOnFailure:  command.Rollback,
+		DryRun:             command.DryRun,
+		Plan:               plan,
+		SkipChanged:        command.SkipChanged,
+		WritePlan:          command.WritePlan,
+		Canary:             command.Canary,
+		MaxFailures:        command.MaxFailures.Value,
+		StartupTimeout:     migratehelpers.StartupTimeout(),
+		PollInterval:       migratehelpers.DefaultPollInterval,
+		Journal:            journal,
+		ReportPath:         command.Report,
+		ReportFormat:       command.ReportFormat.Value,
 		AppsGetterFunc:     appsGetter,
+		MigratedAppsGetter: migratedAppsGetter,
+		NameFilter:         diegohelpers.NewAppNameFilter(command.Include, command.Exclude),
 		MigrateAppsCommand: &migrateAppsCommand,
 	}
 
+	cmd.WatchInterrupts = func() (<-chan struct{}, func()) {
+		signals := make(chan os.Signal, 2)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+		interrupt, stop := migratehelpers.WatchInterrupts(
+			signals,
+			migrateAppsCommand.Interrupted,
+			func() {
+				migrateAppsCommand.Aborted()
+				os.Exit(130)
+			},
+		)
+
+		return interrupt, func() {
+			signal.Stop(signals)
+			stop()
+		}
+	}
+
+	if command.AllOrgs && !command.Force {
+		cmd.Confirm = migrateAppsCommand.ConfirmAllOrgs
+	}
+
 	return cmd.Execute(cliConnection)
 }
+
+// defaultsToTargetedSpace reports whether the command was not told where to
+// migrate apps, so that migrating every app visible to the user takes
+// --all-orgs. A plan then only looks in the spaces of the apps it names.
+func (command MigrateAppsCommand) defaultsToTargetedSpace() bool {
+	return len(command.Organizations) == 0 &&
+		len(command.Spaces) == 0 &&
+		!command.AllOrgs
+}
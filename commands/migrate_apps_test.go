@@ -1,9 +1,17 @@
 package commands_test
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
 	. "github.com/cloudfoundry-incubator/diego-enabler/commands"
+	"github.com/cloudfoundry-incubator/diego-enabler/commands/diegohelpers"
 	"github.com/cloudfoundry-incubator/diego-enabler/commands/errorhelpers"
+	"github.com/cloudfoundry-incubator/diego-enabler/commands/flaghelpers"
+	"github.com/cloudfoundry-incubator/diego-enabler/commands/migratehelpers"
 	"github.com/cloudfoundry-incubator/diego-enabler/ui"
+	"github.com/cloudfoundry/cli/plugin/models"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -11,8 +19,7 @@ import (
 
 var _ = Describe("MigrateAppsCommand", func() {
 	var (
-		command         MigrateAppsCommand
-		requiredOptions MigrateAppsPositionalArgs
+		command MigrateAppsCommand
 
 		err error
 	)
@@ -21,21 +28,129 @@ var _ = Describe("MigrateAppsCommand", func() {
 		err = command.Execute([]string{})
 	})
 
-	Context("when both organization and space are passed", func() {
+	Context("when a space is not in the organization it is given with", func() {
+		BeforeEach(func() {
+			fakeConnection.GetOrgReturns(plugin_models.GetOrg_Model{
+				Guid:   "some-organization-guid",
+				Name:   "some-organization",
+				Spaces: []plugin_models.GetOrg_Space{{Guid: "other-space-guid", Name: "other-space"}},
+			}, nil)
+
+			command = MigrateAppsCommand{
+				RequiredOptions: MigrateAppsPositionalArgs{Runtime: string(ui.DEA)},
+				Organizations:   []string{"some-organization"},
+				Spaces:          []flaghelpers.SpaceFlag{{Organization: "some-organization", Space: "some-space"}},
+			}
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(Equal(diegohelpers.SpaceNotFoundErr{SpaceName: "some-organization/some-space"}))
+		})
+	})
+
+	Context("when an organization cannot be found", func() {
 		BeforeEach(func() {
-			requiredOptions = MigrateAppsPositionalArgs{
-				Runtime: string(ui.DEA),
+			command = MigrateAppsCommand{
+				RequiredOptions: MigrateAppsPositionalArgs{Runtime: string(ui.DEA)},
+				Organizations:   []string{"some-organization"},
 			}
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(Equal(diegohelpers.OrgNotFoundErr{OrganizationName: "some-organization"}))
+		})
+	})
 
+	Context("when all orgs are requested together with an org", func() {
+		BeforeEach(func() {
 			command = MigrateAppsCommand{
-				RequiredOptions: requiredOptions,
-				Space:           "some-space",
-				Organization:    "some-organization",
+				RequiredOptions: MigrateAppsPositionalArgs{Runtime: string(ui.DEA)},
+				Organizations:   []string{"some-organization"},
+				AllOrgs:         true,
 			}
 		})
 
 		It("returns an error", func() {
-			Expect(err).To(Equal(errorhelpers.SpecifyOrgOrSpaceError))
+			Expect(err).To(Equal(errorhelpers.SpecifyAllOrgsOrScopeError))
+			Expect(fakeConnection.GetOrgCallCount()).To(BeZero())
+		})
+	})
+
+	Context("when neither orgs, spaces nor all orgs are given", func() {
+		BeforeEach(func() {
+			command = MigrateAppsCommand{
+				RequiredOptions: MigrateAppsPositionalArgs{Runtime: string(ui.DEA)},
+			}
+		})
+
+		It("looks up the targeted space", func() {
+			Expect(fakeConnection.GetCurrentSpaceCallCount()).To(Equal(1))
+		})
+
+		Context("when no space is targeted", func() {
+			It("returns an error", func() {
+				Expect(err).To(Equal(diegohelpers.NoSpaceTargetedErr{}))
+			})
+		})
+
+		Context("when the targeted space cannot be found", func() {
+			BeforeEach(func() {
+				fakeConnection.GetCurrentSpaceReturns(plugin_models.Space{
+					SpaceFields: plugin_models.SpaceFields{Guid: "some-space-guid", Name: "some-space"},
+				}, nil)
+			})
+
+			It("looks it up by name", func() {
+				Expect(fakeConnection.GetSpaceArgsForCall(0)).To(Equal("some-space"))
+				Expect(err).To(Equal(diegohelpers.SpaceNotFoundErr{SpaceName: "some-space"}))
+			})
+		})
+	})
+
+	Context("when a plan is given without orgs, spaces or all orgs", func() {
+		var (
+			planDir string
+			plan    migratehelpers.Plan
+		)
+
+		BeforeEach(func() {
+			planDir, err = ioutil.TempDir("", "migrate-apps-plan")
+			Expect(err).NotTo(HaveOccurred())
+
+			plan = migratehelpers.Plan{
+				Runtime: ui.Diego,
+				Apps: []migratehelpers.PlanApp{
+					{Organization: "some-organization", Space: "some-space", Name: "some-app", Guid: "some-app-guid"},
+				},
+			}
+
+			command = MigrateAppsCommand{
+				RequiredOptions: MigrateAppsPositionalArgs{Runtime: string(ui.Diego)},
+				Plan:            filepath.Join(planDir, "plan.json"),
+			}
+			Expect(plan.Save(command.Plan)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(planDir)
+		})
+
+		It("only looks for apps in the spaces of the plan", func() {
+			Expect(fakeConnection.GetCurrentSpaceCallCount()).To(BeZero())
+			Expect(fakeConnection.GetOrgArgsForCall(0)).To(Equal("some-organization"))
+			Expect(err).To(Equal(diegohelpers.OrgNotFoundErr{OrganizationName: "some-organization"}))
+		})
+
+		Context("when the plan has no apps", func() {
+			BeforeEach(func() {
+				plan.Apps = nil
+				Expect(plan.Save(command.Plan)).To(Succeed())
+			})
+
+			It("looks up the targeted space", func() {
+				Expect(fakeConnection.GetOrgCallCount()).To(BeZero())
+				Expect(err).To(Equal(diegohelpers.NoSpaceTargetedErr{}))
+			})
 		})
 	})
 })
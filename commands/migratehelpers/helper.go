@@ -1,15 +1,17 @@
 package migratehelpers
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"strconv"
-	"strings"
 	"time"
 
 	"sync"
 
 	"github.com/cloudfoundry-incubator/diego-enabler/api"
 	"github.com/cloudfoundry-incubator/diego-enabler/commands/displayhelpers"
+	"github.com/cloudfoundry-incubator/diego-enabler/commands/flaghelpers"
 	"github.com/cloudfoundry-incubator/diego-enabler/diegosupport"
 	"github.com/cloudfoundry-incubator/diego-enabler/models"
 	"github.com/cloudfoundry-incubator/diego-enabler/thingdoer"
@@ -21,13 +23,63 @@ const (
 	FailWarning
 	Err
 	OKWarning
+	Crashed
+	TimedOut
+	RolledBack
+)
+
+const (
+	DefaultStartupTimeout = 5 * time.Minute
+	DefaultPollInterval   = 5 * time.Second
 )
 
 type MigrateApps struct {
 	MaxInFlight        int
 	Runtime            ui.Runtime
+	RollbackOnFailure  bool
+	DryRun             bool
+	Plan               *Plan
+	SkipChanged        bool
+	WritePlan          string
+	Canary             flaghelpers.CanaryFlag
+	MaxFailures        int
+	StartupTimeout     time.Duration
+	PollInterval       time.Duration
+	Journal            *Journal
+	ReportPath         string
+	ReportFormat       string
 	AppsGetterFunc     thingdoer.AppsGetterFunc
+	NameFilter         thingdoer.AppNameFilter
 	MigrateAppsCommand *ui.MigrateAppsCommand
+
+	// MigratedAppsGetter gets the apps in the same orgs, spaces and state
+	// that already run on the target runtime. Only those are resumed from
+	// the Journal.
+	MigratedAppsGetter thingdoer.AppsGetterFunc
+
+	// Confirm is asked with the number of apps before migrating them, unless
+	// DryRun is set. Nil migrates without asking.
+	Confirm func(apps int) bool
+
+	// WatchInterrupts is called right before the apps are migrated. It
+	// returns a channel that is closed when the migration is interrupted and
+	// a function that stops watching once the migration is over. Nil means
+	// the migration is not interruptible.
+	WatchInterrupts func() (<-chan struct{}, func())
+}
+
+// StartupTimeout honors CF_STARTUP_TIMEOUT (in minutes) the same way cf push
+// does, falling back to DefaultStartupTimeout.
+func StartupTimeout() time.Duration {
+	timeout := os.Getenv("CF_STARTUP_TIMEOUT")
+	if timeout != "" {
+		t, err := strconv.Atoi(timeout)
+		if err == nil && t > 0 {
+			return time.Duration(t) * time.Minute
+		}
+	}
+
+	return DefaultStartupTimeout
 }
 
 func (cmd *MigrateApps) Execute(cliConnection api.Connection) error {
@@ -55,6 +107,8 @@ func (cmd *MigrateApps) Execute(cliConnection api.Connection) error {
 		return err
 	}
 
+	apps, excluded := cmd.NameFilter.Apply(apps)
+
 	spaceRequestFactory := apiClient.HandleFiltersAndParameters(
 		apiClient.Authorize(apiClient.NewGetSpacesRequest),
 	)
@@ -77,121 +131,547 @@ func (cmd *MigrateApps) Execute(cliConnection api.Connection) error {
 		spaceMap[space.Guid] = space
 	}
 
-	okWarnings, failWarnings, errors := cmd.migrateApps(cliConnection, apps, spaceMap, cmd.MaxInFlight)
-	cmd.MigrateAppsCommand.AfterAll(len(apps), okWarnings, failWarnings, errors)
+	// apps a previous run migrated are gone from the source runtime, so the
+	// journal is resumed before the plan is checked for apps that changed
+	if cmd.Journal != nil {
+		migrated, err := cmd.migratedApps(appPaginatedRequester)
+		if err != nil {
+			return err
+		}
+
+		var skipped int
+		apps, skipped = cmd.Journal.Resume(apps, migrated)
+		cmd.MigrateAppsCommand.ResumeJournal(cmd.Journal.Path, skipped)
+	}
+
+	if cmd.Plan != nil {
+		var changed []PlanApp
+		apps, changed = cmd.Plan.Select(apps)
+		changed = cmd.drifted(changed)
+		if len(changed) > 0 {
+			if !cmd.SkipChanged {
+				return PlanChangedError{Apps: changed}
+			}
+
+			for _, app := range changed {
+				cmd.MigrateAppsCommand.PlanChangedWarning(app.Name, app.Organization, app.Space)
+			}
+		}
+	}
+
+	if cmd.WritePlan != "" {
+		err = NewPlan(cmd.Runtime, apps, spaceMap).Save(cmd.WritePlan)
+		if err != nil {
+			return err
+		}
+
+		cmd.MigrateAppsCommand.PlanWritten(cmd.WritePlan, len(apps))
+		return nil
+	}
+
+	if !cmd.DryRun && cmd.Confirm != nil && !cmd.Confirm(len(apps)) {
+		cmd.MigrateAppsCommand.Cancelled()
+		return nil
+	}
+
+	if cmd.Journal != nil && !cmd.DryRun {
+		for _, app := range apps {
+			cmd.record(app, JournalPending)
+		}
+	}
+
+	if cmd.DryRun {
+		cmd.dryRun(apps, spaceMap, excluded)
+		return nil
+	}
+
+	cc, err := api.NewCloudController(cliConnection)
+	if err != nil {
+		return err
+	}
+	diegoSupport := diegosupport.NewDiegoSupport(cliConnection, cc)
+
+	interrupt, stopWatching := cmd.watchInterrupts()
+	results, summary := cmd.migrateApps(diegoSupport, apps, spaceMap, cmd.MaxInFlight, interrupt)
+	stopWatching()
+
+	summary.Excluded = excluded
+	cmd.MigrateAppsCommand.AfterAll(summary)
+
+	if cmd.ReportPath != "" {
+		return newReport(cmd.Runtime, results, apps, spaceMap).Save(cmd.ReportPath, cmd.ReportFormat)
+	}
 
 	return nil
 }
 
-func NewMigrateAppsCommand(cliConnection api.Connection, organizationName string, spaceName string, runtime ui.Runtime) (ui.MigrateAppsCommand, error) {
+// dryRun reports what migrating apps would do without setting the diego flag
+// on any of them.
+func (cmd *MigrateApps) dryRun(apps models.Applications, spaceMap map[string]models.Space, excluded int) {
+	restarts := 0
+	warnings := 0
+
+	for _, app := range apps {
+		appPrinter := &displayhelpers.AppPrinter{
+			App:    app,
+			Spaces: spaceMap,
+		}
+
+		restart := app.State == models.Started
+		if restart {
+			restarts++
+		}
+		cmd.MigrateAppsCommand.DryRunEach(appPrinter, restart)
+
+		if cmd.Runtime == ui.Diego && !app.HasRoutes {
+			cmd.MigrateAppsCommand.HealthCheckNoneWarning(appPrinter, os.Stdout)
+			warnings++
+		}
+	}
+
+	cmd.MigrateAppsCommand.DryRunAfterAll(len(apps), restarts, warnings, excluded)
+}
+
+func NewMigrateAppsCommand(cliConnection api.Connection, scopes []ui.Scope, runtime ui.Runtime) (ui.MigrateAppsCommand, error) {
 	username, err := cliConnection.Username()
 	if err != nil {
 		return ui.MigrateAppsCommand{}, err
 	}
 
-	if spaceName != "" {
-		space, err := cliConnection.GetSpace(spaceName)
-		if err != nil || space.Guid == "" {
-			return ui.MigrateAppsCommand{}, err
-		}
-		organizationName = space.Organization.Name
-	}
-
 	return ui.MigrateAppsCommand{
-		Username:     username,
-		Runtime:      runtime,
-		Organization: organizationName,
-		Space:        spaceName,
+		Username: username,
+		Runtime:  runtime,
+		Scopes:   scopes,
+		Stdin:    os.Stdin,
 	}, nil
 }
 
-type migrateAppFunc func(appPrinter *displayhelpers.AppPrinter, diegoSupport DiegoFlagSetter) int
+type migrateAppFunc func(appPrinter *displayhelpers.AppPrinter, diegoSupport DiegoFlagSetter) migrationResult
+
+type migrationResult struct {
+	App      *displayhelpers.AppPrinter
+	Status   int
+	Message  string
+	Duration time.Duration
+}
 
 //go:generate counterfeiter . DiegoFlagSetter
 type DiegoFlagSetter interface {
-	SetDiegoFlag(string, bool) ([]string, error)
-	HasRoutes(appName string) (bool, error)
+	SetDiegoFlag(string, bool) error
+	HasRoutes(appGuid string) (bool, error)
+	InstanceStates(appGuid string) ([]string, error)
 }
 
 func (cmd *MigrateApps) MigrateApp(
 	appPrinter *displayhelpers.AppPrinter,
 	diegoSupport DiegoFlagSetter,
 ) int {
-	status := Success
+	return cmd.migrate(appPrinter, diegoSupport).Status
+}
 
-	cmd.MigrateAppsCommand.BeforeEach(appPrinter)
+func (cmd *MigrateApps) migrate(
+	appPrinter *displayhelpers.AppPrinter,
+	diegoSupport DiegoFlagSetter,
+) migrationResult {
+	start := time.Now()
+	status, message := cmd.migrateApp(appPrinter, diegoSupport)
+
+	switch status {
+	case Success, OKWarning:
+		cmd.record(appPrinter.App, JournalVerified)
+	default:
+		cmd.record(appPrinter.App, JournalFailed)
+	}
 
-	var waitTime time.Duration
-	if appPrinter.App.State == models.Started {
-		waitTime = 1 * time.Minute
-		timeout := os.Getenv("CF_STARTUP_TIMEOUT")
-		if timeout != "" {
-			t, err := strconv.Atoi(timeout)
+	return migrationResult{
+		App:      appPrinter,
+		Status:   status,
+		Message:  message,
+		Duration: time.Since(start),
+	}
+}
 
-			if err == nil {
-				waitTime = time.Duration(float32(t)/5.0*60.0) * time.Second
-			}
+// drifted leaves out of changed the apps the Journal shows a previous run
+// already migrated.
+func (cmd *MigrateApps) drifted(changed []PlanApp) []PlanApp {
+	if cmd.Journal == nil {
+		return changed
+	}
+
+	var drifted []PlanApp
+	for _, app := range changed {
+		if !cmd.Journal.Verified(app.Guid) {
+			drifted = append(drifted, app)
 		}
 	}
+	return drifted
+}
+
+// migratedApps lists the selected apps that already run on the target
+// runtime, narrowed down the same way as the apps to migrate.
+func (cmd *MigrateApps) migratedApps(requester thingdoer.PaginatedRequester) (models.Applications, error) {
+	if cmd.MigratedAppsGetter == nil {
+		return nil, nil
+	}
 
-	_, err := diegoSupport.SetDiegoFlag(appPrinter.App.Guid, cmd.Runtime == ui.Diego)
+	migrated, err := cmd.MigratedAppsGetter(models.ApplicationsParser{}, requester)
 	if err != nil {
-		if strings.Contains(err.Error(), "NotAuthorized") {
+		return nil, err
+	}
+
+	migrated, _ = cmd.NameFilter.Apply(migrated)
+	if cmd.Plan != nil {
+		migrated = cmd.Plan.Only(migrated)
+	}
+	return migrated, nil
+}
+
+func (cmd *MigrateApps) record(app models.Application, state JournalState) {
+	if cmd.Journal == nil {
+		return
+	}
+
+	err := cmd.Journal.Record(app, state)
+	if err != nil {
+		cmd.MigrateAppsCommand.JournalWarning(cmd.Journal.Path, err)
+	}
+}
+
+// migrateApp returns the status of the migration along with the warning or
+// error that explains it, if any.
+func (cmd *MigrateApps) migrateApp(
+	appPrinter *displayhelpers.AppPrinter,
+	diegoSupport DiegoFlagSetter,
+) (int, string) {
+	status := Success
+	message := ""
+
+	cmd.MigrateAppsCommand.BeforeEach(appPrinter)
+
+	err := diegoSupport.SetDiegoFlag(appPrinter.App.Guid, cmd.Runtime == ui.Diego)
+	if err != nil {
+		if api.IsNotAuthorized(err) {
 			cmd.MigrateAppsCommand.UserWarning(appPrinter)
-			return FailWarning
+			return FailWarning, err.Error()
 		} else {
 			cmd.MigrateAppsCommand.FailMigrate(appPrinter, err)
-			return Err
+			return Err, err.Error()
 		}
 	}
+	cmd.record(appPrinter.App, JournalFlagSet)
 
 	if cmd.Runtime == ui.Diego && !appPrinter.App.ApplicationEntity.HasRoutes {
 		cmd.MigrateAppsCommand.HealthCheckNoneWarning(appPrinter, os.Stdout)
 		status = OKWarning
+		message = "No mapped routes, assuming health check of type process ('none')"
 	}
 
-	printDot := time.NewTicker(5 * time.Second)
-	done := make(chan bool)
-	go func() {
-		for {
-			select {
-			case <-printDot.C:
-				cmd.MigrateAppsCommand.DuringEach()
-			case <-done:
-				return
-			}
+	if appPrinter.App.State == models.Started {
+		result := cmd.waitForRunning(appPrinter, diegoSupport)
+		switch result {
+		case Crashed:
+			cmd.MigrateAppsCommand.CrashedEach(appPrinter)
+			message = "App crashed after migrating"
+		case TimedOut:
+			cmd.MigrateAppsCommand.TimedOutEach(appPrinter, cmd.StartupTimeout)
+			message = fmt.Sprintf("App did not start within %s after migrating", cmd.StartupTimeout)
 		}
-	}()
 
-	time.Sleep(waitTime)
-	done <- true
-	printDot.Stop()
+		if result != Success {
+			if cmd.RollbackOnFailure {
+				return cmd.rollback(appPrinter, diegoSupport, result, message)
+			}
+			return result, message
+		}
+	}
 
 	cmd.MigrateAppsCommand.CompletedEach(appPrinter)
 
-	return status
+	return status, message
 }
 
-func (cmd *MigrateApps) migrateApps(cliConnection api.Connection, apps models.Applications, spaceMap map[string]models.Space, maxInFlight int) (int, int, int) {
+// rollback sets the diego flag back to the value the app had before the
+// migration and waits for the app to recover on its original runtime. The
+// original failure is returned when the app does not recover.
+func (cmd *MigrateApps) rollback(
+	appPrinter *displayhelpers.AppPrinter,
+	diegoSupport DiegoFlagSetter,
+	failure int,
+	message string,
+) (int, string) {
+	cmd.MigrateAppsCommand.RollingBackEach(appPrinter)
+
+	err := diegoSupport.SetDiegoFlag(appPrinter.App.Guid, appPrinter.App.Diego)
+	if err != nil {
+		cmd.MigrateAppsCommand.FailRollback(appPrinter, err)
+		return failure, message + "; rollback failed: " + err.Error()
+	}
+
+	if cmd.waitForRunning(appPrinter, diegoSupport) != Success {
+		err = errors.New("app did not start after rolling back")
+		cmd.MigrateAppsCommand.FailRollback(appPrinter, err)
+		return failure, message + "; rollback failed: " + err.Error()
+	}
+
+	cmd.MigrateAppsCommand.RolledBackEach(appPrinter)
+
+	return RolledBack, message + "; rolled back to " + cmd.Runtime.Flip().String()
+}
+
+func (cmd *MigrateApps) waitForRunning(
+	appPrinter *displayhelpers.AppPrinter,
+	diegoSupport DiegoFlagSetter,
+) int {
+	return WaitForRunning(
+		diegoSupport,
+		appPrinter.App.Guid,
+		appPrinter.App.InstanceCount,
+		cmd.StartupTimeout,
+		cmd.PollInterval,
+		cmd.MigrateAppsCommand.DuringEach,
+	)
+}
+
+// WaitForRunning polls the app's instances until all desired instances are
+// RUNNING. It returns Crashed as soon as an instance crashes or staging
+// fails, and TimedOut once the startup timeout has elapsed. An app scaled to
+// zero instances has nothing to wait for.
+func WaitForRunning(
+	diegoSupport DiegoFlagSetter,
+	appGuid string,
+	instanceCount int,
+	startupTimeout time.Duration,
+	pollInterval time.Duration,
+	duringEach func(),
+) int {
+	if instanceCount == 0 {
+		return Success
+	}
+
+	deadline := time.Now().Add(startupTimeout)
+
+	for {
+		states, err := diegoSupport.InstanceStates(appGuid)
+		if err != nil {
+			if api.IsStagingError(err) {
+				return Crashed
+			}
+		} else {
+			running := 0
+			for _, state := range states {
+				switch state {
+				case diegosupport.InstanceRunning:
+					running++
+				case diegosupport.InstanceCrashed:
+					return Crashed
+				}
+			}
+
+			if running >= instanceCount {
+				return Success
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return TimedOut
+		}
+
+		duringEach()
+		time.Sleep(pollInterval)
+	}
+}
+
+func (cmd *MigrateApps) watchInterrupts() (<-chan struct{}, func()) {
+	if cmd.WatchInterrupts == nil {
+		return nil, func() {}
+	}
+
+	return cmd.WatchInterrupts()
+}
+
+func (cmd *MigrateApps) migrateApps(diegoSupport DiegoFlagSetter, apps models.Applications, spaceMap map[string]models.Space, maxInFlight int, interrupt <-chan struct{}) ([]migrationResult, ui.MigrationSummary) {
+	limit := newFailureLimit(cmd.MaxFailures, interrupt)
+	remaining := apps
+
+	var results []migrationResult
+
+	// a canary that covers every app is still migrated as one, so its
+	// failures are reported the same way
+	canary := cmd.Canary.Size(len(apps))
+	if canary > 0 {
+		cmd.MigrateAppsCommand.BeforeCanary(canary)
+
+		results = cmd.migrateBatch(diegoSupport, apps[:canary], spaceMap, maxInFlight, limit)
+		remaining = apps[canary:]
+
+		for _, result := range results {
+			if isFailure(result.Status) {
+				if len(remaining) > 0 {
+					cmd.MigrateAppsCommand.CanaryFailed(canary)
+				}
+				remaining = nil
+				break
+			}
+		}
+
+		if len(remaining) > 0 && !limit.Interrupted() {
+			cmd.MigrateAppsCommand.CanarySucceeded(canary)
+		}
+	}
+
+	if len(remaining) > 0 {
+		results = append(results, cmd.migrateBatch(diegoSupport, remaining, spaceMap, maxInFlight, limit)...)
+	}
+
+	if limit.Reached() {
+		cmd.MigrateAppsCommand.MaxFailuresReached(cmd.MaxFailures)
+	}
+
+	summary := summarize(results, apps, spaceMap)
+	summary.Interrupted = limit.Interrupted()
+	return results, summary
+}
+
+func (cmd *MigrateApps) migrateBatch(
+	diegoSupport DiegoFlagSetter,
+	apps models.Applications,
+	spaceMap map[string]models.Space,
+	maxInFlight int,
+	limit *failureLimit,
+) []migrationResult {
 	if len(apps) < maxInFlight {
 		maxInFlight = len(apps)
 	}
 
-	runningAppsChan := generateAppsChan(apps)
-	outputsChan, waitDone := processAppsChan(cliConnection, spaceMap, cmd.MigrateApp, runningAppsChan, maxInFlight, len(apps))
+	migrate := func(appPrinter *displayhelpers.AppPrinter, diegoSupport DiegoFlagSetter) migrationResult {
+		result := cmd.migrate(appPrinter, diegoSupport)
+		limit.Record(result.Status)
+		return result
+	}
+
+	runningAppsChan := generateAppsChan(apps, limit.Stop())
+	outputsChan, waitDone := processAppsChan(diegoSupport, spaceMap, migrate, runningAppsChan, limit.Stopped, maxInFlight, len(apps))
 
 	waitDone.Wait()
 	close(outputsChan)
 
-	return outputAppsChan(outputsChan)
+	var results []migrationResult
+	for result := range outputsChan {
+		results = append(results, result)
+	}
+	return results
+}
+
+func isFailure(status int) bool {
+	switch status {
+	case Err, Crashed, TimedOut, RolledBack:
+		return true
+	default:
+		return false
+	}
+}
+
+// failureLimit closes its stop channel once max apps have failed or the
+// migration is interrupted, so no new apps are handed to the workers. A max of
+// zero means there is no limit. The interrupt channel is checked every time
+// the limit is asked whether to stop, so an interrupt is noticed before the
+// next app is started.
+type failureLimit struct {
+	max         int
+	failures    int
+	interrupted bool
+	interrupt   <-chan struct{}
+	stop        chan struct{}
+	once        sync.Once
+	mutex       sync.Mutex
 }
 
-func generateAppsChan(apps models.Applications) chan models.Application {
+func newFailureLimit(max int, interrupt <-chan struct{}) *failureLimit {
+	return &failureLimit{
+		max:       max,
+		interrupt: interrupt,
+		stop:      make(chan struct{}),
+	}
+}
+
+func (l *failureLimit) Record(status int) {
+	if l.max <= 0 || !isFailure(status) {
+		return
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.failures++
+	if l.failures == l.max {
+		l.once.Do(func() { close(l.stop) })
+	}
+}
+
+func (l *failureLimit) Interrupt() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.interrupted = true
+	l.once.Do(func() { close(l.stop) })
+}
+
+func (l *failureLimit) Stop() <-chan struct{} {
+	return l.stop
+}
+
+// Stopped reports whether no more apps should be started.
+func (l *failureLimit) Stopped() bool {
+	l.checkInterrupt()
+
+	select {
+	case <-l.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+func (l *failureLimit) checkInterrupt() {
+	select {
+	case <-l.interrupt:
+		l.Interrupt()
+	default:
+	}
+}
+
+func (l *failureLimit) Reached() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.max > 0 && l.failures >= l.max
+}
+
+func (l *failureLimit) Interrupted() bool {
+	l.checkInterrupt()
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.interrupted
+}
+
+func generateAppsChan(apps models.Applications, stop <-chan struct{}) chan models.Application {
 	runningAppsChan := make(chan models.Application)
 	go func() {
 		defer close(runningAppsChan)
 		for _, app := range apps {
-			runningAppsChan <- app
+			select {
+			case <-stop:
+				return
+			default:
+			}
+
+			select {
+			case runningAppsChan <- app:
+			case <-stop:
+				return
+			}
 		}
 	}()
 
@@ -199,17 +679,16 @@ func generateAppsChan(apps models.Applications) chan models.Application {
 }
 
 func processAppsChan(
-	cliConnection api.Connection,
+	diegoSupport DiegoFlagSetter,
 	spaceMap map[string]models.Space,
 	migrate migrateAppFunc,
 	appsChan chan models.Application,
+	stopped func() bool,
 	maxInFlight int,
-	outputSize int) (chan int, *sync.WaitGroup) {
+	outputSize int) (chan migrationResult, *sync.WaitGroup) {
 	var waitDone sync.WaitGroup
 
-	output := make(chan int, outputSize)
-
-	diegoSupport := diegosupport.NewDiegoSupport(cliConnection)
+	output := make(chan migrationResult, outputSize)
 
 	for i := 0; i < maxInFlight; i++ {
 		waitDone.Add(1)
@@ -218,6 +697,12 @@ func processAppsChan(
 			defer waitDone.Done()
 
 			for app := range appsChan {
+				if stopped() {
+					// the app was handed out before the migration was
+					// stopped; leave it for the not attempted summary
+					continue
+				}
+
 				a := &displayhelpers.AppPrinter{
 					App:    app,
 					Spaces: spaceMap,
@@ -229,21 +714,39 @@ func processAppsChan(
 	return output, &waitDone
 }
 
-func outputAppsChan(outputsChan chan int) (int, int, int) {
-	okWarnings := 0
-	failWarnings := 0
-	errors := 0
+func summarize(results []migrationResult, apps models.Applications, spaceMap map[string]models.Space) ui.MigrationSummary {
+	var summary ui.MigrationSummary
+	attempted := map[string]bool{}
 
-	for result := range outputsChan {
-		switch result {
+	for _, result := range results {
+		summary.Attempts++
+		attempted[result.App.App.Guid] = true
+
+		switch result.Status {
 		case OKWarning:
-			okWarnings++
+			summary.OKWarnings++
 		case FailWarning:
-			failWarnings++
+			summary.FailWarnings++
 		case Err:
-			errors++
+			summary.Errors++
+		case Crashed:
+			summary.Crashed++
+		case TimedOut:
+			summary.TimedOut++
+		case RolledBack:
+			summary.RolledBack = append(summary.RolledBack, result.App)
 		default:
 		}
 	}
-	return okWarnings, failWarnings, errors
+
+	for _, app := range apps {
+		if !attempted[app.Guid] {
+			summary.NotAttempted = append(summary.NotAttempted, &displayhelpers.AppPrinter{
+				App:    app,
+				Spaces: spaceMap,
+			})
+		}
+	}
+
+	return summary
 }
@@ -1,14 +1,27 @@
 package migratehelpers_test
 
 import (
+	"encoding/json"
 	"errors"
 	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/cloudfoundry-incubator/diego-enabler/api"
+	"github.com/cloudfoundry-incubator/diego-enabler/api/apifakes"
 	"github.com/cloudfoundry-incubator/diego-enabler/commands/displayhelpers"
+	"github.com/cloudfoundry-incubator/diego-enabler/commands/flaghelpers"
 	. "github.com/cloudfoundry-incubator/diego-enabler/commands/migratehelpers"
 	"github.com/cloudfoundry-incubator/diego-enabler/commands/migratehelpers/migratehelpersfakes"
 	"github.com/cloudfoundry-incubator/diego-enabler/models"
+	"github.com/cloudfoundry-incubator/diego-enabler/thingdoer"
+	"github.com/cloudfoundry-incubator/diego-enabler/thingdoer/thingdoerfakes"
 	"github.com/cloudfoundry-incubator/diego-enabler/ui"
 
 	. "github.com/onsi/ginkgo"
@@ -37,16 +50,598 @@ var _ = Describe("MigrateApps", func() {
 		command = MigrateApps{
 			MaxInFlight:    1,
 			Runtime:        ui.Diego,
+			StartupTimeout: 50 * time.Millisecond,
+			PollInterval:   time.Millisecond,
 			AppsGetterFunc: nil,
 			MigrateAppsCommand: &ui.MigrateAppsCommand{
-				Username:     "some-user",
-				Runtime:      ui.Diego,
-				Organization: "some-organization",
-				Space:        "some-space",
+				Username: "some-user",
+				Runtime:  ui.Diego,
+				Scopes:   []ui.Scope{{Organization: "some-organization", Space: "some-space"}},
 			},
 		}
 	})
 
+	Describe("Execute", func() {
+		var (
+			fakeConnection *apifakes.FakeConnection
+			server         *httptest.Server
+			apps           models.Applications
+
+			mutex        sync.Mutex
+			updatedGuids []string
+			failingGuids map[string]bool
+			onUpdate     func(guid string)
+
+			buf    *Buffer
+			stdout *os.File
+
+			err error
+		)
+
+		BeforeEach(func() {
+			updatedGuids = nil
+			failingGuids = map[string]bool{}
+			onUpdate = nil
+
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == "PUT":
+					guid := strings.TrimPrefix(r.URL.Path, "/v2/apps/")
+
+					mutex.Lock()
+					updatedGuids = append(updatedGuids, guid)
+					mutex.Unlock()
+
+					if onUpdate != nil {
+						onUpdate(guid)
+					}
+					if failingGuids[guid] {
+						w.WriteHeader(http.StatusBadRequest)
+						w.Write([]byte(`{"code": 100001, "description": "disaster", "error_code": "CF-AppInvalid"}`))
+						return
+					}
+					w.Write([]byte(`{}`))
+				case strings.HasSuffix(r.URL.Path, "/instances"):
+					w.Write([]byte(`{"0": {"state": "RUNNING"}}`))
+				default:
+					w.Write([]byte(`{"total_pages": 1, "resources": []}`))
+				}
+			}))
+
+			fakeConnection = new(apifakes.FakeConnection)
+			fakeConnection.IsLoggedInReturns(true, nil)
+			fakeConnection.ApiEndpointReturns(server.URL, nil)
+			fakeConnection.AccessTokenReturns("bearer some-token", nil)
+
+			apps = models.Applications{
+				{
+					ApplicationEntity: models.ApplicationEntity{
+						Name:      "started-app",
+						State:     models.Started,
+						HasRoutes: true,
+					},
+					ApplicationMetadata: models.ApplicationMetadata{Guid: "started-app-guid"},
+				},
+				{
+					ApplicationEntity: models.ApplicationEntity{
+						Name:  "stopped-app",
+						State: models.Stopped,
+					},
+					ApplicationMetadata: models.ApplicationMetadata{Guid: "stopped-app-guid"},
+				},
+			}
+			command.AppsGetterFunc = func(thingdoer.ApplicationsParser, thingdoer.PaginatedRequester) (models.Applications, error) {
+				return apps, nil
+			}
+
+			buf = NewBuffer()
+			stdout = captureStdout(buf)
+		})
+
+		AfterEach(func() {
+			os.Stdout.Close()
+			os.Stdout = stdout
+
+			server.Close()
+		})
+
+		JustBeforeEach(func() {
+			err = command.Execute(fakeConnection)
+		})
+
+		Context("when doing a dry run", func() {
+			BeforeEach(func() {
+				command.DryRun = true
+			})
+
+			It("reports what would happen without setting the diego flag", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(updatedGuids).To(BeEmpty())
+
+				Eventually(buf).Should(Say("App .+started-app.+ would be restarted on .+Diego"))
+				Eventually(buf).Should(Say("App .+stopped-app.+ would be configured to run on .+Diego.+ but not started"))
+				Eventually(buf).Should(Say("WARNING: Assuming health check of type process"))
+				Eventually(buf).Should(Say("Dry run of migration to .+Diego.+ completed: 2 apps would be migrated, 1 restarted, 1 warnings"))
+			})
+
+			Context("when apps are excluded by name", func() {
+				BeforeEach(func() {
+					matcher := new(thingdoerfakes.FakeNameMatcher)
+					matcher.MatchStub = func(name string) bool {
+						return name == "stopped-app"
+					}
+					command.NameFilter = thingdoer.AppNameFilter{
+						Exclude: []thingdoer.NameMatcher{matcher},
+					}
+				})
+
+				It("does not migrate them and reports how many were excluded", func() {
+					Eventually(buf).Should(Say("completed: 1 apps would be migrated"))
+					Eventually(buf).Should(Say("1 apps excluded by --include/--exclude"))
+				})
+			})
+		})
+
+		Context("when migrating", func() {
+			It("migrates every app", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(updatedGuids).To(ConsistOf("started-app-guid", "stopped-app-guid"))
+				Eventually(buf).Should(Say("completed: 2 apps, 0 errors"))
+			})
+
+			Context("when asked to confirm", func() {
+				var askedApps int
+
+				BeforeEach(func() {
+					askedApps = 0
+					command.Confirm = func(apps int) bool {
+						askedApps = apps
+						return false
+					}
+				})
+
+				It("asks with the number of apps and migrates none when declined", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(askedApps).To(Equal(2))
+					Expect(updatedGuids).To(BeEmpty())
+					Eventually(buf).Should(Say("Migration cancelled"))
+				})
+
+				Context("when confirmed", func() {
+					BeforeEach(func() {
+						command.Confirm = func(int) bool { return true }
+					})
+
+					It("migrates every app", func() {
+						Expect(updatedGuids).To(ConsistOf("started-app-guid", "stopped-app-guid"))
+					})
+				})
+
+				Context("when doing a dry run", func() {
+					BeforeEach(func() {
+						command.DryRun = true
+					})
+
+					It("does not ask", func() {
+						Expect(askedApps).To(BeZero())
+					})
+				})
+			})
+
+			Context("with a canary", func() {
+				BeforeEach(func() {
+					command.Canary = flaghelpers.CanaryFlag{Count: 1}
+				})
+
+				It("migrates the canary first and then the remaining apps", func() {
+					Eventually(buf).Should(Say("Migrating a canary of 1 apps"))
+					Eventually(buf).Should(Say("Migrating app .+started-app"))
+					Eventually(buf).Should(Say("Canary of 1 apps succeeded"))
+					Eventually(buf).Should(Say("Migrating app .+stopped-app"))
+					Eventually(buf).Should(Say("completed: 2 apps, 0 errors"))
+				})
+
+				Context("when the canary fails", func() {
+					BeforeEach(func() {
+						failingGuids["started-app-guid"] = true
+					})
+
+					It("does not migrate the remaining apps", func() {
+						Eventually(buf).Should(Say("Canary of 1 apps failed"))
+						Eventually(buf).Should(Say("completed: 0 apps, 1 errors"))
+						Eventually(buf).Should(Say("1 apps were not attempted"))
+						Expect(updatedGuids).To(Equal([]string{"started-app-guid"}))
+					})
+				})
+
+				Context("when migrating apps in parallel", func() {
+					var overlapped bool
+
+					BeforeEach(func() {
+						command.MaxInFlight = 2
+						command.Canary = flaghelpers.CanaryFlag{Count: 2}
+						apps = append(apps, models.Application{
+							ApplicationEntity:   models.ApplicationEntity{Name: "other-app", State: models.Stopped},
+							ApplicationMetadata: models.ApplicationMetadata{Guid: "other-app-guid"},
+						})
+
+						// the first two updates wait for each other, which
+						// only works when they are in flight together
+						overlapped = false
+						var (
+							barrier sync.WaitGroup
+							updates int
+						)
+						barrier.Add(2)
+						onUpdate = func(string) {
+							mutex.Lock()
+							updates++
+							first := updates <= 2
+							mutex.Unlock()
+							if !first {
+								return
+							}
+
+							barrier.Done()
+							both := make(chan struct{})
+							go func() {
+								barrier.Wait()
+								close(both)
+							}()
+
+							select {
+							case <-both:
+								mutex.Lock()
+								overlapped = true
+								mutex.Unlock()
+							case <-time.After(time.Second):
+							}
+						}
+					})
+
+					It("migrates the canary in parallel too", func() {
+						Expect(err).NotTo(HaveOccurred())
+						Expect(overlapped).To(BeTrue())
+						Eventually(buf).Should(Say("Migrating a canary of 2 apps"))
+						Eventually(buf).Should(Say("Canary of 2 apps succeeded"))
+						Eventually(buf).Should(Say("Migrating app .+other-app"))
+						Eventually(buf).Should(Say("completed: 3 apps, 0 errors"))
+					})
+				})
+
+				Context("when the canary covers every app", func() {
+					BeforeEach(func() {
+						command.Canary = flaghelpers.CanaryFlag{Count: 5}
+					})
+
+					It("migrates all apps as the canary", func() {
+						Expect(err).NotTo(HaveOccurred())
+						Eventually(buf).Should(Say("Migrating a canary of 2 apps"))
+						Eventually(buf).Should(Say("completed: 2 apps, 0 errors"))
+						Expect(buf.Contents()).NotTo(ContainSubstring("remaining apps"))
+						Expect(updatedGuids).To(ConsistOf("started-app-guid", "stopped-app-guid"))
+					})
+
+					Context("when the canary fails", func() {
+						BeforeEach(func() {
+							failingGuids["started-app-guid"] = true
+						})
+
+						It("reports the failure without any remaining apps to hold back", func() {
+							Eventually(buf).Should(Say("completed: 1 apps, 1 errors"))
+							Expect(buf.Contents()).NotTo(ContainSubstring("remaining apps"))
+							Expect(updatedGuids).To(ConsistOf("started-app-guid", "stopped-app-guid"))
+						})
+					})
+				})
+			})
+
+			Context("when resuming a journal", func() {
+				var dir string
+
+				journaledApp := func(guid string) models.Application {
+					return models.Application{
+						ApplicationEntity: models.ApplicationEntity{
+							Name:  strings.TrimSuffix(guid, "-guid"),
+							State: models.Stopped,
+						},
+						ApplicationMetadata: models.ApplicationMetadata{Guid: guid},
+					}
+				}
+
+				BeforeEach(func() {
+					var err error
+					dir, err = ioutil.TempDir("", "journal")
+					Expect(err).NotTo(HaveOccurred())
+
+					previous, err := OpenJournal(filepath.Join(dir, "journal"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(previous.Record(journaledApp("flagged-app-guid"), JournalFlagSet)).To(Succeed())
+					Expect(previous.Record(journaledApp("excluded-app-guid"), JournalFlagSet)).To(Succeed())
+					Expect(previous.Record(journaledApp("other-space-app-guid"), JournalFlagSet)).To(Succeed())
+					Expect(previous.Close()).To(Succeed())
+
+					command.Journal, err = OpenJournal(filepath.Join(dir, "journal"))
+					Expect(err).NotTo(HaveOccurred())
+
+					command.MigratedAppsGetter = func(thingdoer.ApplicationsParser, thingdoer.PaginatedRequester) (models.Applications, error) {
+						return models.Applications{journaledApp("flagged-app-guid"), journaledApp("excluded-app-guid")}, nil
+					}
+
+					matcher := new(thingdoerfakes.FakeNameMatcher)
+					matcher.MatchStub = func(name string) bool {
+						return name == "excluded-app"
+					}
+					command.NameFilter = thingdoer.AppNameFilter{
+						Exclude: []thingdoer.NameMatcher{matcher},
+					}
+				})
+
+				AfterEach(func() {
+					command.Journal.Close()
+					os.RemoveAll(dir)
+				})
+
+				It("only adds back the unfinished apps that are still selected", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(updatedGuids).To(ConsistOf("started-app-guid", "stopped-app-guid", "flagged-app-guid"))
+				})
+
+				Context("when the migrated apps cannot be listed", func() {
+					BeforeEach(func() {
+						command.MigratedAppsGetter = func(thingdoer.ApplicationsParser, thingdoer.PaginatedRequester) (models.Applications, error) {
+							return nil, errors.New("listing failed")
+						}
+					})
+
+					It("returns the error without migrating anything", func() {
+						Expect(err).To(MatchError("listing failed"))
+						Expect(updatedGuids).To(BeEmpty())
+					})
+				})
+			})
+
+			Context("with a maximum number of failures", func() {
+				BeforeEach(func() {
+					command.MaxFailures = 1
+					failingGuids["started-app-guid"] = true
+					failingGuids["stopped-app-guid"] = true
+				})
+
+				It("stops migrating apps once the limit is reached", func() {
+					Eventually(buf).Should(Say("1 apps failed to migrate, not migrating the remaining apps"))
+					Eventually(buf).Should(Say("completed: 0 apps, 1 errors"))
+					Eventually(buf).Should(Say("1 apps were not attempted"))
+				})
+			})
+
+			Context("when writing a report", func() {
+				var dir string
+
+				BeforeEach(func() {
+					var err error
+					dir, err = ioutil.TempDir("", "report")
+					Expect(err).NotTo(HaveOccurred())
+
+					command.ReportPath = filepath.Join(dir, "report.json")
+					command.ReportFormat = flaghelpers.ReportJSON
+					command.MaxFailures = 1
+					failingGuids["started-app-guid"] = true
+				})
+
+				AfterEach(func() {
+					os.RemoveAll(dir)
+				})
+
+				It("records the result of every app", func() {
+					Expect(err).NotTo(HaveOccurred())
+
+					contents, err := ioutil.ReadFile(command.ReportPath)
+					Expect(err).NotTo(HaveOccurred())
+
+					var apps []ReportApp
+					Expect(json.Unmarshal(contents, &apps)).To(Succeed())
+					Expect(apps).To(HaveLen(2))
+
+					Expect(apps[0].Name).To(Equal("started-app"))
+					Expect(apps[0].Guid).To(Equal("started-app-guid"))
+					Expect(apps[0].Source).To(Equal("DEA"))
+					Expect(apps[0].Target).To(Equal("Diego"))
+					Expect(apps[0].Result).To(Equal(ResultError))
+					Expect(apps[0].Message).To(Equal("CF-AppInvalid - disaster"))
+
+					Expect(apps[1].Name).To(Equal("stopped-app"))
+					Expect(apps[1].Result).To(Equal(ResultNotAttempted))
+				})
+			})
+
+			Context("when interrupted", func() {
+				BeforeEach(func() {
+					interrupt := make(chan struct{})
+					command.WatchInterrupts = func() (<-chan struct{}, func()) {
+						return interrupt, func() {}
+					}
+
+					onUpdate = func(guid string) {
+						if guid == "started-app-guid" {
+							close(interrupt)
+						}
+					}
+				})
+
+				It("finishes the app in progress and lists the apps never attempted", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(updatedGuids).To(Equal([]string{"started-app-guid"}))
+					Eventually(buf).Should(Say("Completed migrating app .+started-app"))
+					Eventually(buf).Should(Say("interrupted: 1 apps, 0 errors"))
+					Eventually(buf).Should(Say("Apps not attempted, still on .+DEA.+:"))
+					Eventually(buf).Should(Say("stopped-app"))
+				})
+			})
+
+			Context("when interrupted before the migration began", func() {
+				BeforeEach(func() {
+					interrupt := make(chan struct{})
+					close(interrupt)
+					command.WatchInterrupts = func() (<-chan struct{}, func()) {
+						return interrupt, func() {}
+					}
+				})
+
+				It("does not start any app", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(updatedGuids).To(BeEmpty())
+					Eventually(buf).Should(Say("interrupted: 0 apps, 0 errors"))
+				})
+			})
+
+			Context("when watching interrupts", func() {
+				var watching, stopped bool
+
+				BeforeEach(func() {
+					watching, stopped = false, false
+					command.WatchInterrupts = func() (<-chan struct{}, func()) {
+						watching = true
+						return nil, func() { stopped = true }
+					}
+				})
+
+				It("watches only while the apps are migrated", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(watching).To(BeTrue())
+					Expect(stopped).To(BeTrue())
+				})
+
+				Context("on a dry run", func() {
+					BeforeEach(func() {
+						command.DryRun = true
+					})
+
+					It("does not watch interrupts", func() {
+						Expect(err).NotTo(HaveOccurred())
+						Expect(watching).To(BeFalse())
+					})
+				})
+			})
+		})
+
+		Context("when applying a plan", func() {
+			BeforeEach(func() {
+				command.DryRun = true
+				plan := NewPlan(ui.Diego, apps, nil)
+				command.Plan = &plan
+			})
+
+			Context("when an app changed since the plan was made", func() {
+				BeforeEach(func() {
+					apps[1].State = models.Started
+				})
+
+				It("refuses to continue", func() {
+					Expect(err).To(BeAssignableToTypeOf(PlanChangedError{}))
+					Expect(err.Error()).To(ContainSubstring("stopped-app"))
+				})
+
+				Context("when skipping changed apps", func() {
+					BeforeEach(func() {
+						command.SkipChanged = true
+					})
+
+					It("migrates only the unchanged apps", func() {
+						Expect(err).NotTo(HaveOccurred())
+						Eventually(buf).Should(Say("WARNING: Skipping app .+stopped-app.+ because it changed"))
+						Eventually(buf).Should(Say("completed: 1 apps would be migrated"))
+					})
+				})
+			})
+
+			Context("when resuming the journal of an interrupted run of the plan", func() {
+				var (
+					dir          string
+					verifiedApp  models.Application
+					flaggedApp   models.Application
+					unplannedApp models.Application
+				)
+
+				BeforeEach(func() {
+					verifiedApp = models.Application{
+						ApplicationEntity:   models.ApplicationEntity{Name: "verified-app", State: models.Started},
+						ApplicationMetadata: models.ApplicationMetadata{Guid: "verified-app-guid"},
+					}
+					flaggedApp = models.Application{
+						ApplicationEntity:   models.ApplicationEntity{Name: "flagged-app", State: models.Started},
+						ApplicationMetadata: models.ApplicationMetadata{Guid: "flagged-app-guid"},
+					}
+					unplannedApp = models.Application{
+						ApplicationEntity:   models.ApplicationEntity{Name: "unplanned-app", State: models.Started},
+						ApplicationMetadata: models.ApplicationMetadata{Guid: "unplanned-app-guid"},
+					}
+
+					plan := NewPlan(ui.Diego, append(models.Applications{verifiedApp, flaggedApp}, apps...), nil)
+					command.Plan = &plan
+
+					var err error
+					dir, err = ioutil.TempDir("", "journal")
+					Expect(err).NotTo(HaveOccurred())
+
+					previous, err := OpenJournal(filepath.Join(dir, "journal"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(previous.Record(verifiedApp, JournalVerified)).To(Succeed())
+					Expect(previous.Record(flaggedApp, JournalFlagSet)).To(Succeed())
+					Expect(previous.Record(unplannedApp, JournalFlagSet)).To(Succeed())
+					Expect(previous.Close()).To(Succeed())
+
+					command.Journal, err = OpenJournal(filepath.Join(dir, "journal"))
+					Expect(err).NotTo(HaveOccurred())
+
+					command.MigratedAppsGetter = func(thingdoer.ApplicationsParser, thingdoer.PaginatedRequester) (models.Applications, error) {
+						return models.Applications{verifiedApp, flaggedApp, unplannedApp}, nil
+					}
+				})
+
+				AfterEach(func() {
+					command.Journal.Close()
+					os.RemoveAll(dir)
+				})
+
+				It("does not count the apps it already migrated as changed", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Eventually(buf).Should(Say("completed: 3 apps would be migrated"))
+				})
+
+				It("resumes the unfinished apps of the plan only", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Eventually(buf).Should(Say("completed"))
+					Expect(buf.Contents()).To(ContainSubstring("flagged-app"))
+					Expect(buf.Contents()).NotTo(ContainSubstring("unplanned-app"))
+				})
+
+				Context("when migrating", func() {
+					BeforeEach(func() {
+						command.DryRun = false
+					})
+
+					It("migrates the rest of the plan", func() {
+						Expect(err).NotTo(HaveOccurred())
+						Expect(updatedGuids).To(ConsistOf("flagged-app-guid", "started-app-guid", "stopped-app-guid"))
+					})
+				})
+
+				Context("when a planned app changed that the journal has not seen", func() {
+					BeforeEach(func() {
+						apps[1].State = models.Started
+					})
+
+					It("still refuses to continue", func() {
+						Expect(err).To(BeAssignableToTypeOf(PlanChangedError{}))
+						Expect(err.Error()).To(ContainSubstring("stopped-app"))
+						Expect(err.Error()).NotTo(ContainSubstring("verified-app"))
+					})
+				})
+			})
+		})
+	})
+
 	Describe("MigrateApp", func() {
 		var (
 			diegoSupport *migratehelpersfakes.FakeDiegoFlagSetter
@@ -59,19 +654,19 @@ var _ = Describe("MigrateApps", func() {
 		)
 
 		BeforeEach(func() {
-			os.Setenv("CF_STARTUP_TIMEOUT", "1")
-
 			buf = NewBuffer()
 			stdout = captureStdout(buf)
 
 			diegoSupport = new(migratehelpersfakes.FakeDiegoFlagSetter)
+			diegoSupport.InstanceStatesReturns([]string{"RUNNING"}, nil)
 			appPrinter = &displayhelpers.AppPrinter{
 				App: models.Application{
 					ApplicationEntity: models.ApplicationEntity{
-						Name:      "some-app",
-						Diego:     true,
-						State:     "STARTED",
-						SpaceGuid: "some-space-guid",
+						Name:          "some-app",
+						Diego:         true,
+						InstanceCount: 1,
+						State:         "STARTED",
+						SpaceGuid:     "some-space-guid",
 					},
 					ApplicationMetadata: models.ApplicationMetadata{
 						Guid: "some-app-guid",
@@ -93,8 +688,6 @@ var _ = Describe("MigrateApps", func() {
 		})
 
 		AfterEach(func() {
-			os.Unsetenv("CF_STARTUP_TIMEOUT")
-
 			os.Stdout.Close()
 			os.Stdout = stdout
 		})
@@ -106,7 +699,12 @@ var _ = Describe("MigrateApps", func() {
 		Context("when migrating the app fails", func() {
 			Context("when the user does not have permissions to migrate apps", func() {
 				BeforeEach(func() {
-					diegoSupport.SetDiegoFlagReturns(nil, errors.New("CF-NotAuthorized - You are not authorized to perform the requested action"))
+					diegoSupport.SetDiegoFlagReturns(api.CCError{
+						StatusCode:  http.StatusForbidden,
+						Code:        10003,
+						ErrorCode:   "CF-NotAuthorized",
+						Description: "You are not authorized to perform the requested action",
+					})
 				})
 
 				It("returns a warning", func() {
@@ -117,7 +715,7 @@ var _ = Describe("MigrateApps", func() {
 
 			Context("for any other reason", func() {
 				BeforeEach(func() {
-					diegoSupport.SetDiegoFlagReturns(nil, errors.New("disaster"))
+					diegoSupport.SetDiegoFlagReturns(errors.New("disaster"))
 				})
 
 				It("returns an error", func() {
@@ -127,9 +725,222 @@ var _ = Describe("MigrateApps", func() {
 			})
 		})
 
+		Context("when the app is started", func() {
+			BeforeEach(func() {
+				appPrinter.App.ApplicationEntity.HasRoutes = true
+				appPrinter.App.ApplicationEntity.InstanceCount = 2
+			})
+
+			Context("when all desired instances are running", func() {
+				BeforeEach(func() {
+					diegoSupport.InstanceStatesReturns([]string{"RUNNING", "RUNNING"}, nil)
+				})
+
+				It("polls the app instances and completes", func() {
+					Expect(migrationResult).To(Equal(Success))
+					Expect(diegoSupport.InstanceStatesCallCount()).To(Equal(1))
+					Expect(diegoSupport.InstanceStatesArgsForCall(0)).To(Equal("some-app-guid"))
+					Eventually(buf).Should(Say("Completed migrating app"))
+				})
+			})
+
+			Context("when the app is scaled to zero instances", func() {
+				BeforeEach(func() {
+					appPrinter.App.ApplicationEntity.InstanceCount = 0
+				})
+
+				It("completes without polling the app instances", func() {
+					Expect(migrationResult).To(Equal(Success))
+					Expect(diegoSupport.InstanceStatesCallCount()).To(Equal(0))
+					Eventually(buf).Should(Say("Completed migrating app"))
+				})
+			})
+
+			Context("when the instances start after a while", func() {
+				BeforeEach(func() {
+					diegoSupport.InstanceStatesStub = func(string) ([]string, error) {
+						switch diegoSupport.InstanceStatesCallCount() {
+						case 1:
+							return nil, errors.New("CF-NotStaged - App has not finished staging")
+						case 2:
+							return []string{"STARTING", "RUNNING"}, nil
+						default:
+							return []string{"RUNNING", "RUNNING"}, nil
+						}
+					}
+				})
+
+				It("keeps polling until all instances are running", func() {
+					Expect(migrationResult).To(Equal(Success))
+					Expect(diegoSupport.InstanceStatesCallCount()).To(Equal(3))
+				})
+			})
+
+			Context("when an instance crashes", func() {
+				BeforeEach(func() {
+					diegoSupport.InstanceStatesReturns([]string{"RUNNING", "CRASHED"}, nil)
+				})
+
+				It("returns the Crashed constant", func() {
+					Expect(migrationResult).To(Equal(Crashed))
+					Eventually(buf).Should(Say("Error: App .+some-app.+ crashed"))
+				})
+			})
+
+			Context("when the app fails to stage", func() {
+				BeforeEach(func() {
+					diegoSupport.InstanceStatesReturns(nil, api.CCError{
+						StatusCode:  http.StatusBadRequest,
+						Code:        170001,
+						ErrorCode:   "CF-StagingError",
+						Description: "Staging error: failed",
+					})
+				})
+
+				It("returns the Crashed constant", func() {
+					Expect(migrationResult).To(Equal(Crashed))
+				})
+			})
+
+			Context("when the instances never become running", func() {
+				BeforeEach(func() {
+					diegoSupport.InstanceStatesReturns([]string{"STARTING", "STARTING"}, nil)
+				})
+
+				It("returns the TimedOut constant", func() {
+					Expect(migrationResult).To(Equal(TimedOut))
+					Eventually(buf).Should(Say("Error: App .+some-app.+ did not start"))
+				})
+			})
+		})
+
+		Context("when rolling back on failure", func() {
+			BeforeEach(func() {
+				command.RollbackOnFailure = true
+				appPrinter.App.ApplicationEntity.Diego = false
+				appPrinter.App.ApplicationEntity.HasRoutes = true
+			})
+
+			Context("when the app starts on the new runtime", func() {
+				It("does not roll back", func() {
+					Expect(migrationResult).To(Equal(Success))
+					Expect(diegoSupport.SetDiegoFlagCallCount()).To(Equal(1))
+				})
+			})
+
+			Context("when the app does not start on the new runtime", func() {
+				BeforeEach(func() {
+					diegoSupport.InstanceStatesStub = func(string) ([]string, error) {
+						if diegoSupport.SetDiegoFlagCallCount() == 1 {
+							return []string{"CRASHED"}, nil
+						}
+						return []string{"RUNNING"}, nil
+					}
+				})
+
+				It("sets the diego flag back to its original value", func() {
+					Expect(diegoSupport.SetDiegoFlagCallCount()).To(Equal(2))
+
+					guid, enable := diegoSupport.SetDiegoFlagArgsForCall(1)
+					Expect(guid).To(Equal("some-app-guid"))
+					Expect(enable).To(BeFalse())
+				})
+
+				It("returns the RolledBack constant", func() {
+					Expect(migrationResult).To(Equal(RolledBack))
+					Eventually(buf).Should(Say("Rolled back app .+some-app.+ to .+DEA"))
+				})
+			})
+
+			Context("when the app does not recover after rolling back", func() {
+				BeforeEach(func() {
+					diegoSupport.InstanceStatesReturns([]string{"CRASHED"}, nil)
+				})
+
+				It("returns the original failure", func() {
+					Expect(diegoSupport.SetDiegoFlagCallCount()).To(Equal(2))
+					Expect(migrationResult).To(Equal(Crashed))
+					Eventually(buf).Should(Say("Error: Failed to roll back app"))
+				})
+			})
+
+			Context("when setting the diego flag back fails", func() {
+				BeforeEach(func() {
+					diegoSupport.InstanceStatesReturns([]string{"CRASHED"}, nil)
+					diegoSupport.SetDiegoFlagStub = func(string, bool) error {
+						if diegoSupport.SetDiegoFlagCallCount() == 2 {
+							return errors.New("disaster")
+						}
+						return nil
+					}
+				})
+
+				It("returns the original failure", func() {
+					Expect(migrationResult).To(Equal(Crashed))
+					Eventually(buf).Should(Say("Error: Failed to roll back app .+some-app.+: .+disaster"))
+				})
+			})
+		})
+
+		Context("when recording to a journal", func() {
+			var dir string
+
+			BeforeEach(func() {
+				var err error
+				dir, err = ioutil.TempDir("", "journal")
+				Expect(err).NotTo(HaveOccurred())
+
+				command.Journal, err = OpenJournal(filepath.Join(dir, "journal"))
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			AfterEach(func() {
+				command.Journal.Close()
+				os.RemoveAll(dir)
+			})
+
+			readJournal := func() string {
+				contents, err := ioutil.ReadFile(filepath.Join(dir, "journal"))
+				Expect(err).NotTo(HaveOccurred())
+				return string(contents)
+			}
+
+			Context("when the migration succeeds", func() {
+				It("records the flag being set and the app being verified", func() {
+					contents := readJournal()
+					Expect(contents).To(MatchRegexp(`"state":"flag-set".*"guid":"some-app-guid"`))
+					Expect(contents).To(MatchRegexp(`"state":"verified".*"guid":"some-app-guid"`))
+				})
+			})
+
+			Context("when the migration fails", func() {
+				BeforeEach(func() {
+					diegoSupport.SetDiegoFlagReturns(errors.New("disaster"))
+				})
+
+				It("records the app as failed", func() {
+					contents := readJournal()
+					Expect(contents).NotTo(ContainSubstring("flag-set"))
+					Expect(contents).To(MatchRegexp(`"state":"failed".*"guid":"some-app-guid"`))
+				})
+			})
+		})
+
+		Context("when the app is stopped", func() {
+			BeforeEach(func() {
+				appPrinter.App.ApplicationEntity.HasRoutes = true
+				appPrinter.App.ApplicationEntity.State = "STOPPED"
+			})
+
+			It("does not wait for the app to start", func() {
+				Expect(migrationResult).To(Equal(Success))
+				Expect(diegoSupport.InstanceStatesCallCount()).To(Equal(0))
+			})
+		})
+
 		Context("when migrating to Diego", func() {
 			BeforeEach(func() {
-				diegoSupport.SetDiegoFlagReturns(nil, nil)
+				diegoSupport.SetDiegoFlagReturns(nil)
 				command.Runtime = ui.Diego
 			})
 
@@ -160,7 +971,7 @@ var _ = Describe("MigrateApps", func() {
 
 		Context("when the runtime is DEA", func() {
 			BeforeEach(func() {
-				diegoSupport.SetDiegoFlagReturns(nil, nil)
+				diegoSupport.SetDiegoFlagReturns(nil)
 				command.Runtime = ui.DEA
 			})
 
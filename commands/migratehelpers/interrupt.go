@@ -0,0 +1,44 @@
+package migratehelpers
+
+import (
+	"os"
+	"sync"
+)
+
+// WatchInterrupts closes the returned channel on the first signal so a
+// migration can finish the apps in progress, and calls abort on the second
+// one. The returned stop function ends the watch; once it returns neither
+// callback is called any more.
+func WatchInterrupts(signals <-chan os.Signal, interrupted func(), abort func()) (<-chan struct{}, func()) {
+	interrupt := make(chan struct{})
+	done := make(chan struct{})
+	exited := make(chan struct{})
+
+	go func() {
+		defer close(exited)
+
+		select {
+		case <-signals:
+		case <-done:
+			return
+		}
+		close(interrupt)
+		interrupted()
+
+		select {
+		case <-signals:
+			abort()
+		case <-done:
+		}
+	}()
+
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			close(done)
+			<-exited
+		})
+	}
+
+	return interrupt, stop
+}
@@ -0,0 +1,87 @@
+package migratehelpers_test
+
+import (
+	"os"
+	"syscall"
+
+	. "github.com/cloudfoundry-incubator/diego-enabler/commands/migratehelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WatchInterrupts", func() {
+	var (
+		signals     chan os.Signal
+		interrupted chan bool
+		aborted     chan bool
+
+		interrupt <-chan struct{}
+		stop      func()
+	)
+
+	BeforeEach(func() {
+		signals = make(chan os.Signal)
+		interrupted = make(chan bool, 1)
+		aborted = make(chan bool, 1)
+
+		interrupt, stop = WatchInterrupts(
+			signals,
+			func() { interrupted <- true },
+			func() { aborted <- true },
+		)
+	})
+
+	AfterEach(func() {
+		stop()
+	})
+
+	It("closes the interrupt channel on the first signal", func() {
+		Consistently(interrupt).ShouldNot(BeClosed())
+
+		signals <- os.Interrupt
+
+		Eventually(interrupt).Should(BeClosed())
+		Eventually(interrupted).Should(Receive())
+		Consistently(aborted).ShouldNot(Receive())
+	})
+
+	It("aborts on the second signal", func() {
+		signals <- syscall.SIGTERM
+		Eventually(interrupted).Should(Receive())
+
+		signals <- syscall.SIGTERM
+		Eventually(aborted).Should(Receive())
+	})
+
+	It("ignores signals once stopped", func() {
+		stop()
+
+		Consistently(func() bool {
+			select {
+			case signals <- os.Interrupt:
+				return true
+			default:
+				return false
+			}
+		}).Should(BeFalse())
+		Expect(interrupt).NotTo(BeClosed())
+	})
+
+	It("does not abort once stopped after the first signal", func() {
+		signals <- os.Interrupt
+		Eventually(interrupted).Should(Receive())
+
+		stop()
+
+		Consistently(func() bool {
+			select {
+			case signals <- os.Interrupt:
+				return true
+			default:
+				return false
+			}
+		}).Should(BeFalse())
+		Expect(aborted).NotTo(Receive())
+	})
+})
@@ -0,0 +1,195 @@
+package migratehelpers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/cloudfoundry-incubator/diego-enabler/models"
+)
+
+type JournalState string
+
+const (
+	JournalPending  JournalState = "pending"
+	JournalFlagSet  JournalState = "flag-set"
+	JournalVerified JournalState = "verified"
+	JournalFailed   JournalState = "failed"
+)
+
+type journalEntry struct {
+	State JournalState       `json:"state"`
+	App   models.Application `json:"app"`
+}
+
+// Journal is an append-only record of the migration state of every app. Each
+// line holds one state change, so a run that is interrupted at any point can
+// be resumed from the last line that was written.
+type Journal struct {
+	Path string
+
+	file    *os.File
+	mutex   sync.Mutex
+	entries map[string]journalEntry
+	order   []string
+}
+
+type InvalidJournalError struct {
+	Path string
+	Line int
+}
+
+func (e InvalidJournalError) Error() string {
+	return fmt.Sprintf("Invalid journal %s: line %d is not a journal entry", e.Path, e.Line)
+}
+
+func OpenJournal(path string) (*Journal, error) {
+	journal := &Journal{
+		Path:    path,
+		entries: map[string]journalEntry{},
+	}
+
+	err := journal.load()
+	if err != nil {
+		return nil, err
+	}
+
+	journal.file, err = os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	return journal, nil
+}
+
+func (j *Journal) load() error {
+	file, err := os.Open(j.Path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var lines [][]byte
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, append([]byte{}, scanner.Bytes()...))
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	for i, line := range lines {
+		var entry journalEntry
+		err := json.Unmarshal(line, &entry)
+		if err != nil || entry.App.Guid == "" {
+			// the last line may have been cut short when the previous run was
+			// killed; everything before it is still valid
+			if i == len(lines)-1 {
+				break
+			}
+			return InvalidJournalError{Path: j.Path, Line: i + 1}
+		}
+
+		j.put(entry)
+	}
+
+	return nil
+}
+
+func (j *Journal) put(entry journalEntry) {
+	if _, ok := j.entries[entry.App.Guid]; !ok {
+		j.order = append(j.order, entry.App.Guid)
+	}
+	j.entries[entry.App.Guid] = entry
+}
+
+// Resume drops the apps that were already verified by a previous run and adds
+// back the journaled apps that never finished. Those no longer show up in
+// apps once their diego flag has been set, so they are only added back when
+// they show up in migrated, the selected apps that already run on the target
+// runtime. Apps journaled by a run over other orgs, spaces or filters are left
+// alone.
+func (j *Journal) Resume(apps, migrated models.Applications) (models.Applications, int) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	var remaining models.Applications
+	skipped := 0
+	seen := map[string]bool{}
+
+	for _, app := range apps {
+		seen[app.Guid] = true
+		if entry, ok := j.entries[app.Guid]; ok && entry.State == JournalVerified {
+			skipped++
+			continue
+		}
+		remaining = append(remaining, app)
+	}
+
+	selected := map[string]bool{}
+	for _, app := range migrated {
+		selected[app.Guid] = true
+	}
+
+	for _, guid := range j.order {
+		entry := j.entries[guid]
+		if seen[guid] || !selected[guid] {
+			continue
+		}
+
+		if entry.State == JournalVerified {
+			skipped++
+			continue
+		}
+		remaining = append(remaining, entry.App)
+	}
+
+	return remaining, skipped
+}
+
+// Verified tells whether a previous run migrated the app and saw it start.
+func (j *Journal) Verified(guid string) bool {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	entry, ok := j.entries[guid]
+	return ok && entry.State == JournalVerified
+}
+
+func (j *Journal) Record(app models.Application, state JournalState) error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	entry := journalEntry{
+		State: state,
+		App:   app,
+	}
+
+	if existing, ok := j.entries[app.Guid]; ok {
+		// keep the app as it was before the first migration attempt, so a
+		// rollback still knows the original runtime
+		entry.App = existing.App
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	_, err = j.file.Write(append(line, '\n'))
+	if err != nil {
+		return err
+	}
+
+	j.put(entry)
+	return nil
+}
+
+func (j *Journal) Close() error {
+	return j.file.Close()
+}
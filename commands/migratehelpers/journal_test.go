@@ -0,0 +1,147 @@
+package migratehelpers_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/cloudfoundry-incubator/diego-enabler/commands/migratehelpers"
+	"github.com/cloudfoundry-incubator/diego-enabler/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Journal", func() {
+	var (
+		dir     string
+		path    string
+		journal *Journal
+
+		appA models.Application
+		appB models.Application
+		appC models.Application
+	)
+
+	newApp := func(guid string) models.Application {
+		return models.Application{
+			ApplicationEntity: models.ApplicationEntity{
+				Name: guid + "-name",
+			},
+			ApplicationMetadata: models.ApplicationMetadata{
+				Guid: guid,
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "journal")
+		Expect(err).NotTo(HaveOccurred())
+		path = filepath.Join(dir, "migration.journal")
+
+		appA = newApp("app-a")
+		appB = newApp("app-b")
+		appC = newApp("app-c")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	Context("when the journal does not exist yet", func() {
+		BeforeEach(func() {
+			var err error
+			journal, err = OpenJournal(path)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			journal.Close()
+		})
+
+		It("creates it and resumes all apps", func() {
+			Expect(path).To(BeARegularFile())
+
+			apps, skipped := journal.Resume(models.Applications{appA, appB}, nil)
+			Expect(apps).To(Equal(models.Applications{appA, appB}))
+			Expect(skipped).To(Equal(0))
+		})
+	})
+
+	Context("when resuming a previous run", func() {
+		BeforeEach(func() {
+			previous, err := OpenJournal(path)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(previous.Record(appA, JournalPending)).To(Succeed())
+			Expect(previous.Record(appB, JournalPending)).To(Succeed())
+			Expect(previous.Record(appC, JournalPending)).To(Succeed())
+			Expect(previous.Record(appA, JournalFlagSet)).To(Succeed())
+			Expect(previous.Record(appA, JournalVerified)).To(Succeed())
+			Expect(previous.Record(appB, JournalFlagSet)).To(Succeed())
+			Expect(previous.Close()).To(Succeed())
+
+			journal, err = OpenJournal(path)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			journal.Close()
+		})
+
+		It("skips verified apps and retries the rest", func() {
+			apps, skipped := journal.Resume(models.Applications{appC}, models.Applications{appA, appB})
+			Expect(skipped).To(Equal(1))
+			Expect(apps).To(Equal(models.Applications{appC, appB}))
+		})
+
+		It("skips verified apps that are still listed", func() {
+			apps, skipped := journal.Resume(models.Applications{appA, appC}, models.Applications{appB})
+			Expect(skipped).To(Equal(1))
+			Expect(apps).To(Equal(models.Applications{appC, appB}))
+		})
+
+		It("leaves alone the journaled apps that are no longer selected", func() {
+			apps, skipped := journal.Resume(models.Applications{appC}, nil)
+			Expect(skipped).To(Equal(0))
+			Expect(apps).To(Equal(models.Applications{appC}))
+		})
+	})
+
+	Context("when the last line was cut short", func() {
+		BeforeEach(func() {
+			previous, err := OpenJournal(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(previous.Record(appA, JournalVerified)).To(Succeed())
+			Expect(previous.Close()).To(Succeed())
+
+			file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = file.WriteString(`{"state":"ver`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(file.Close()).To(Succeed())
+		})
+
+		It("ignores the partial entry", func() {
+			journal, err := OpenJournal(path)
+			Expect(err).NotTo(HaveOccurred())
+			defer journal.Close()
+
+			apps, skipped := journal.Resume(models.Applications{appA}, nil)
+			Expect(apps).To(BeEmpty())
+			Expect(skipped).To(Equal(1))
+		})
+	})
+
+	Context("when the file is not a journal", func() {
+		BeforeEach(func() {
+			Expect(ioutil.WriteFile(path, []byte("not\na journal\n"), 0600)).To(Succeed())
+		})
+
+		It("returns an error", func() {
+			_, err := OpenJournal(path)
+			Expect(err).To(MatchError(InvalidJournalError{Path: path, Line: 1}))
+		})
+	})
+})
@@ -8,30 +8,38 @@ import (
 )
 
 type FakeDiegoFlagSetter struct {
-	SetDiegoFlagStub        func(string, bool) ([]string, error)
+	SetDiegoFlagStub        func(string, bool) error
 	setDiegoFlagMutex       sync.RWMutex
 	setDiegoFlagArgsForCall []struct {
 		arg1 string
 		arg2 bool
 	}
 	setDiegoFlagReturns struct {
-		result1 []string
-		result2 error
+		result1 error
 	}
-	HasRoutesStub        func(appName string) (bool, error)
+	HasRoutesStub        func(appGuid string) (bool, error)
 	hasRoutesMutex       sync.RWMutex
 	hasRoutesArgsForCall []struct {
-		appName string
+		appGuid string
 	}
 	hasRoutesReturns struct {
 		result1 bool
 		result2 error
 	}
+	InstanceStatesStub        func(appGuid string) ([]string, error)
+	instanceStatesMutex       sync.RWMutex
+	instanceStatesArgsForCall []struct {
+		appGuid string
+	}
+	instanceStatesReturns struct {
+		result1 []string
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeDiegoFlagSetter) SetDiegoFlag(arg1 string, arg2 bool) ([]string, error) {
+func (fake *FakeDiegoFlagSetter) SetDiegoFlag(arg1 string, arg2 bool) error {
 	fake.setDiegoFlagMutex.Lock()
 	fake.setDiegoFlagArgsForCall = append(fake.setDiegoFlagArgsForCall, struct {
 		arg1 string
@@ -42,7 +50,7 @@ func (fake *FakeDiegoFlagSetter) SetDiegoFlag(arg1 string, arg2 bool) ([]string,
 	if fake.SetDiegoFlagStub != nil {
 		return fake.SetDiegoFlagStub(arg1, arg2)
 	} else {
-		return fake.setDiegoFlagReturns.result1, fake.setDiegoFlagReturns.result2
+		return fake.setDiegoFlagReturns.result1
 	}
 }
 
@@ -58,23 +66,22 @@ func (fake *FakeDiegoFlagSetter) SetDiegoFlagArgsForCall(i int) (string, bool) {
 	return fake.setDiegoFlagArgsForCall[i].arg1, fake.setDiegoFlagArgsForCall[i].arg2
 }
 
-func (fake *FakeDiegoFlagSetter) SetDiegoFlagReturns(result1 []string, result2 error) {
+func (fake *FakeDiegoFlagSetter) SetDiegoFlagReturns(result1 error) {
 	fake.SetDiegoFlagStub = nil
 	fake.setDiegoFlagReturns = struct {
-		result1 []string
-		result2 error
-	}{result1, result2}
+		result1 error
+	}{result1}
 }
 
-func (fake *FakeDiegoFlagSetter) HasRoutes(appName string) (bool, error) {
+func (fake *FakeDiegoFlagSetter) HasRoutes(appGuid string) (bool, error) {
 	fake.hasRoutesMutex.Lock()
 	fake.hasRoutesArgsForCall = append(fake.hasRoutesArgsForCall, struct {
-		appName string
-	}{appName})
-	fake.recordInvocation("HasRoutes", []interface{}{appName})
+		appGuid string
+	}{appGuid})
+	fake.recordInvocation("HasRoutes", []interface{}{appGuid})
 	fake.hasRoutesMutex.Unlock()
 	if fake.HasRoutesStub != nil {
-		return fake.HasRoutesStub(appName)
+		return fake.HasRoutesStub(appGuid)
 	} else {
 		return fake.hasRoutesReturns.result1, fake.hasRoutesReturns.result2
 	}
@@ -89,7 +96,7 @@ func (fake *FakeDiegoFlagSetter) HasRoutesCallCount() int {
 func (fake *FakeDiegoFlagSetter) HasRoutesArgsForCall(i int) string {
 	fake.hasRoutesMutex.RLock()
 	defer fake.hasRoutesMutex.RUnlock()
-	return fake.hasRoutesArgsForCall[i].appName
+	return fake.hasRoutesArgsForCall[i].appGuid
 }
 
 func (fake *FakeDiegoFlagSetter) HasRoutesReturns(result1 bool, result2 error) {
@@ -100,6 +107,40 @@ func (fake *FakeDiegoFlagSetter) HasRoutesReturns(result1 bool, result2 error) {
 	}{result1, result2}
 }
 
+func (fake *FakeDiegoFlagSetter) InstanceStates(appGuid string) ([]string, error) {
+	fake.instanceStatesMutex.Lock()
+	fake.instanceStatesArgsForCall = append(fake.instanceStatesArgsForCall, struct {
+		appGuid string
+	}{appGuid})
+	fake.recordInvocation("InstanceStates", []interface{}{appGuid})
+	fake.instanceStatesMutex.Unlock()
+	if fake.InstanceStatesStub != nil {
+		return fake.InstanceStatesStub(appGuid)
+	} else {
+		return fake.instanceStatesReturns.result1, fake.instanceStatesReturns.result2
+	}
+}
+
+func (fake *FakeDiegoFlagSetter) InstanceStatesCallCount() int {
+	fake.instanceStatesMutex.RLock()
+	defer fake.instanceStatesMutex.RUnlock()
+	return len(fake.instanceStatesArgsForCall)
+}
+
+func (fake *FakeDiegoFlagSetter) InstanceStatesArgsForCall(i int) string {
+	fake.instanceStatesMutex.RLock()
+	defer fake.instanceStatesMutex.RUnlock()
+	return fake.instanceStatesArgsForCall[i].appGuid
+}
+
+func (fake *FakeDiegoFlagSetter) InstanceStatesReturns(result1 []string, result2 error) {
+	fake.InstanceStatesStub = nil
+	fake.instanceStatesReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeDiegoFlagSetter) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -107,6 +148,8 @@ func (fake *FakeDiegoFlagSetter) Invocations() map[string][][]interface{} {
 	defer fake.setDiegoFlagMutex.RUnlock()
 	fake.hasRoutesMutex.RLock()
 	defer fake.hasRoutesMutex.RUnlock()
+	fake.instanceStatesMutex.RLock()
+	defer fake.instanceStatesMutex.RUnlock()
 	return fake.invocations
 }
 
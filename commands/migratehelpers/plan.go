@@ -0,0 +1,186 @@
+package migratehelpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/diego-enabler/commands/displayhelpers"
+	"github.com/cloudfoundry-incubator/diego-enabler/commands/flaghelpers"
+	"github.com/cloudfoundry-incubator/diego-enabler/models"
+	"github.com/cloudfoundry-incubator/diego-enabler/ui"
+	"gopkg.in/yaml.v2"
+)
+
+// Plan is the reviewed list of apps a migration is allowed to touch. It is
+// written as YAML when the file name ends in .yml or .yaml and as JSON
+// otherwise.
+type Plan struct {
+	Runtime ui.Runtime `json:"runtime" yaml:"runtime"`
+	Apps    []PlanApp  `json:"apps" yaml:"apps"`
+}
+
+type PlanApp struct {
+	Organization string     `json:"org" yaml:"org"`
+	Space        string     `json:"space" yaml:"space"`
+	Name         string     `json:"name" yaml:"name"`
+	Guid         string     `json:"guid" yaml:"guid"`
+	Runtime      ui.Runtime `json:"runtime" yaml:"runtime"`
+	State        string     `json:"state" yaml:"state"`
+	HasRoutes    bool       `json:"has_routes" yaml:"has_routes"`
+	Restart      bool       `json:"restart" yaml:"restart"`
+}
+
+type PlanRuntimeMismatchError struct {
+	Path    string
+	Planned ui.Runtime
+}
+
+func (e PlanRuntimeMismatchError) Error() string {
+	return fmt.Sprintf("Plan %s migrates apps to %s", e.Path, e.Planned)
+}
+
+type PlanChangedError struct {
+	Apps []PlanApp
+}
+
+func (e PlanChangedError) Error() string {
+	var names []string
+	for _, app := range e.Apps {
+		names = append(names, fmt.Sprintf("%s (%s / %s)", app.Name, app.Organization, app.Space))
+	}
+
+	return fmt.Sprintf(
+		"Apps changed since the plan was made: %s\nCreate a new plan or pass --skip-changed to migrate the remaining apps",
+		strings.Join(names, ", "),
+	)
+}
+
+func NewPlan(runtime ui.Runtime, apps models.Applications, spaceMap map[string]models.Space) Plan {
+	plan := Plan{Runtime: runtime}
+
+	for _, app := range apps {
+		appPrinter := &displayhelpers.AppPrinter{
+			App:    app,
+			Spaces: spaceMap,
+		}
+
+		plan.Apps = append(plan.Apps, PlanApp{
+			Organization: appPrinter.Organization(),
+			Space:        appPrinter.Space(),
+			Name:         app.Name,
+			Guid:         app.Guid,
+			Runtime:      runtime.Flip(),
+			State:        app.State,
+			HasRoutes:    app.HasRoutes,
+			Restart:      app.State == models.Started,
+		})
+	}
+
+	return plan
+}
+
+func LoadPlan(path string) (Plan, error) {
+	var plan Plan
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	if isYAML(path) {
+		err = yaml.Unmarshal(contents, &plan)
+	} else {
+		err = json.Unmarshal(contents, &plan)
+	}
+	if err != nil {
+		return Plan{}, fmt.Errorf("Invalid plan %s: %s", path, err)
+	}
+
+	return plan, nil
+}
+
+func (p Plan) Save(path string) error {
+	var (
+		contents []byte
+		err      error
+	)
+
+	if isYAML(path) {
+		contents, err = yaml.Marshal(p)
+	} else {
+		contents, err = json.MarshalIndent(p, "", "  ")
+	}
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, contents, 0644)
+}
+
+// Select returns the apps that are part of the plan and still match it. The
+// apps that are missing from apps, which holds everything currently on the
+// planned source runtime, or whose state differs are returned as changed.
+func (p Plan) Select(apps models.Applications) (models.Applications, []PlanApp) {
+	current := map[string]models.Application{}
+	for _, app := range apps {
+		current[app.Guid] = app
+	}
+
+	var (
+		selected models.Applications
+		changed  []PlanApp
+	)
+
+	for _, planned := range p.Apps {
+		app, ok := current[planned.Guid]
+		if !ok || app.State != planned.State {
+			changed = append(changed, planned)
+			continue
+		}
+
+		selected = append(selected, app)
+	}
+
+	return selected, changed
+}
+
+// Only keeps the apps that are part of the plan, whatever their state.
+func (p Plan) Only(apps models.Applications) models.Applications {
+	planned := map[string]bool{}
+	for _, app := range p.Apps {
+		planned[app.Guid] = true
+	}
+
+	var kept models.Applications
+	for _, app := range apps {
+		if planned[app.Guid] {
+			kept = append(kept, app)
+		}
+	}
+	return kept
+}
+
+// Spaces lists each space the planned apps are in once, in the order the
+// plan first names them, so that a plan scopes the migration to its apps.
+func (p Plan) Spaces() []flaghelpers.SpaceFlag {
+	seen := map[flaghelpers.SpaceFlag]bool{}
+
+	var spaces []flaghelpers.SpaceFlag
+	for _, app := range p.Apps {
+		space := flaghelpers.SpaceFlag{Organization: app.Organization, Space: app.Space}
+		if seen[space] {
+			continue
+		}
+		seen[space] = true
+		spaces = append(spaces, space)
+	}
+	return spaces
+}
+
+func isYAML(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yml" || ext == ".yaml"
+}
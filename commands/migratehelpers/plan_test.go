@@ -0,0 +1,181 @@
+package migratehelpers_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/cloudfoundry-incubator/diego-enabler/commands/flaghelpers"
+	. "github.com/cloudfoundry-incubator/diego-enabler/commands/migratehelpers"
+	"github.com/cloudfoundry-incubator/diego-enabler/models"
+	"github.com/cloudfoundry-incubator/diego-enabler/ui"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Plan", func() {
+	var (
+		apps     models.Applications
+		spaceMap map[string]models.Space
+		plan     Plan
+	)
+
+	BeforeEach(func() {
+		apps = models.Applications{
+			{
+				ApplicationEntity: models.ApplicationEntity{
+					Name:      "started-app",
+					State:     models.Started,
+					SpaceGuid: "some-space-guid",
+					HasRoutes: true,
+				},
+				ApplicationMetadata: models.ApplicationMetadata{Guid: "started-app-guid"},
+			},
+			{
+				ApplicationEntity: models.ApplicationEntity{
+					Name:      "stopped-app",
+					State:     models.Stopped,
+					SpaceGuid: "some-space-guid",
+				},
+				ApplicationMetadata: models.ApplicationMetadata{Guid: "stopped-app-guid"},
+			},
+		}
+
+		spaceMap = map[string]models.Space{
+			"some-space-guid": {
+				SpaceEntity: models.SpaceEntity{
+					Name: "some-space",
+					Organization: models.Organization{
+						OrganizationEntity: models.OrganizationEntity{Name: "some-org"},
+					},
+				},
+			},
+		}
+
+		plan = NewPlan(ui.Diego, apps, spaceMap)
+	})
+
+	Describe("NewPlan", func() {
+		It("lists every app with its current runtime and expected restart", func() {
+			Expect(plan.Runtime).To(Equal(ui.Diego))
+			Expect(plan.Apps).To(Equal([]PlanApp{
+				{
+					Organization: "some-org",
+					Space:        "some-space",
+					Name:         "started-app",
+					Guid:         "started-app-guid",
+					Runtime:      ui.DEA,
+					State:        models.Started,
+					HasRoutes:    true,
+					Restart:      true,
+				},
+				{
+					Organization: "some-org",
+					Space:        "some-space",
+					Name:         "stopped-app",
+					Guid:         "stopped-app-guid",
+					Runtime:      ui.DEA,
+					State:        models.Stopped,
+				},
+			}))
+		})
+	})
+
+	Describe("Save and LoadPlan", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "plan")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		It("round trips a JSON plan", func() {
+			path := filepath.Join(dir, "plan.json")
+			Expect(plan.Save(path)).To(Succeed())
+
+			contents, err := ioutil.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(ContainSubstring(`"guid": "started-app-guid"`))
+
+			loaded, err := LoadPlan(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(loaded).To(Equal(plan))
+		})
+
+		It("round trips a YAML plan", func() {
+			path := filepath.Join(dir, "plan.yml")
+			Expect(plan.Save(path)).To(Succeed())
+
+			contents, err := ioutil.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(ContainSubstring("guid: started-app-guid"))
+
+			loaded, err := LoadPlan(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(loaded).To(Equal(plan))
+		})
+
+		It("returns an error for an invalid plan", func() {
+			path := filepath.Join(dir, "plan.json")
+			Expect(ioutil.WriteFile(path, []byte("not json"), 0644)).To(Succeed())
+
+			_, err := LoadPlan(path)
+			Expect(err).To(MatchError(ContainSubstring("Invalid plan")))
+		})
+	})
+
+	Describe("Select", func() {
+		It("selects the planned apps that did not change", func() {
+			selected, changed := plan.Select(apps)
+			Expect(selected).To(Equal(apps))
+			Expect(changed).To(BeEmpty())
+		})
+
+		It("ignores apps that are not part of the plan", func() {
+			other := models.Application{ApplicationMetadata: models.ApplicationMetadata{Guid: "other-guid"}}
+
+			selected, _ := plan.Select(append(apps, other))
+			Expect(selected).To(Equal(apps))
+		})
+
+		It("reports apps whose state changed", func() {
+			apps[1].State = models.Started
+
+			selected, changed := plan.Select(apps)
+			Expect(selected).To(Equal(apps[:1]))
+			Expect(changed).To(Equal(plan.Apps[1:]))
+		})
+
+		It("reports apps that are no longer on the source runtime", func() {
+			selected, changed := plan.Select(apps[1:])
+			Expect(selected).To(Equal(apps[1:]))
+			Expect(changed).To(Equal(plan.Apps[:1]))
+		})
+	})
+
+	Describe("Only", func() {
+		It("keeps the planned apps whatever their state", func() {
+			apps[1].State = models.Started
+			other := models.Application{ApplicationMetadata: models.ApplicationMetadata{Guid: "other-guid"}}
+
+			Expect(plan.Only(append(apps, other))).To(Equal(apps))
+		})
+	})
+
+	Describe("Spaces", func() {
+		It("lists each space of the planned apps once", func() {
+			plan.Apps = append(plan.Apps, PlanApp{Organization: "other-org", Space: "some-space"})
+
+			Expect(plan.Spaces()).To(Equal([]flaghelpers.SpaceFlag{
+				{Organization: "some-org", Space: "some-space"},
+				{Organization: "other-org", Space: "some-space"},
+			}))
+		})
+	})
+})
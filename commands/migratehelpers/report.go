@@ -0,0 +1,226 @@
+package migratehelpers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/cloudfoundry-incubator/diego-enabler/commands/displayhelpers"
+	"github.com/cloudfoundry-incubator/diego-enabler/commands/flaghelpers"
+	"github.com/cloudfoundry-incubator/diego-enabler/models"
+	"github.com/cloudfoundry-incubator/diego-enabler/ui"
+)
+
+const (
+	ResultSucceeded    = "succeeded"
+	ResultWarning      = "succeeded-with-warning"
+	ResultUnauthorized = "unauthorized"
+	ResultError        = "error"
+	ResultCrashed      = "crashed"
+	ResultTimedOut     = "timed-out"
+	ResultRolledBack   = "rolled-back"
+	ResultNotAttempted = "not-attempted"
+)
+
+var resultNames = map[int]string{
+	Success:     ResultSucceeded,
+	OKWarning:   ResultWarning,
+	FailWarning: ResultUnauthorized,
+	Err:         ResultError,
+	Crashed:     ResultCrashed,
+	TimedOut:    ResultTimedOut,
+	RolledBack:  ResultRolledBack,
+}
+
+// Report holds one record per app that was selected for the migration,
+// including the apps that were never attempted.
+type Report struct {
+	Runtime ui.Runtime
+	Apps    []ReportApp
+}
+
+type ReportApp struct {
+	Organization string  `json:"org"`
+	Space        string  `json:"space"`
+	Name         string  `json:"name"`
+	Guid         string  `json:"guid"`
+	Source       string  `json:"source_runtime"`
+	Target       string  `json:"target_runtime"`
+	Result       string  `json:"result"`
+	Message      string  `json:"message"`
+	Duration     float64 `json:"duration_seconds"`
+}
+
+func newReport(runtime ui.Runtime, results []migrationResult, apps models.Applications, spaceMap map[string]models.Space) Report {
+	report := Report{Runtime: runtime}
+	attempted := map[string]bool{}
+
+	newApp := func(appPrinter *displayhelpers.AppPrinter) ReportApp {
+		return ReportApp{
+			Organization: appPrinter.Organization(),
+			Space:        appPrinter.Space(),
+			Name:         appPrinter.Name(),
+			Guid:         appPrinter.App.Guid,
+			Source:       runtime.Flip().String(),
+			Target:       runtime.String(),
+		}
+	}
+
+	for _, result := range results {
+		attempted[result.App.App.Guid] = true
+
+		app := newApp(result.App)
+		app.Result = resultNames[result.Status]
+		app.Message = result.Message
+		app.Duration = result.Duration.Seconds()
+		report.Apps = append(report.Apps, app)
+	}
+
+	for _, app := range apps {
+		if attempted[app.Guid] {
+			continue
+		}
+
+		notAttempted := newApp(&displayhelpers.AppPrinter{
+			App:    app,
+			Spaces: spaceMap,
+		})
+		notAttempted.Result = ResultNotAttempted
+		report.Apps = append(report.Apps, notAttempted)
+	}
+
+	return report
+}
+
+func (r Report) Save(path string, format string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	err = r.Write(file, format)
+	if err != nil {
+		return err
+	}
+
+	return file.Close()
+}
+
+func (r Report) Write(w io.Writer, format string) error {
+	switch format {
+	case flaghelpers.ReportCSV:
+		return r.writeCSV(w)
+	case flaghelpers.ReportJUnit:
+		return r.writeJUnit(w)
+	default:
+		return r.writeJSON(w)
+	}
+}
+
+func (r Report) writeJSON(w io.Writer) error {
+	apps := r.Apps
+	if apps == nil {
+		apps = []ReportApp{}
+	}
+
+	contents, err := json.MarshalIndent(apps, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(contents, '\n'))
+	return err
+}
+
+func (r Report) writeCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"org", "space", "name", "guid", "source_runtime", "target_runtime", "result", "message", "duration_seconds"})
+
+	for _, app := range r.Apps {
+		writer.Write([]string{
+			app.Organization,
+			app.Space,
+			app.Name,
+			app.Guid,
+			app.Source,
+			app.Target,
+			app.Result,
+			app.Message,
+			strconv.FormatFloat(app.Duration, 'f', 3, 64),
+		})
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+	Type    string `xml:"type,attr,omitempty"`
+}
+
+// writeJUnit reports every app as a test case named after the app, grouped
+// by org and space. Apps that failed or were rolled back are failures, apps
+// that were not migrated for any other reason are skipped.
+func (r Report) writeJUnit(w io.Writer) error {
+	suite := junitTestSuite{
+		Name: fmt.Sprintf("migrate-apps to %s", r.Runtime),
+	}
+
+	total := 0.0
+	for _, app := range r.Apps {
+		testCase := junitTestCase{
+			ClassName: app.Organization + "." + app.Space,
+			Name:      app.Name,
+			Time:      strconv.FormatFloat(app.Duration, 'f', 3, 64),
+		}
+
+		switch app.Result {
+		case ResultError, ResultCrashed, ResultTimedOut, ResultRolledBack:
+			testCase.Failure = &junitMessage{Message: app.Message, Type: app.Result}
+			suite.Failures++
+		case ResultUnauthorized, ResultNotAttempted:
+			testCase.Skipped = &junitMessage{Message: app.Message, Type: app.Result}
+			suite.Skipped++
+		default:
+			testCase.SystemOut = app.Message
+		}
+
+		total += app.Duration
+		suite.Tests++
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+	suite.Time = strconv.FormatFloat(total, 'f', 3, 64)
+
+	contents, err := xml.MarshalIndent(suite, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, xml.Header+string(contents)+"\n")
+	return err
+}
@@ -0,0 +1,91 @@
+package migratehelpers_test
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/cloudfoundry-incubator/diego-enabler/commands/flaghelpers"
+	. "github.com/cloudfoundry-incubator/diego-enabler/commands/migratehelpers"
+	"github.com/cloudfoundry-incubator/diego-enabler/ui"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Report", func() {
+	var (
+		report Report
+		buf    *bytes.Buffer
+	)
+
+	BeforeEach(func() {
+		buf = new(bytes.Buffer)
+
+		report = Report{
+			Runtime: ui.Diego,
+			Apps: []ReportApp{
+				{
+					Organization: "some-org",
+					Space:        "some-space",
+					Name:         "good-app",
+					Guid:         "good-app-guid",
+					Source:       "DEA",
+					Target:       "Diego",
+					Result:       ResultSucceeded,
+					Duration:     1.5,
+				},
+				{
+					Organization: "some-org",
+					Space:        "some-space",
+					Name:         "bad-app",
+					Guid:         "bad-app-guid",
+					Source:       "DEA",
+					Target:       "Diego",
+					Result:       ResultCrashed,
+					Message:      "App crashed after migrating",
+					Duration:     2,
+				},
+				{
+					Organization: "some-org",
+					Space:        "other-space",
+					Name:         "late-app",
+					Guid:         "late-app-guid",
+					Source:       "DEA",
+					Target:       "Diego",
+					Result:       ResultNotAttempted,
+				},
+			},
+		}
+	})
+
+	It("writes JSON", func() {
+		Expect(report.Write(buf, flaghelpers.ReportJSON)).To(Succeed())
+
+		var apps []ReportApp
+		Expect(json.Unmarshal(buf.Bytes(), &apps)).To(Succeed())
+		Expect(apps).To(Equal(report.Apps))
+		Expect(buf.String()).To(ContainSubstring(`"duration_seconds": 1.5`))
+	})
+
+	It("writes CSV", func() {
+		Expect(report.Write(buf, flaghelpers.ReportCSV)).To(Succeed())
+
+		Expect(buf.String()).To(Equal(
+			"org,space,name,guid,source_runtime,target_runtime,result,message,duration_seconds\n" +
+				"some-org,some-space,good-app,good-app-guid,DEA,Diego,succeeded,,1.500\n" +
+				"some-org,some-space,bad-app,bad-app-guid,DEA,Diego,crashed,App crashed after migrating,2.000\n" +
+				"some-org,other-space,late-app,late-app-guid,DEA,Diego,not-attempted,,0.000\n",
+		))
+	})
+
+	It("writes JUnit XML", func() {
+		Expect(report.Write(buf, flaghelpers.ReportJUnit)).To(Succeed())
+
+		output := buf.String()
+		Expect(output).To(HavePrefix(`<?xml version="1.0" encoding="UTF-8"?>`))
+		Expect(output).To(ContainSubstring(`<testsuite name="migrate-apps to Diego" tests="3" failures="1" skipped="1" time="3.500">`))
+		Expect(output).To(ContainSubstring(`<testcase classname="some-org.some-space" name="good-app" time="1.500"></testcase>`))
+		Expect(output).To(ContainSubstring(`<failure message="App crashed after migrating" type="crashed"></failure>`))
+		Expect(output).To(ContainSubstring(`<skipped type="not-attempted"></skipped>`))
+	})
+})
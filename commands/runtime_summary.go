@@ -0,0 +1,33 @@
+package commands
+
+import (
+	"github.com/cloudfoundry-incubator/diego-enabler/commands/diegohelpers"
+	"github.com/cloudfoundry-incubator/diego-enabler/commands/flaghelpers"
+	"github.com/cloudfoundry-incubator/diego-enabler/commands/summaryhelpers"
+)
+
+type RuntimeSummaryCommand struct {
+	Organizations []string                `short:"o" value-name:"ORG" description:"Organization to restrict the summary to (can be repeated)"`
+	Spaces        []flaghelpers.SpaceFlag `short:"s" value-name:"SPACE" description:"Space to restrict the summary to, in the targeted organization or given as ORG/SPACE (can be repeated)"`
+}
+
+func (command RuntimeSummaryCommand) Execute([]string) error {
+	cliConnection := DiegoEnabler.CLIConnection
+
+	scope, err := diegohelpers.AppsScope{Organizations: command.Organizations, Spaces: command.Spaces}.Resolve(cliConnection)
+	if err != nil {
+		return err
+	}
+
+	appsGetter, err := diegohelpers.NewAllAppsGetterFunc(cliConnection, scope, diegohelpers.AppsFilter{}, false)
+	if err != nil {
+		return err
+	}
+
+	runtimeSummaryCommand, err := summaryhelpers.NewRuntimeSummaryCommand(cliConnection, scope.Scopes)
+	if err != nil {
+		return err
+	}
+
+	return summaryhelpers.RuntimeSummary(cliConnection, appsGetter, &runtimeSummaryCommand)
+}
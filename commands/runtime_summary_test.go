@@ -0,0 +1,62 @@
+package commands_test
+
+import (
+	. "github.com/cloudfoundry-incubator/diego-enabler/commands"
+	"github.com/cloudfoundry-incubator/diego-enabler/commands/diegohelpers"
+	"github.com/cloudfoundry-incubator/diego-enabler/commands/flaghelpers"
+	"github.com/cloudfoundry/cli/plugin/models"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RuntimeSummary", func() {
+	var (
+		command RuntimeSummaryCommand
+
+		err error
+	)
+
+	JustBeforeEach(func() {
+		err = command.Execute([]string{})
+	})
+
+	Context("when a space is not in the organization it is given with", func() {
+		BeforeEach(func() {
+			fakeConnection.GetOrgReturns(plugin_models.GetOrg_Model{
+				Guid:   "some-organization-guid",
+				Name:   "some-organization",
+				Spaces: []plugin_models.GetOrg_Space{{Guid: "other-space-guid", Name: "other-space"}},
+			}, nil)
+
+			command = RuntimeSummaryCommand{
+				Organizations: []string{"some-organization"},
+				Spaces:        []flaghelpers.SpaceFlag{{Organization: "some-organization", Space: "some-space"}},
+			}
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(Equal(diegohelpers.SpaceNotFoundErr{SpaceName: "some-organization/some-space"}))
+		})
+	})
+
+	Context("when one of several organizations cannot be found", func() {
+		BeforeEach(func() {
+			fakeConnection.GetOrgStub = func(name string) (plugin_models.GetOrg_Model, error) {
+				if name == "some-organization" {
+					return plugin_models.GetOrg_Model{Guid: "some-organization-guid", Name: name}, nil
+				}
+				return plugin_models.GetOrg_Model{}, nil
+			}
+
+			command = RuntimeSummaryCommand{
+				Organizations: []string{"some-organization", "other-organization"},
+			}
+		})
+
+		It("returns an error", func() {
+			Expect(fakeConnection.GetOrgCallCount()).To(Equal(2))
+			Expect(err).To(Equal(diegohelpers.OrgNotFoundErr{OrganizationName: "other-organization"}))
+		})
+	})
+})
@@ -0,0 +1,143 @@
+package summaryhelpers
+
+import (
+	"os"
+	"sort"
+
+	"github.com/cloudfoundry-incubator/diego-enabler/api"
+	"github.com/cloudfoundry-incubator/diego-enabler/commands/displayhelpers"
+	"github.com/cloudfoundry-incubator/diego-enabler/models"
+	"github.com/cloudfoundry-incubator/diego-enabler/thingdoer"
+	"github.com/cloudfoundry-incubator/diego-enabler/ui"
+	"github.com/cloudfoundry/cli/cf/terminal"
+	"github.com/cloudfoundry/cli/cf/trace"
+)
+
+func RuntimeSummary(cliConnection api.Connection, appsGetterFunc thingdoer.AppsGetterFunc, runtimeSummaryCommand *ui.RuntimeSummaryCommand) error {
+	runtimeSummaryCommand.BeforeAll()
+
+	apiClient, err := api.NewClient(cliConnection)
+	if err != nil {
+		return err
+	}
+
+	appRequestFactory := apiClient.HandleFiltersAndParameters(
+		apiClient.Authorize(apiClient.NewGetAppsRequest),
+	)
+	appPaginatedRequester, err := api.NewPaginatedRequester(cliConnection, appRequestFactory)
+	if err != nil {
+		return err
+	}
+
+	apps, err := appsGetterFunc(
+		models.ApplicationsParser{},
+		appPaginatedRequester,
+	)
+	if err != nil {
+		return err
+	}
+
+	spaceRequestFactory := apiClient.HandleFiltersAndParameters(
+		apiClient.Authorize(apiClient.NewGetSpacesRequest),
+	)
+	spacesPaginatedRequester, err := api.NewPaginatedRequester(cliConnection, spaceRequestFactory)
+	if err != nil {
+		return err
+	}
+
+	spaces, err := thingdoer.Spaces(
+		models.SpacesParser{},
+		spacesPaginatedRequester,
+	)
+	if err != nil {
+		return err
+	}
+
+	spaceMap := make(map[string]models.Space)
+	for _, space := range spaces {
+		spaceMap[space.Guid] = space
+	}
+
+	orgRows, spaceRows := Summarize(apps, spaceMap)
+	runtimeSummaryCommand.AfterAll(orgRows, spaceRows)
+
+	return nil
+}
+
+// Summarize adds up the apps per org and per space, both sorted by name.
+func Summarize(apps models.Applications, spaceMap map[string]models.Space) ([]ui.RuntimeSummaryRow, []ui.RuntimeSummaryRow) {
+	orgs := map[string]*ui.RuntimeSummaryRow{}
+	spaces := map[string]*ui.RuntimeSummaryRow{}
+
+	for _, app := range apps {
+		appPrinter := &displayhelpers.AppPrinter{
+			App:    app,
+			Spaces: spaceMap,
+		}
+
+		runtime := ui.DEA
+		if app.Diego {
+			runtime = ui.Diego
+		}
+
+		org, ok := orgs[appPrinter.OrganizationGuid()]
+		if !ok {
+			org = &ui.RuntimeSummaryRow{
+				Organization: appPrinter.Organization(),
+			}
+			orgs[appPrinter.OrganizationGuid()] = org
+		}
+		org.Add(runtime, app.InstanceCount, app.Memory)
+
+		space, ok := spaces[app.SpaceGuid]
+		if !ok {
+			space = &ui.RuntimeSummaryRow{
+				Organization: appPrinter.Organization(),
+				Space:        appPrinter.Space(),
+			}
+			spaces[app.SpaceGuid] = space
+		}
+		space.Add(runtime, app.InstanceCount, app.Memory)
+	}
+
+	return sortedRows(orgs), sortedRows(spaces)
+}
+
+func sortedRows(rows map[string]*ui.RuntimeSummaryRow) []ui.RuntimeSummaryRow {
+	var sorted []ui.RuntimeSummaryRow
+	for _, row := range rows {
+		sorted = append(sorted, *row)
+	}
+
+	sort.Sort(byName(sorted))
+	return sorted
+}
+
+type byName []ui.RuntimeSummaryRow
+
+func (rows byName) Len() int      { return len(rows) }
+func (rows byName) Swap(i, j int) { rows[i], rows[j] = rows[j], rows[i] }
+func (rows byName) Less(i, j int) bool {
+	if rows[i].Organization != rows[j].Organization {
+		return rows[i].Organization < rows[j].Organization
+	}
+	return rows[i].Space < rows[j].Space
+}
+
+func NewRuntimeSummaryCommand(cliConnection api.Connection, scopes []ui.Scope) (ui.RuntimeSummaryCommand, error) {
+	username, err := cliConnection.Username()
+	if err != nil {
+		return ui.RuntimeSummaryCommand{}, err
+	}
+
+	traceEnv := os.Getenv("CF_TRACE")
+	traceLogger := trace.NewLogger(false, traceEnv, "")
+	tUI := terminal.NewUI(os.Stdin, terminal.NewTeePrinter(), traceLogger)
+
+	cmd := ui.RuntimeSummaryCommand{
+		Username: username,
+		Scopes:   scopes,
+		UI:       tUI,
+	}
+	return cmd, nil
+}
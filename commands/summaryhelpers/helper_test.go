@@ -0,0 +1,83 @@
+package summaryhelpers_test
+
+import (
+	. "github.com/cloudfoundry-incubator/diego-enabler/commands/summaryhelpers"
+	"github.com/cloudfoundry-incubator/diego-enabler/models"
+	"github.com/cloudfoundry-incubator/diego-enabler/ui"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Summarize", func() {
+	newSpace := func(guid, name, orgGuid, orgName string) models.Space {
+		space := models.Space{}
+		space.Guid = guid
+		space.Name = name
+		space.OrganizationGuid = orgGuid
+		space.Organization.Guid = orgGuid
+		space.Organization.Name = orgName
+		return space
+	}
+
+	newApp := func(spaceGuid string, diego bool, instances int, memory int64) models.Application {
+		app := models.Application{}
+		app.SpaceGuid = spaceGuid
+		app.Diego = diego
+		app.InstanceCount = instances
+		app.Memory = memory
+		return app
+	}
+
+	It("adds up the apps on each runtime per org and per space", func() {
+		spaceMap := map[string]models.Space{
+			"space-a-guid": newSpace("space-a-guid", "space-a", "org-b-guid", "org-b"),
+			"space-b-guid": newSpace("space-b-guid", "space-b", "org-b-guid", "org-b"),
+			"space-c-guid": newSpace("space-c-guid", "space-c", "org-a-guid", "org-a"),
+		}
+
+		orgs, spaces := Summarize(models.Applications{
+			newApp("space-a-guid", true, 2, 512),
+			newApp("space-a-guid", false, 1, 256),
+			newApp("space-b-guid", true, 3, 1024),
+			newApp("space-c-guid", false, 4, 128),
+		}, spaceMap)
+
+		Expect(orgs).To(Equal([]ui.RuntimeSummaryRow{
+			{
+				Organization: "org-a",
+				DEA:          ui.RuntimeUsage{Apps: 1, Instances: 4, Memory: 512},
+			},
+			{
+				Organization: "org-b",
+				DEA:          ui.RuntimeUsage{Apps: 1, Instances: 1, Memory: 256},
+				Diego:        ui.RuntimeUsage{Apps: 2, Instances: 5, Memory: 4096},
+			},
+		}))
+
+		Expect(spaces).To(Equal([]ui.RuntimeSummaryRow{
+			{
+				Organization: "org-a",
+				Space:        "space-c",
+				DEA:          ui.RuntimeUsage{Apps: 1, Instances: 4, Memory: 512},
+			},
+			{
+				Organization: "org-b",
+				Space:        "space-a",
+				DEA:          ui.RuntimeUsage{Apps: 1, Instances: 1, Memory: 256},
+				Diego:        ui.RuntimeUsage{Apps: 1, Instances: 2, Memory: 1024},
+			},
+			{
+				Organization: "org-b",
+				Space:        "space-b",
+				Diego:        ui.RuntimeUsage{Apps: 1, Instances: 3, Memory: 3072},
+			},
+		}))
+	})
+
+	It("returns no rows when there are no apps", func() {
+		orgs, spaces := Summarize(nil, nil)
+		Expect(orgs).To(BeEmpty())
+		Expect(spaces).To(BeEmpty())
+	})
+})
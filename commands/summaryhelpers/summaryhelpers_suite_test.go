@@ -0,0 +1,13 @@
+package summaryhelpers_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestSummaryhelpers(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Summaryhelpers Suite")
+}
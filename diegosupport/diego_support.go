@@ -1,72 +1,79 @@
 package diegosupport
 
 import (
-	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
-	"strconv"
-	"strings"
 
+	"github.com/cloudfoundry-incubator/diego-enabler/api"
+	"github.com/cloudfoundry-incubator/diego-enabler/models"
 	"github.com/cloudfoundry/cli/cf/terminal"
 	"github.com/cloudfoundry/cli/plugin/models"
 )
 
 //go:generate counterfeiter . CliConnection
 type CliConnection interface {
-	CliCommandWithoutTerminalOutput(args ...string) ([]string, error)
-	GetApp(string) (plugin_models.GetAppModel, error)
 	GetCurrentSpace() (plugin_models.Space, error)
 	GetSpace(string) (plugin_models.GetSpace_Model, error)
 	Username() (string, error)
 }
 
-type DiegoSupport struct {
-	cli CliConnection
+//go:generate counterfeiter . CloudController
+type CloudController interface {
+	UpdateApp(appGUID string, update api.AppUpdate) (models.Application, error)
+	GetAppInstances(appGUID string) (map[string]api.AppInstance, error)
+	FindApp(spaceGUID string, appName string) (models.Application, error)
+	CountAppRoutes(appGUID string) (int, error)
 }
 
-type diegoError struct {
-	Code        int64  `json:"code,omitempty"`
-	Description string `json:"description,omitempty"`
-	ErrorCode   string `json:"error_code,omitempty"`
+type DiegoSupport struct {
+	cli CliConnection
+	cc  CloudController
 }
 
-func NewDiegoSupport(cli CliConnection) *DiegoSupport {
+func NewDiegoSupport(cli CliConnection, cc CloudController) *DiegoSupport {
 	return &DiegoSupport{
 		cli: cli,
+		cc:  cc,
 	}
 }
 
-func (d *DiegoSupport) SetDiegoFlag(appGuid string, enable bool) ([]string, error) {
-	output, err := d.cli.CliCommandWithoutTerminalOutput("curl", "/v2/apps/"+appGuid, "-X", "PUT", "-d", `{"diego":`+strconv.FormatBool(enable)+`}`)
+// FindApp looks up an app by name in the targeted space, the way the cf CLI
+// does for the app names it is given.
+func (d *DiegoSupport) FindApp(appName string) (models.Application, error) {
+	space, err := d.cli.GetCurrentSpace()
 	if err != nil {
-		return output, err
+		return models.Application{}, err
 	}
 
-	if err = checkDiegoError(strings.Join(output, "")); err != nil {
-		return output, err
-	}
+	return d.cc.FindApp(space.Guid, appName)
+}
 
-	return output, nil
+func (d *DiegoSupport) SetDiegoFlag(appGuid string, enable bool) error {
+	_, err := d.cc.UpdateApp(appGuid, api.AppUpdate{Diego: &enable})
+	return err
 }
 
-func checkDiegoError(jsonRsp string) error {
-	b := []byte(jsonRsp)
-	diegoErr := diegoError{}
-	err := json.Unmarshal(b, &diegoErr)
+const (
+	InstanceRunning = "RUNNING"
+	InstanceCrashed = "CRASHED"
+)
+
+func (d *DiegoSupport) InstanceStates(appGuid string) ([]string, error) {
+	instances, err := d.cc.GetAppInstances(appGuid)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if diegoErr.ErrorCode != "" || diegoErr.Code != 0 {
-		return errors.New(diegoErr.ErrorCode + " - " + diegoErr.Description)
+	var states []string
+	for _, instance := range instances {
+		states = append(states, instance.State)
 	}
 
-	return nil
+	return states, nil
 }
 
-func (d *DiegoSupport) WarnNoRoutes(appName string, output io.Writer) error {
-	hasRoutes, err := d.HasRoutes(appName)
+func (d *DiegoSupport) WarnNoRoutes(app models.Application, output io.Writer) error {
+	hasRoutes, err := d.HasRoutes(app.Guid)
 	if err != nil {
 		return err
 	}
@@ -96,7 +103,7 @@ func (d *DiegoSupport) WarnNoRoutes(appName string, output io.Writer) error {
 	fmt.Fprintf(
 		output,
 		"WARNING: Assuming health check of type process ('none') for app with no mapped routes. Use 'cf set-health-check' to change this. App %s to %s in space %s / org %s as %s\n",
-		terminal.EntityNameColor(appName),
+		terminal.EntityNameColor(app.Name),
 		terminal.EntityNameColor("Diego"),
 		terminal.EntityNameColor(space.Name),
 		terminal.EntityNameColor(space.Organization.Name),
@@ -106,11 +113,11 @@ func (d *DiegoSupport) WarnNoRoutes(appName string, output io.Writer) error {
 	return nil
 }
 
-func (d *DiegoSupport) HasRoutes(appName string) (bool, error) {
-	app, err := d.cli.GetApp(appName)
+func (d *DiegoSupport) HasRoutes(appGuid string) (bool, error) {
+	routeCount, err := d.cc.CountAppRoutes(appGuid)
 	if err != nil {
 		return false, err
 	}
 
-	return len(app.Routes) > 0, nil
+	return routeCount > 0, nil
 }
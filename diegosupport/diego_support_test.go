@@ -3,8 +3,10 @@ package diegosupport_test
 import (
 	"errors"
 
+	"github.com/cloudfoundry-incubator/diego-enabler/api"
 	"github.com/cloudfoundry-incubator/diego-enabler/diegosupport"
 	"github.com/cloudfoundry-incubator/diego-enabler/diegosupport/diegosupportfakes"
+	"github.com/cloudfoundry-incubator/diego-enabler/models"
 	"github.com/cloudfoundry/cli/plugin/models"
 
 	. "github.com/onsi/ginkgo"
@@ -14,84 +16,128 @@ import (
 
 var _ = Describe("DiegoSupport", func() {
 	var (
-		fakeCliConnection *diegosupportfakes.FakeCliConnection
-		diegoSupport      *diegosupport.DiegoSupport
+		fakeCliConnection   *diegosupportfakes.FakeCliConnection
+		fakeCloudController *diegosupportfakes.FakeCloudController
+		diegoSupport        *diegosupport.DiegoSupport
 	)
 
 	BeforeEach(func() {
 		fakeCliConnection = &diegosupportfakes.FakeCliConnection{}
-		fakeCliConnection.CliCommandWithoutTerminalOutputReturns([]string{""}, nil)
-		diegoSupport = diegosupport.NewDiegoSupport(fakeCliConnection)
+		fakeCloudController = &diegosupportfakes.FakeCloudController{}
+		diegoSupport = diegosupport.NewDiegoSupport(fakeCliConnection, fakeCloudController)
 	})
 
 	Describe("SetDiegoFlag", func() {
-		Context("when constructing the api call", func() {
-			It("invokes CliCommandWithoutTerminalOutput()", func() {
-				diegoSupport.SetDiegoFlag("123", false)
+		It("updates the diego flag of the app", func() {
+			err := diegoSupport.SetDiegoFlag("test-app-guid", true)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeCloudController.UpdateAppCallCount()).To(Equal(1))
+			appGuid, update := fakeCloudController.UpdateAppArgsForCall(0)
+			Expect(appGuid).To(Equal("test-app-guid"))
+			Expect(*update.Diego).To(BeTrue())
+		})
 
-				Expect(fakeCliConnection.CliCommandWithoutTerminalOutputCallCount()).To(Equal(1))
-			})
+		It("sets the flag to false", func() {
+			diegoSupport.SetDiegoFlag("test-app-guid", false)
 
-			It("calls cli core command 'curl'", func() {
-				diegoSupport.SetDiegoFlag("123", false)
+			_, update := fakeCloudController.UpdateAppArgsForCall(0)
+			Expect(*update.Diego).To(BeFalse())
+		})
 
-				Expect(fakeCliConnection.CliCommandWithoutTerminalOutputArgsForCall(0)[0]).To(Equal("curl"))
+		Context("when updating the app fails", func() {
+			BeforeEach(func() {
+				fakeCloudController.UpdateAppReturns(models.Application{}, errors.New("CF-NotAuthorized - You are not authorized"))
 			})
 
-			It("hits the /v2/apps endpoint", func() {
-				diegoSupport.SetDiegoFlag("test-app-guid", false)
-
-				Expect(fakeCliConnection.CliCommandWithoutTerminalOutputArgsForCall(0)[1]).To(Equal("/v2/apps/test-app-guid"))
+			It("returns the error", func() {
+				err := diegoSupport.SetDiegoFlag("test-app-guid", true)
+				Expect(err).To(MatchError("CF-NotAuthorized - You are not authorized"))
 			})
+		})
+	})
 
-			It("uses the 'PUT' method", func() {
-				diegoSupport.SetDiegoFlag("test-app-guid", false)
+	Describe("InstanceStates", func() {
+		It("gets the instances of the app", func() {
+			diegoSupport.InstanceStates("test-app-guid")
 
-				Expect(fakeCliConnection.CliCommandWithoutTerminalOutputArgsForCall(0)[2]).To(Equal("-X"))
-				Expect(fakeCliConnection.CliCommandWithoutTerminalOutputArgsForCall(0)[3]).To(Equal("PUT"))
-			})
+			Expect(fakeCloudController.GetAppInstancesCallCount()).To(Equal(1))
+			Expect(fakeCloudController.GetAppInstancesArgsForCall(0)).To(Equal("test-app-guid"))
+		})
 
-			It("includes http data in the body to set diego flag", func() {
-				diegoSupport.SetDiegoFlag("test-app-guid", true)
+		Context("when the API returns the instances", func() {
+			BeforeEach(func() {
+				fakeCloudController.GetAppInstancesReturns(map[string]api.AppInstance{
+					"0": {State: "RUNNING"},
+					"1": {State: "CRASHED"},
+				}, nil)
+			})
 
-				Expect(fakeCliConnection.CliCommandWithoutTerminalOutputArgsForCall(0)[4]).To(Equal("-d"))
-				Expect(fakeCliConnection.CliCommandWithoutTerminalOutputArgsForCall(0)[5]).To(Equal(`{"diego":true}`))
+			It("returns the state of every instance", func() {
+				states, err := diegoSupport.InstanceStates("test-app-guid")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(states).To(ConsistOf("RUNNING", "CRASHED"))
 			})
 		})
 
-		Context("when we do not encounter an error from the API call", func() {
-			It("is able to set the diego flag", func() {
-				apiOutput := []string{"{", `"key":"value"`, "}"}
-				fakeCliConnection.CliCommandWithoutTerminalOutputReturns(apiOutput, nil)
+		Context("when getting the instances fails", func() {
+			BeforeEach(func() {
+				fakeCloudController.GetAppInstancesReturns(nil, errors.New("CF-NotStaged - App has not finished staging"))
+			})
 
-				output, err := diegoSupport.SetDiegoFlag("test-app-guid", false)
-				Expect(output).To(Equal(apiOutput))
-				Expect(err).NotTo(HaveOccurred())
+			It("returns the error", func() {
+				_, err := diegoSupport.InstanceStates("test-app-guid")
+				Expect(err).To(MatchError("CF-NotStaged - App has not finished staging"))
 			})
 		})
+	})
 
-		Context("when we encounter an error from the API call", func() {
-			It("returns the output and the error from 'curl'", func() {
-				fakeCliConnection.CliCommandWithoutTerminalOutputReturns([]string{"This is the fake output from curl", "some other content"}, errors.New("error from curl"))
+	Describe("FindApp", func() {
+		BeforeEach(func() {
+			fakeCliConnection.GetCurrentSpaceReturns(plugin_models.Space{
+				SpaceFields: plugin_models.SpaceFields{
+					Guid: "some-space-guid",
+					Name: "some-space",
+				},
+			}, nil)
+			fakeCloudController.FindAppReturns(models.Application{
+				ApplicationEntity:   models.ApplicationEntity{Name: "some-app", Diego: true},
+				ApplicationMetadata: models.ApplicationMetadata{Guid: "some-app-guid"},
+			}, nil)
+		})
 
-				output, err := diegoSupport.SetDiegoFlag("test-app-guid", false)
-				Expect(output[0]).To(Equal("This is the fake output from curl"))
-				Expect(output[1]).To(Equal("some other content"))
-				Expect(err).To(HaveOccurred())
-				Expect(err.Error()).To(Equal("error from curl"))
+		It("looks up the app in the targeted space", func() {
+			app, err := diegoSupport.FindApp("some-app")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(app.Guid).To(Equal("some-app-guid"))
+			Expect(app.Diego).To(BeTrue())
+
+			Expect(fakeCloudController.FindAppCallCount()).To(Equal(1))
+			spaceGUID, appName := fakeCloudController.FindAppArgsForCall(0)
+			Expect(spaceGUID).To(Equal("some-space-guid"))
+			Expect(appName).To(Equal("some-app"))
+		})
+
+		Context("when getting the targeted space fails", func() {
+			BeforeEach(func() {
+				fakeCliConnection.GetCurrentSpaceReturns(plugin_models.Space{}, errors.New("some-error"))
 			})
 
-			It("parses the output and returns any diego specific error", func() {
-				response := []string{`{"code": 10000,	"description": "diego not supported",	"error_code": "12345"}`}
+			It("returns the error", func() {
+				_, err := diegoSupport.FindApp("some-app")
+				Expect(err).To(MatchError("some-error"))
+				Expect(fakeCloudController.FindAppCallCount()).To(BeZero())
+			})
+		})
 
-				fakeCliConnection.CliCommandWithoutTerminalOutputReturns(response, nil)
+		Context("when the app is not found", func() {
+			BeforeEach(func() {
+				fakeCloudController.FindAppReturns(models.Application{}, api.AppNotFoundError{Name: "some-app"})
+			})
 
-				output, err := diegoSupport.SetDiegoFlag("test-app-guid", false)
-				Expect(output[0]).To(ContainSubstring(`"code": 10000`))
-				Expect(output[0]).To(ContainSubstring("diego not supported"))
-				Expect(output[0]).To(ContainSubstring("12345"))
-				Expect(err).To(HaveOccurred())
-				Expect(err.Error()).To(Equal("12345 - diego not supported"))
+			It("returns the error", func() {
+				_, err := diegoSupport.FindApp("some-app")
+				Expect(err).To(MatchError("App some-app not found"))
 			})
 		})
 	})
@@ -99,30 +145,26 @@ var _ = Describe("DiegoSupport", func() {
 	Describe("HasRoutes", func() {
 		Context("when the app has no routes", func() {
 			BeforeEach(func() {
-				fakeCliConnection.GetAppReturns(plugin_models.GetAppModel{}, nil)
+				fakeCloudController.CountAppRoutesReturns(0, nil)
 			})
 
 			It("returns false and no error", func() {
-				hasRoutes, err := diegoSupport.HasRoutes("some-app")
+				hasRoutes, err := diegoSupport.HasRoutes("some-app-guid")
 				Expect(hasRoutes).To(BeFalse())
 				Expect(err).ToNot(HaveOccurred())
 
-				Expect(fakeCliConnection.GetAppCallCount()).To(Equal(1))
-				Expect(fakeCliConnection.GetAppArgsForCall(0)).To(Equal("some-app"))
+				Expect(fakeCloudController.CountAppRoutesCallCount()).To(Equal(1))
+				Expect(fakeCloudController.CountAppRoutesArgsForCall(0)).To(Equal("some-app-guid"))
 			})
 		})
 
 		Context("when the app has routes", func() {
 			BeforeEach(func() {
-				fakeCliConnection.GetAppReturns(plugin_models.GetAppModel{
-					Routes: []plugin_models.GetApp_RouteSummary{
-						{},
-					},
-				}, nil)
+				fakeCloudController.CountAppRoutesReturns(2, nil)
 			})
 
 			It("returns true and no error", func() {
-				hasRoutes, err := diegoSupport.HasRoutes("some-app")
+				hasRoutes, err := diegoSupport.HasRoutes("some-app-guid")
 				Expect(hasRoutes).To(BeTrue())
 				Expect(err).ToNot(HaveOccurred())
 			})
@@ -133,11 +175,11 @@ var _ = Describe("DiegoSupport", func() {
 
 			BeforeEach(func() {
 				expectedError = errors.New("some-error")
-				fakeCliConnection.GetAppReturns(plugin_models.GetAppModel{}, expectedError)
+				fakeCloudController.CountAppRoutesReturns(0, expectedError)
 			})
 
 			It("returns the error", func() {
-				_, err := diegoSupport.HasRoutes("some-app")
+				_, err := diegoSupport.HasRoutes("some-app-guid")
 				Expect(err).To(MatchError(expectedError))
 			})
 		})
@@ -146,15 +188,20 @@ var _ = Describe("DiegoSupport", func() {
 	Describe("WarnNoRoutes", func() {
 		var (
 			output *gbytes.Buffer
+			app    models.Application
 		)
 
 		BeforeEach(func() {
 			output = gbytes.NewBuffer()
+			app = models.Application{
+				ApplicationEntity:   models.ApplicationEntity{Name: "some-app"},
+				ApplicationMetadata: models.ApplicationMetadata{Guid: "some-app-guid"},
+			}
 		})
 
 		Context("when the app has no routes", func() {
 			BeforeEach(func() {
-				fakeCliConnection.GetAppReturns(plugin_models.GetAppModel{}, nil)
+				fakeCloudController.CountAppRoutesReturns(0, nil)
 				fakeCliConnection.GetCurrentSpaceReturns(plugin_models.Space{
 					SpaceFields: plugin_models.SpaceFields{
 						Name: "some-space",
@@ -173,11 +220,11 @@ var _ = Describe("DiegoSupport", func() {
 			})
 
 			It("writes a warning to the output", func() {
-				err := diegoSupport.WarnNoRoutes("some-app", output)
+				err := diegoSupport.WarnNoRoutes(app, output)
 				Expect(err).ToNot(HaveOccurred())
 
-				Expect(fakeCliConnection.GetAppCallCount()).To(Equal(1))
-				Expect(fakeCliConnection.GetAppArgsForCall(0)).To(Equal("some-app"))
+				Expect(fakeCloudController.CountAppRoutesCallCount()).To(Equal(1))
+				Expect(fakeCloudController.CountAppRoutesArgsForCall(0)).To(Equal("some-app-guid"))
 
 				Expect(fakeCliConnection.GetCurrentSpaceCallCount()).To(Equal(1))
 
@@ -192,31 +239,27 @@ var _ = Describe("DiegoSupport", func() {
 
 		Context("when the app has routes", func() {
 			BeforeEach(func() {
-				fakeCliConnection.GetAppReturns(plugin_models.GetAppModel{
-					Routes: []plugin_models.GetApp_RouteSummary{
-						{},
-					},
-				}, nil)
+				fakeCloudController.CountAppRoutesReturns(1, nil)
 			})
 
 			It("does not write a warning to the output", func() {
-				err := diegoSupport.WarnNoRoutes("some-app", output)
+				err := diegoSupport.WarnNoRoutes(app, output)
 				Expect(err).ToNot(HaveOccurred())
 
 				Expect(output.Contents()).To(BeEmpty())
 			})
 		})
 
-		Context("when getting the app returns an error", func() {
+		Context("when counting the routes returns an error", func() {
 			var expectedError error
 
 			BeforeEach(func() {
 				expectedError = errors.New("some-error")
-				fakeCliConnection.GetAppReturns(plugin_models.GetAppModel{}, expectedError)
+				fakeCloudController.CountAppRoutesReturns(0, expectedError)
 			})
 
 			It("returns the error", func() {
-				err := diegoSupport.WarnNoRoutes("some-app", output)
+				err := diegoSupport.WarnNoRoutes(app, output)
 				Expect(err).To(MatchError(expectedError))
 			})
 		})
@@ -226,12 +269,12 @@ var _ = Describe("DiegoSupport", func() {
 
 			BeforeEach(func() {
 				expectedError = errors.New("some-error")
-				fakeCliConnection.GetAppReturns(plugin_models.GetAppModel{}, nil)
+				fakeCloudController.CountAppRoutesReturns(0, nil)
 				fakeCliConnection.GetCurrentSpaceReturns(plugin_models.Space{}, expectedError)
 			})
 
 			It("returns the error", func() {
-				err := diegoSupport.WarnNoRoutes("some-app", output)
+				err := diegoSupport.WarnNoRoutes(app, output)
 				Expect(err).To(MatchError(expectedError))
 			})
 		})
@@ -241,7 +284,7 @@ var _ = Describe("DiegoSupport", func() {
 
 			BeforeEach(func() {
 				expectedError = errors.New("some-error")
-				fakeCliConnection.GetAppReturns(plugin_models.GetAppModel{}, nil)
+				fakeCloudController.CountAppRoutesReturns(0, nil)
 				fakeCliConnection.GetCurrentSpaceReturns(plugin_models.Space{
 					SpaceFields: plugin_models.SpaceFields{
 						Name: "some-space",
@@ -251,7 +294,7 @@ var _ = Describe("DiegoSupport", func() {
 			})
 
 			It("returns the error", func() {
-				err := diegoSupport.WarnNoRoutes("some-app", output)
+				err := diegoSupport.WarnNoRoutes(app, output)
 				Expect(err).To(MatchError(expectedError))
 			})
 		})
@@ -260,7 +303,7 @@ var _ = Describe("DiegoSupport", func() {
 			var expectedError error
 			BeforeEach(func() {
 				expectedError = errors.New("some-error")
-				fakeCliConnection.GetAppReturns(plugin_models.GetAppModel{}, nil)
+				fakeCloudController.CountAppRoutesReturns(0, nil)
 				fakeCliConnection.GetCurrentSpaceReturns(plugin_models.Space{
 					SpaceFields: plugin_models.SpaceFields{
 						Name: "some-space",
@@ -279,7 +322,7 @@ var _ = Describe("DiegoSupport", func() {
 			})
 
 			It("returns the error", func() {
-				err := diegoSupport.WarnNoRoutes("some-app", output)
+				err := diegoSupport.WarnNoRoutes(app, output)
 				Expect(err).To(MatchError(expectedError))
 			})
 		})
@@ -9,24 +9,6 @@ import (
 )
 
 type FakeCliConnection struct {
-	CliCommandWithoutTerminalOutputStub        func(args ...string) ([]string, error)
-	cliCommandWithoutTerminalOutputMutex       sync.RWMutex
-	cliCommandWithoutTerminalOutputArgsForCall []struct {
-		args []string
-	}
-	cliCommandWithoutTerminalOutputReturns struct {
-		result1 []string
-		result2 error
-	}
-	GetAppStub        func(string) (models.GetAppModel, error)
-	getAppMutex       sync.RWMutex
-	getAppArgsForCall []struct {
-		arg1 string
-	}
-	getAppReturns struct {
-		result1 models.GetAppModel
-		result2 error
-	}
 	GetCurrentSpaceStub        func() (models.Space, error)
 	getCurrentSpaceMutex       sync.RWMutex
 	getCurrentSpaceArgsForCall []struct{}
@@ -54,74 +36,6 @@ type FakeCliConnection struct {
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeCliConnection) CliCommandWithoutTerminalOutput(args ...string) ([]string, error) {
-	fake.cliCommandWithoutTerminalOutputMutex.Lock()
-	fake.cliCommandWithoutTerminalOutputArgsForCall = append(fake.cliCommandWithoutTerminalOutputArgsForCall, struct {
-		args []string
-	}{args})
-	fake.recordInvocation("CliCommandWithoutTerminalOutput", []interface{}{args})
-	fake.cliCommandWithoutTerminalOutputMutex.Unlock()
-	if fake.CliCommandWithoutTerminalOutputStub != nil {
-		return fake.CliCommandWithoutTerminalOutputStub(args...)
-	} else {
-		return fake.cliCommandWithoutTerminalOutputReturns.result1, fake.cliCommandWithoutTerminalOutputReturns.result2
-	}
-}
-
-func (fake *FakeCliConnection) CliCommandWithoutTerminalOutputCallCount() int {
-	fake.cliCommandWithoutTerminalOutputMutex.RLock()
-	defer fake.cliCommandWithoutTerminalOutputMutex.RUnlock()
-	return len(fake.cliCommandWithoutTerminalOutputArgsForCall)
-}
-
-func (fake *FakeCliConnection) CliCommandWithoutTerminalOutputArgsForCall(i int) []string {
-	fake.cliCommandWithoutTerminalOutputMutex.RLock()
-	defer fake.cliCommandWithoutTerminalOutputMutex.RUnlock()
-	return fake.cliCommandWithoutTerminalOutputArgsForCall[i].args
-}
-
-func (fake *FakeCliConnection) CliCommandWithoutTerminalOutputReturns(result1 []string, result2 error) {
-	fake.CliCommandWithoutTerminalOutputStub = nil
-	fake.cliCommandWithoutTerminalOutputReturns = struct {
-		result1 []string
-		result2 error
-	}{result1, result2}
-}
-
-func (fake *FakeCliConnection) GetApp(arg1 string) (models.GetAppModel, error) {
-	fake.getAppMutex.Lock()
-	fake.getAppArgsForCall = append(fake.getAppArgsForCall, struct {
-		arg1 string
-	}{arg1})
-	fake.recordInvocation("GetApp", []interface{}{arg1})
-	fake.getAppMutex.Unlock()
-	if fake.GetAppStub != nil {
-		return fake.GetAppStub(arg1)
-	} else {
-		return fake.getAppReturns.result1, fake.getAppReturns.result2
-	}
-}
-
-func (fake *FakeCliConnection) GetAppCallCount() int {
-	fake.getAppMutex.RLock()
-	defer fake.getAppMutex.RUnlock()
-	return len(fake.getAppArgsForCall)
-}
-
-func (fake *FakeCliConnection) GetAppArgsForCall(i int) string {
-	fake.getAppMutex.RLock()
-	defer fake.getAppMutex.RUnlock()
-	return fake.getAppArgsForCall[i].arg1
-}
-
-func (fake *FakeCliConnection) GetAppReturns(result1 models.GetAppModel, result2 error) {
-	fake.GetAppStub = nil
-	fake.getAppReturns = struct {
-		result1 models.GetAppModel
-		result2 error
-	}{result1, result2}
-}
-
 func (fake *FakeCliConnection) GetCurrentSpace() (models.Space, error) {
 	fake.getCurrentSpaceMutex.Lock()
 	fake.getCurrentSpaceArgsForCall = append(fake.getCurrentSpaceArgsForCall, struct{}{})
@@ -211,10 +125,6 @@ func (fake *FakeCliConnection) UsernameReturns(result1 string, result2 error) {
 func (fake *FakeCliConnection) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
-	fake.cliCommandWithoutTerminalOutputMutex.RLock()
-	defer fake.cliCommandWithoutTerminalOutputMutex.RUnlock()
-	fake.getAppMutex.RLock()
-	defer fake.getAppMutex.RUnlock()
 	fake.getCurrentSpaceMutex.RLock()
 	defer fake.getCurrentSpaceMutex.RUnlock()
 	fake.getSpaceMutex.RLock()
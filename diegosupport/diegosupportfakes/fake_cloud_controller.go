@@ -0,0 +1,219 @@
+// This file was generated by counterfeiter
+package diegosupportfakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry-incubator/diego-enabler/api"
+	"github.com/cloudfoundry-incubator/diego-enabler/diegosupport"
+	"github.com/cloudfoundry-incubator/diego-enabler/models"
+)
+
+type FakeCloudController struct {
+	UpdateAppStub        func(appGUID string, update api.AppUpdate) (models.Application, error)
+	updateAppMutex       sync.RWMutex
+	updateAppArgsForCall []struct {
+		appGUID string
+		update  api.AppUpdate
+	}
+	updateAppReturns struct {
+		result1 models.Application
+		result2 error
+	}
+	GetAppInstancesStub        func(appGUID string) (map[string]api.AppInstance, error)
+	getAppInstancesMutex       sync.RWMutex
+	getAppInstancesArgsForCall []struct {
+		appGUID string
+	}
+	getAppInstancesReturns struct {
+		result1 map[string]api.AppInstance
+		result2 error
+	}
+	FindAppStub        func(spaceGUID string, appName string) (models.Application, error)
+	findAppMutex       sync.RWMutex
+	findAppArgsForCall []struct {
+		spaceGUID string
+		appName   string
+	}
+	findAppReturns struct {
+		result1 models.Application
+		result2 error
+	}
+	CountAppRoutesStub        func(appGUID string) (int, error)
+	countAppRoutesMutex       sync.RWMutex
+	countAppRoutesArgsForCall []struct {
+		appGUID string
+	}
+	countAppRoutesReturns struct {
+		result1 int
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeCloudController) UpdateApp(appGUID string, update api.AppUpdate) (models.Application, error) {
+	fake.updateAppMutex.Lock()
+	fake.updateAppArgsForCall = append(fake.updateAppArgsForCall, struct {
+		appGUID string
+		update  api.AppUpdate
+	}{appGUID, update})
+	fake.recordInvocation("UpdateApp", []interface{}{appGUID, update})
+	fake.updateAppMutex.Unlock()
+	if fake.UpdateAppStub != nil {
+		return fake.UpdateAppStub(appGUID, update)
+	} else {
+		return fake.updateAppReturns.result1, fake.updateAppReturns.result2
+	}
+}
+
+func (fake *FakeCloudController) UpdateAppCallCount() int {
+	fake.updateAppMutex.RLock()
+	defer fake.updateAppMutex.RUnlock()
+	return len(fake.updateAppArgsForCall)
+}
+
+func (fake *FakeCloudController) UpdateAppArgsForCall(i int) (string, api.AppUpdate) {
+	fake.updateAppMutex.RLock()
+	defer fake.updateAppMutex.RUnlock()
+	return fake.updateAppArgsForCall[i].appGUID, fake.updateAppArgsForCall[i].update
+}
+
+func (fake *FakeCloudController) UpdateAppReturns(result1 models.Application, result2 error) {
+	fake.UpdateAppStub = nil
+	fake.updateAppReturns = struct {
+		result1 models.Application
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCloudController) GetAppInstances(appGUID string) (map[string]api.AppInstance, error) {
+	fake.getAppInstancesMutex.Lock()
+	fake.getAppInstancesArgsForCall = append(fake.getAppInstancesArgsForCall, struct {
+		appGUID string
+	}{appGUID})
+	fake.recordInvocation("GetAppInstances", []interface{}{appGUID})
+	fake.getAppInstancesMutex.Unlock()
+	if fake.GetAppInstancesStub != nil {
+		return fake.GetAppInstancesStub(appGUID)
+	} else {
+		return fake.getAppInstancesReturns.result1, fake.getAppInstancesReturns.result2
+	}
+}
+
+func (fake *FakeCloudController) GetAppInstancesCallCount() int {
+	fake.getAppInstancesMutex.RLock()
+	defer fake.getAppInstancesMutex.RUnlock()
+	return len(fake.getAppInstancesArgsForCall)
+}
+
+func (fake *FakeCloudController) GetAppInstancesArgsForCall(i int) string {
+	fake.getAppInstancesMutex.RLock()
+	defer fake.getAppInstancesMutex.RUnlock()
+	return fake.getAppInstancesArgsForCall[i].appGUID
+}
+
+func (fake *FakeCloudController) GetAppInstancesReturns(result1 map[string]api.AppInstance, result2 error) {
+	fake.GetAppInstancesStub = nil
+	fake.getAppInstancesReturns = struct {
+		result1 map[string]api.AppInstance
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCloudController) FindApp(spaceGUID string, appName string) (models.Application, error) {
+	fake.findAppMutex.Lock()
+	fake.findAppArgsForCall = append(fake.findAppArgsForCall, struct {
+		spaceGUID string
+		appName   string
+	}{spaceGUID, appName})
+	fake.recordInvocation("FindApp", []interface{}{spaceGUID, appName})
+	fake.findAppMutex.Unlock()
+	if fake.FindAppStub != nil {
+		return fake.FindAppStub(spaceGUID, appName)
+	} else {
+		return fake.findAppReturns.result1, fake.findAppReturns.result2
+	}
+}
+
+func (fake *FakeCloudController) FindAppCallCount() int {
+	fake.findAppMutex.RLock()
+	defer fake.findAppMutex.RUnlock()
+	return len(fake.findAppArgsForCall)
+}
+
+func (fake *FakeCloudController) FindAppArgsForCall(i int) (string, string) {
+	fake.findAppMutex.RLock()
+	defer fake.findAppMutex.RUnlock()
+	return fake.findAppArgsForCall[i].spaceGUID, fake.findAppArgsForCall[i].appName
+}
+
+func (fake *FakeCloudController) FindAppReturns(result1 models.Application, result2 error) {
+	fake.FindAppStub = nil
+	fake.findAppReturns = struct {
+		result1 models.Application
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCloudController) CountAppRoutes(appGUID string) (int, error) {
+	fake.countAppRoutesMutex.Lock()
+	fake.countAppRoutesArgsForCall = append(fake.countAppRoutesArgsForCall, struct {
+		appGUID string
+	}{appGUID})
+	fake.recordInvocation("CountAppRoutes", []interface{}{appGUID})
+	fake.countAppRoutesMutex.Unlock()
+	if fake.CountAppRoutesStub != nil {
+		return fake.CountAppRoutesStub(appGUID)
+	} else {
+		return fake.countAppRoutesReturns.result1, fake.countAppRoutesReturns.result2
+	}
+}
+
+func (fake *FakeCloudController) CountAppRoutesCallCount() int {
+	fake.countAppRoutesMutex.RLock()
+	defer fake.countAppRoutesMutex.RUnlock()
+	return len(fake.countAppRoutesArgsForCall)
+}
+
+func (fake *FakeCloudController) CountAppRoutesArgsForCall(i int) string {
+	fake.countAppRoutesMutex.RLock()
+	defer fake.countAppRoutesMutex.RUnlock()
+	return fake.countAppRoutesArgsForCall[i].appGUID
+}
+
+func (fake *FakeCloudController) CountAppRoutesReturns(result1 int, result2 error) {
+	fake.CountAppRoutesStub = nil
+	fake.countAppRoutesReturns = struct {
+		result1 int
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCloudController) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.updateAppMutex.RLock()
+	defer fake.updateAppMutex.RUnlock()
+	fake.getAppInstancesMutex.RLock()
+	defer fake.getAppInstancesMutex.RUnlock()
+	fake.findAppMutex.RLock()
+	defer fake.findAppMutex.RUnlock()
+	fake.countAppRoutesMutex.RLock()
+	defer fake.countAppRoutesMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeCloudController) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ diegosupport.CloudController = new(FakeCloudController)
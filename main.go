@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/cloudfoundry-incubator/diego-enabler/commands"
 	"github.com/cloudfoundry-incubator/diego-enabler/ui"
@@ -12,6 +13,20 @@ import (
 
 type DiegoEnabler struct{}
 
+// The environment variables the commands read, each defined once for the
+// ENVIRONMENT section of every usage that lists it. The README describes them
+// in full.
+const (
+	retryMaxAttemptsHelp = "   CF_RETRY_MAX_ATTEMPTS      Attempts to make at a Cloud Controller request that fails with a transient error (Default: 5)"
+	resultsPerPageHelp   = "   CF_RESULTS_PER_PAGE        Results to fetch per page of a Cloud Controller listing, at most 100 (Default: decided by the Cloud Controller)"
+	startupTimeoutHelp   = "   CF_STARTUP_TIMEOUT         Minutes to wait for a migrated app's instances to start before reporting it timed out (Default: 5)"
+)
+
+// environmentHelp is the ENVIRONMENT section of a usage listing variables.
+func environmentHelp(variables ...string) string {
+	return "\n\nENVIRONMENT:\n" + strings.Join(variables, "\n")
+}
+
 func (c *DiegoEnabler) GetMetadata() plugin.PluginMetadata {
 	return plugin.PluginMetadata{
 		Name: "Diego-Enabler",
@@ -25,64 +40,135 @@ func (c *DiegoEnabler) GetMetadata() plugin.PluginMetadata {
 				Name:     "enable-diego",
 				HelpText: "Migrate app to the Diego runtime",
 				UsageDetails: plugin.Usage{
-					Usage: `cf enable-diego APP_NAME
+					Usage: `cf enable-diego APP_NAME [--rollback-on-failure] [--dry-run]
 
 WARNING:
-   Migration of a running app causes a restart. Stopped apps will be configured to run on the target runtime but are not started.`,
+   Migration of a running app causes a restart. Stopped apps will be configured to run on the target runtime but are not started.
+
+OPTIONS:
+   --rollback-on-failure      Migrate the app back to its original runtime if it fails to start
+   --dry-run                  Report what would change without changing the app` + environmentHelp(retryMaxAttemptsHelp, startupTimeoutHelp),
 				},
 			},
 			{
 				Name:     "disable-diego",
 				HelpText: "Migrate app to the DEA runtime",
 				UsageDetails: plugin.Usage{
-					Usage: `cf disable-diego APP_NAME
+					Usage: `cf disable-diego APP_NAME [--rollback-on-failure] [--dry-run]
 
 WARNING:
-   Migration of a running app causes a restart. Stopped apps will be configured to run on the target runtime but are not started.`,
+   Migration of a running app causes a restart. Stopped apps will be configured to run on the target runtime but are not started.
+
+OPTIONS:
+   --rollback-on-failure      Migrate the app back to its original runtime if it fails to start
+   --dry-run                  Report what would change without changing the app` + environmentHelp(retryMaxAttemptsHelp, startupTimeoutHelp),
 				},
 			},
 			{
 				Name:     "has-diego-enabled",
 				HelpText: "Report whether an app is configured to run on the Diego runtime",
 				UsageDetails: plugin.Usage{
-					Usage: "cf has-diego-enabled APP_NAME",
+					Usage: `cf has-diego-enabled APP_NAME` + environmentHelp(retryMaxAttemptsHelp),
 				},
 			},
 			{
 				Name:     "diego-apps",
 				HelpText: "Lists all apps running on the Diego runtime that are visible to the user",
 				UsageDetails: plugin.Usage{
-					Usage: `cf diego-apps [-o ORG | -s SPACE]
+					Usage: `cf diego-apps [-o ORG]... [-s SPACE]... [--state STATE] [--updated-since TIME] [--created-before TIME] [--include PATTERN]... [--exclude PATTERN]... [--output FORMAT] [--columns COLUMNS]
 
 OPTIONS:
-   -o      Organization to restrict the app migration to,
-   -s      Space in the targeted organization to limit results to`,
+   -o      Organization to restrict the app listing to (can be repeated)
+   -s      Space to restrict the app listing to, in the targeted organization or given as ORG/SPACE (can be repeated)
+   --state                    Only include apps in STATE (started or stopped)
+   --updated-since            Only include apps updated at or after TIME, a date or an RFC 3339 timestamp
+   --created-before           Only include apps created before TIME, a date or an RFC 3339 timestamp
+   --include                  Only include apps whose name matches PATTERN, a glob or a /regex/ (can be repeated)
+   --exclude                  Exclude apps whose name matches PATTERN, a glob or a /regex/ (can be repeated)
+   --output                   Output format: table, json, csv or yaml (Default: table)
+   --columns                  Also show COLUMNS, a comma separated list of state, instances, memory, disk, stack, buildpack, routes, health-check and package-state` + environmentHelp(retryMaxAttemptsHelp, resultsPerPageHelp),
 				},
 			},
 			{
 				Name:     "dea-apps",
 				HelpText: "Lists all apps running on the DEA runtime that are visible to the user",
 				UsageDetails: plugin.Usage{
-					Usage: `cf dea-apps [-o ORG | -s SPACE]
+					Usage: `cf dea-apps [-o ORG]... [-s SPACE]... [--state STATE] [--updated-since TIME] [--created-before TIME] [--include PATTERN]... [--exclude PATTERN]... [--output FORMAT] [--columns COLUMNS]
+
+OPTIONS:
+   -o      Organization to restrict the app listing to (can be repeated)
+   -s      Space to restrict the app listing to, in the targeted organization or given as ORG/SPACE (can be repeated)
+   --state                    Only include apps in STATE (started or stopped)
+   --updated-since            Only include apps updated at or after TIME, a date or an RFC 3339 timestamp
+   --created-before           Only include apps created before TIME, a date or an RFC 3339 timestamp
+   --include                  Only include apps whose name matches PATTERN, a glob or a /regex/ (can be repeated)
+   --exclude                  Exclude apps whose name matches PATTERN, a glob or a /regex/ (can be repeated)
+   --output                   Output format: table, json, csv or yaml (Default: table)
+   --columns                  Also show COLUMNS, a comma separated list of state, instances, memory, disk, stack, buildpack, routes, health-check and package-state` + environmentHelp(retryMaxAttemptsHelp, resultsPerPageHelp),
+				},
+			},
+			{
+				Name:     "apps-by-runtime",
+				HelpText: "Lists all apps visible to the user with the runtime each one runs on",
+				UsageDetails: plugin.Usage{
+					Usage: `cf apps-by-runtime [-o ORG]... [-s SPACE]... [--state STATE] [--updated-since TIME] [--created-before TIME] [--include PATTERN]... [--exclude PATTERN]... [--output FORMAT] [--columns COLUMNS] [--sort-by KEY] [--group-by KEY]
 
 OPTIONS:
-   -o      Organization to restrict the app migration to,
-   -s      Space in the targeted organization to limit results to`,
+   -o      Organization to restrict the app listing to (can be repeated)
+   -s      Space to restrict the app listing to, in the targeted organization or given as ORG/SPACE (can be repeated)
+   --state                    Only include apps in STATE (started or stopped)
+   --updated-since            Only include apps updated at or after TIME, a date or an RFC 3339 timestamp
+   --created-before           Only include apps created before TIME, a date or an RFC 3339 timestamp
+   --include                  Only include apps whose name matches PATTERN, a glob or a /regex/ (can be repeated)
+   --exclude                  Exclude apps whose name matches PATTERN, a glob or a /regex/ (can be repeated)
+   --output                   Output format: table, json, csv or yaml (Default: table)
+   --columns                  Also show COLUMNS, a comma separated list of state, instances, memory, disk, stack, buildpack, routes, health-check and package-state
+   --sort-by                  Sort apps by name, org, space or runtime (Default: name)
+   --group-by                 Group apps by org, space or runtime` + environmentHelp(retryMaxAttemptsHelp, resultsPerPageHelp),
 				},
 			},
 			{
 				Name:     "migrate-apps",
-				HelpText: "Migrate all apps to Diego/DEA",
+				HelpText: "Migrate the apps in the targeted space, or in the given orgs and spaces, to Diego/DEA",
 				UsageDetails: plugin.Usage{
-					Usage: `cf migrate-apps (diego | dea) [-o ORG | -s SPACE] [-p MAX_IN_FLIGHT]
+					Usage: `cf migrate-apps (diego | dea) [[-o ORG]... [-s SPACE]... | --all-orgs [-f]] [-p MAX_IN_FLIGHT] [--rollback-on-failure] [--journal FILE] [--dry-run]
+   [--write-plan FILE | --plan FILE [--skip-changed]] [--canary N] [--max-failures K]
+   [--state STATE] [--include PATTERN]... [--exclude PATTERN]... [--report FILE [--report-format FORMAT]]
 
 WARNING:
    Migration of a running app causes a restart. Stopped apps will be configured to run on the target runtime but are not started.
+   Without -o, -s or --all-orgs only the apps in the targeted space, or with --plan in the spaces of the planned apps, are migrated.
+
+OPTIONS:
+   -o      Organization to restrict the app migration to (can be repeated)
+   -s      Space to restrict the app migration to, in the targeted organization or given as ORG/SPACE (can be repeated)
+   -f      Force migration with --all-orgs without confirmation
+   -p      Maximum number of apps to migrate in parallel (Default: 1, maximum: 100)
+   --all-orgs                 Migrate the apps in all orgs instead of the targeted space, after confirming the number of apps
+   --rollback-on-failure      Migrate apps that fail to start back to their original runtime
+   --journal                  Record the progress of each app in FILE and skip apps that a previous run already migrated
+   --dry-run                  Report which apps would be migrated without changing them
+   --write-plan               Write the apps that would be migrated to FILE (JSON, or YAML for .yml/.yaml) without migrating them
+   --plan                     Migrate exactly the apps listed in a plan written by --write-plan
+   --skip-changed             Skip apps that changed since the plan was made instead of failing
+   --canary                   Migrate N apps (or N% of all apps) first, MAX_IN_FLIGHT at a time, and only continue if all of them succeed
+   --max-failures             Stop migrating new apps once K apps have failed (Default: 0, never stop)
+   --state                    Only migrate apps in STATE (started or stopped)
+   --include                  Only include apps whose name matches PATTERN, a glob or a /regex/ (can be repeated)
+   --exclude                  Exclude apps whose name matches PATTERN, a glob or a /regex/ (can be repeated)
+   --report                   Write the result of migrating each app to FILE
+   --report-format            Format of the report written by --report: json, csv or junit (Default: json)` + environmentHelp(retryMaxAttemptsHelp, resultsPerPageHelp, startupTimeoutHelp),
+				},
+			},
+			{
+				Name:     "runtime-summary",
+				HelpText: "Summarize the apps, instances and memory on each runtime per org and space",
+				UsageDetails: plugin.Usage{
+					Usage: `cf runtime-summary [-o ORG]... [-s SPACE]...
 
 OPTIONS:
-   -o      Organization to restrict the app migration to
-   -s      Space in the targeted organization to restrict the app migration to
-   -p      Maximum number of apps to migrate in parallel (Default: 1, maximum: 100)`,
+   -o      Organization to restrict the summary to (can be repeated)
+   -s      Space to restrict the summary to, in the targeted organization or given as ORG/SPACE (can be repeated)` + environmentHelp(retryMaxAttemptsHelp, resultsPerPageHelp),
 				},
 			},
 		},
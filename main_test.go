@@ -3,7 +3,11 @@ package main_test
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os/exec"
+	"sync"
 
 	"github.com/cloudfoundry/cli/plugin/models"
 	"github.com/cloudfoundry/cli/testhelpers/rpc_server"
@@ -21,10 +25,65 @@ var _ = Describe("DiegoEnabler", func() {
 			rpcHandlers *fake_rpc_handlers.FakeHandlers
 			ts          *test_rpc_server.TestServer
 			err         error
+
+			ccServer   *httptest.Server
+			ccMutex    sync.Mutex
+			appUpdates map[string]string
+			appUpdated = func(path string) (string, bool) {
+				ccMutex.Lock()
+				defer ccMutex.Unlock()
+				body, ok := appUpdates[path]
+				return body, ok
+			}
+
+			appListing string
+			appLookups int
+			listApp    = func(guid string, diego bool) {
+				appListing = fmt.Sprintf(`{"resources": [{"metadata": {"guid": %q}, "entity": {"name": "test-app", "diego": %t}}]}`, guid, diego)
+			}
+			appLookupCount = func() int {
+				ccMutex.Lock()
+				defer ccMutex.Unlock()
+				return appLookups
+			}
 		)
 
 		BeforeEach(func() {
 			rpcHandlers = &fake_rpc_handlers.FakeHandlers{}
+
+			appUpdates = map[string]string{}
+			appListing = `{"resources": []}`
+			appLookups = 0
+			ccServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == "GET" && r.URL.Path == "/v2/apps" {
+					ccMutex.Lock()
+					appLookups++
+					ccMutex.Unlock()
+					w.Write([]byte(appListing))
+					return
+				}
+				if r.Method == "PUT" {
+					body, _ := ioutil.ReadAll(r.Body)
+					ccMutex.Lock()
+					appUpdates[r.URL.Path] = string(body)
+					ccMutex.Unlock()
+					w.WriteHeader(http.StatusCreated)
+				}
+				w.Write([]byte("{}"))
+			}))
+
+			rpcHandlers.IsLoggedInStub = func(_ string, retVal *bool) error {
+				*retVal = true
+				return nil
+			}
+			rpcHandlers.ApiEndpointStub = func(_ string, retVal *string) error {
+				*retVal = ccServer.URL
+				return nil
+			}
+			rpcHandlers.AccessTokenStub = func(_ string, retVal *string) error {
+				*retVal = "bearer some-token"
+				return nil
+			}
 		})
 
 		JustBeforeEach(func() {
@@ -50,6 +109,7 @@ var _ = Describe("DiegoEnabler", func() {
 
 		AfterEach(func() {
 			ts.Stop()
+			ccServer.Close()
 		})
 
 		Context("enable-diego", func() {
@@ -70,27 +130,22 @@ var _ = Describe("DiegoEnabler", func() {
 
 			Context("when the args are properly provided", func() {
 				BeforeEach(func() {
-					rpcHandlers.GetAppStub = func(_ string, retVal *plugin_models.GetAppModel) error {
-						*retVal = plugin_models.GetAppModel{}
-						return nil
-					}
+					listApp("test-app-guid", false)
 				})
 
-				It("calls GetApp() twice, one to get app guid, another to verify flag is set", func() {
+				It("looks up the app twice, once to get its guid and once to verify the flag is set", func() {
 					session, err := gexec.Start(exec.Command(validPluginPath, args...), GinkgoWriter, GinkgoWriter)
 					Expect(err).NotTo(HaveOccurred())
 
 					session.Wait()
-					Expect(rpcHandlers.GetAppCallCount()).To(Equal(3))
+					Expect(appLookupCount()).To(Equal(2))
+					Expect(rpcHandlers.GetAppCallCount()).To(BeZero())
 				})
 			})
 
 			Context("when the app is found", func() {
 				BeforeEach(func() {
-					rpcHandlers.GetAppStub = func(_ string, retVal *plugin_models.GetAppModel) error {
-						*retVal = plugin_models.GetAppModel{Guid: "test-app-guid"}
-						return nil
-					}
+					listApp("test-app-guid", false)
 				})
 
 				It("sets diego flag with /v2/apps endpoint", func() {
@@ -98,39 +153,45 @@ var _ = Describe("DiegoEnabler", func() {
 					Expect(err).NotTo(HaveOccurred())
 
 					session.Wait()
-					Expect(rpcHandlers.CallCoreCommandCallCount()).To(Equal(1))
-
-					output, _ := rpcHandlers.CallCoreCommandArgsForCall(0)
-					Expect(output[1]).To(ContainSubstring("v2/apps/test-app-guid"))
-					Expect(output[5]).To(ContainSubstring(`"diego":true`))
+					body, ok := appUpdated("/v2/apps/test-app-guid")
+					Expect(ok).To(BeTrue())
+					Expect(body).To(MatchJSON(`{"diego":true}`))
 				})
 			})
 
 			Context("when the app is not found", func() {
+				It("exits with error", func() {
+					session, err := gexec.Start(exec.Command(validPluginPath, args...), GinkgoWriter, GinkgoWriter)
+					Expect(err).NotTo(HaveOccurred())
+
+					session.Wait()
+
+					Expect(session).To(gbytes.Say("App test-app not found"))
+					Expect(session.ExitCode()).To(Equal(1))
+				})
+			})
+
+			Context("when getting the targeted space fails", func() {
 				BeforeEach(func() {
-					rpcHandlers.GetAppStub = func(_ string, retVal *plugin_models.GetAppModel) error {
-						*retVal = plugin_models.GetAppModel{Guid: "test-app-guid"}
-						return errors.New("error in GetApp")
+					rpcHandlers.GetCurrentSpaceStub = func(_ string, retVal *plugin_models.Space) error {
+						return errors.New("error in GetCurrentSpace")
 					}
 				})
 
-				It("exits with error when GetApp() returns error", func() {
+				It("exits with error", func() {
 					session, err := gexec.Start(exec.Command(validPluginPath, args...), GinkgoWriter, GinkgoWriter)
 					Expect(err).NotTo(HaveOccurred())
 
 					session.Wait()
 
-					Expect(session).To(gbytes.Say("error in GetApp"))
+					Expect(session).To(gbytes.Say("error in GetCurrentSpace"))
 					Expect(session.ExitCode()).To(Equal(1))
 				})
 			})
 
 			Context("when the app was successfully changed to Diego", func() {
 				BeforeEach(func() {
-					rpcHandlers.GetAppStub = func(_ string, retVal *plugin_models.GetAppModel) error {
-						*retVal = plugin_models.GetAppModel{Guid: "test-app-guid", Diego: true}
-						return nil
-					}
+					listApp("test-app-guid", true)
 				})
 
 				It("exit 0 after veriftying the flag is correct set", func() {
@@ -147,10 +208,7 @@ var _ = Describe("DiegoEnabler", func() {
 
 			Context("when the change to Diego failed", func() {
 				BeforeEach(func() {
-					rpcHandlers.GetAppStub = func(_ string, retVal *plugin_models.GetAppModel) error {
-						*retVal = plugin_models.GetAppModel{Guid: "test-app-guid", Diego: false}
-						return nil
-					}
+					listApp("test-app-guid", false)
 				})
 
 				It("exit 1 after veriftying the flag is not correct set", func() {
@@ -167,15 +225,7 @@ var _ = Describe("DiegoEnabler", func() {
 
 			Context("when the app has no routes", func() {
 				BeforeEach(func() {
-					rpcHandlers.GetAppStub = func(appName string, retVal *plugin_models.GetAppModel) error {
-						Expect(appName).To(Equal("test-app"))
-
-						*retVal = plugin_models.GetAppModel{
-							Guid: "test-app-guid",
-							Name: "test-app",
-						}
-						return nil
-					}
+					listApp("test-app-guid", false)
 
 					rpcHandlers.GetCurrentSpaceStub = func(_ string, retVal *plugin_models.Space) error {
 						*retVal = plugin_models.Space{
@@ -234,10 +284,7 @@ var _ = Describe("DiegoEnabler", func() {
 
 			Context("when the app is found", func() {
 				BeforeEach(func() {
-					rpcHandlers.GetAppStub = func(_ string, retVal *plugin_models.GetAppModel) error {
-						*retVal = plugin_models.GetAppModel{Guid: "test-app-guid"}
-						return nil
-					}
+					listApp("test-app-guid", true)
 				})
 
 				It("sets diego flag with /v2/apps endpoint", func() {
@@ -245,11 +292,9 @@ var _ = Describe("DiegoEnabler", func() {
 					Expect(err).NotTo(HaveOccurred())
 
 					session.Wait()
-					Expect(rpcHandlers.CallCoreCommandCallCount()).To(Equal(1))
-
-					output, _ := rpcHandlers.CallCoreCommandArgsForCall(0)
-					Expect(output[1]).To(ContainSubstring("v2/apps/test-app-guid"))
-					Expect(output[5]).To(ContainSubstring(`"diego":false`))
+					body, ok := appUpdated("/v2/apps/test-app-guid")
+					Expect(ok).To(BeTrue())
+					Expect(body).To(MatchJSON(`{"diego":false}`))
 				})
 			})
 
@@ -271,30 +316,21 @@ var _ = Describe("DiegoEnabler", func() {
 
 				Context("when the params are properly provided", func() {
 					BeforeEach(func() {
-						rpcHandlers.GetAppStub = func(_ string, retVal *plugin_models.GetAppModel) error {
-							*retVal = plugin_models.GetAppModel{}
-							return nil
-						}
+						listApp("test-app-guid", false)
 					})
 
-					It("calls GetApp() to get app model", func() {
+					It("looks up the app through the Cloud Controller", func() {
 
 						session, err := gexec.Start(exec.Command(validPluginPath, args...), GinkgoWriter, GinkgoWriter)
 						Expect(err).NotTo(HaveOccurred())
 
 						session.Wait()
-						Expect(rpcHandlers.GetAppCallCount()).To(Equal(1))
+						Expect(appLookupCount()).To(Equal(1))
+						Expect(rpcHandlers.GetAppCallCount()).To(BeZero())
 					})
 				})
 
 				Context("when the app does not exist", func() {
-					BeforeEach(func() {
-						rpcHandlers.GetAppStub = func(_ string, retVal *plugin_models.GetAppModel) error {
-							*retVal = plugin_models.GetAppModel{Guid: ""}
-							return nil
-						}
-					})
-
 					It("notifies user app is not found", func() {
 						session, err := gexec.Start(exec.Command(validPluginPath, args...), GinkgoWriter, GinkgoWriter)
 						Expect(err).NotTo(HaveOccurred())
@@ -307,10 +343,7 @@ var _ = Describe("DiegoEnabler", func() {
 
 				Context("when the app is on Diego", func() {
 					BeforeEach(func() {
-						rpcHandlers.GetAppStub = func(_ string, retVal *plugin_models.GetAppModel) error {
-							*retVal = plugin_models.GetAppModel{Guid: "test-app-guid", Diego: true}
-							return nil
-						}
+						listApp("test-app-guid", true)
 					})
 
 					It("outputs the app's Diego flag value", func() {
@@ -1,6 +1,10 @@
 package models
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"path"
+	"time"
+)
 
 type Applications []Application
 
@@ -16,30 +20,37 @@ const (
 type ApplicationEntity struct {
 	Name string `json:"name"`
 	//BuildpackUrl         string
+	Buildpack         string `json:"buildpack"`
+	DetectedBuildpack string `json:"detected_buildpack"`
 	//Command              string
 	Diego bool
 	//DetectedStartCommand string
-	//DiskQuota            int64 // in Megabytes
+	DiskQuota int64 `json:"disk_quota"` // in Megabytes
 	//EnvironmentVars      map[string]interface{}
-	//InstanceCount        int
-	//Memory               int64 // in Megabytes
+	InstanceCount int   `json:"instances"`
+	Memory        int64 `json:"memory"` // in Megabytes
 	//RunningInstances     int
-	//HealthCheckTimeout   int
-	State     string `json:"state"`
-	SpaceGuid string `json:"space_guid"`
+	HealthCheckType    string `json:"health_check_type"`
+	HealthCheckTimeout int    `json:"health_check_timeout"`
+	State              string `json:"state"`
+	SpaceGuid          string `json:"space_guid"`
 	//PackageUpdatedAt     *time.Time
-	//PackageState         string
+	PackageState string `json:"package_state"`
 	//StagingFailedReason  string
 	//AppPorts             []int
-	//Stack                *GetApp_Stack
+	StackGuid string `json:"stack_guid"`
+	// StackName is only known for apps read from the v3 API, which names
+	// the stack instead of linking to it.
+	StackName string `json:"-"`
 	//Instances            []GetApp_AppInstanceFields
 	//Routes               []GetApp_RouteSummary
 	//Services             []GetApp_ServiceSummary
-	HasRoutes bool
+	HasRoutes  bool
+	RouteCount int
 }
 
 type ApplicationsResponse struct {
-	Resources Applications `json:"resources"`
+	Resources []json.RawMessage `json:"resources"`
 }
 
 type Application struct {
@@ -47,8 +58,96 @@ type Application struct {
 	ApplicationMetadata `json:"metadata"`
 }
 
+// V3Application is an app as the v3 API lists it. Instance counts and memory
+// belong to the app's processes in v3, and the runtime is not known to it.
+type V3Application struct {
+	Guid      string    `json:"guid"`
+	Name      string    `json:"name"`
+	State     string    `json:"state"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Lifecycle struct {
+		Type string `json:"type"`
+		Data struct {
+			Buildpacks []string `json:"buildpacks"`
+			Stack      string   `json:"stack"`
+		} `json:"data"`
+	} `json:"lifecycle"`
+	Relationships struct {
+		Space struct {
+			Data struct {
+				Guid string `json:"guid"`
+			} `json:"data"`
+		} `json:"space"`
+	} `json:"relationships"`
+}
+
+// V3Process is a process of an app as the v3 API lists it. Older v3 APIs
+// only link a process to its app.
+type V3Process struct {
+	Type          string `json:"type"`
+	Instances     int    `json:"instances"`
+	MemoryInMB    int64  `json:"memory_in_mb"`
+	Relationships struct {
+		App struct {
+			Data struct {
+				Guid string `json:"guid"`
+			} `json:"data"`
+		} `json:"app"`
+	} `json:"relationships"`
+	Links struct {
+		App struct {
+			Href string `json:"href"`
+		} `json:"app"`
+	} `json:"links"`
+}
+
+// AppGuid returns the guid of the app the process belongs to.
+func (p V3Process) AppGuid() string {
+	if guid := p.Relationships.App.Data.Guid; guid != "" {
+		return guid
+	}
+	if p.Links.App.Href == "" {
+		return ""
+	}
+	return path.Base(p.Links.App.Href)
+}
+
+// ToApplication converts the app to the v2 model with the given processes.
+func (a V3Application) ToApplication(processes []V3Process) Application {
+	app := Application{
+		ApplicationMetadata: ApplicationMetadata{Guid: a.Guid},
+		ApplicationEntity: ApplicationEntity{
+			Name:      a.Name,
+			State:     a.State,
+			SpaceGuid: a.Relationships.Space.Data.Guid,
+			StackName: a.Lifecycle.Data.Stack,
+		},
+	}
+
+	if len(a.Lifecycle.Data.Buildpacks) > 0 {
+		app.Buildpack = a.Lifecycle.Data.Buildpacks[0]
+	}
+
+	app.ApplyProcesses(processes)
+	return app
+}
+
+// ApplyProcesses takes the instance count and memory of the app from its web
+// process, which is the one the v2 API shows.
+func (app *Application) ApplyProcesses(processes []V3Process) {
+	for _, process := range processes {
+		if process.Type == "web" {
+			app.InstanceCount = process.Instances
+			app.Memory = process.MemoryInMB
+		}
+	}
+}
+
 type ApplicationsParser struct{}
 
+// Parse reads apps from a v2 or a v3 listing. Only v2 resources have an
+// entity.
 func (a ApplicationsParser) Parse(body []byte) (Applications, error) {
 	var response ApplicationsResponse
 	var emptyApplications Applications
@@ -58,5 +157,33 @@ func (a ApplicationsParser) Parse(body []byte) (Applications, error) {
 		return emptyApplications, err
 	}
 
-	return response.Resources, nil
+	applications := Applications{}
+	for _, resource := range response.Resources {
+		var shape struct {
+			Entity json.RawMessage `json:"entity"`
+		}
+		err = json.Unmarshal(resource, &shape)
+		if err != nil {
+			return emptyApplications, err
+		}
+
+		if shape.Entity != nil {
+			var app Application
+			err = json.Unmarshal(resource, &app)
+			if err != nil {
+				return emptyApplications, err
+			}
+			applications = append(applications, app)
+			continue
+		}
+
+		var app V3Application
+		err = json.Unmarshal(resource, &app)
+		if err != nil {
+			return emptyApplications, err
+		}
+		applications = append(applications, app.ToApplication(nil))
+	}
+
+	return applications, nil
 }
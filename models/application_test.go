@@ -1,6 +1,8 @@
 package models_test
 
 import (
+	"encoding/json"
+
 	. "github.com/cloudfoundry-incubator/diego-enabler/models"
 
 	. "github.com/onsi/ginkgo"
@@ -119,6 +121,75 @@ var _ = Describe("Application", func() {
 			Expect(applications[0].SpaceGuid).To(Equal("1f7ac3a5-6f4e-4d6c-8edd-ce694fc8c907"))
 			Expect(applications[0].Guid).To(Equal("b2ba6466-23f7-4f90-935b-4da1c87b8943"))
 			Expect(applications[0].State).To(Equal(Started))
+			Expect(applications[0].InstanceCount).To(Equal(4))
+			Expect(applications[0].Memory).To(BeEquivalentTo(512))
+			Expect(applications[0].DiskQuota).To(BeEquivalentTo(1024))
+			Expect(applications[0].DetectedBuildpack).To(Equal("staticfile 1.3.1"))
+			Expect(applications[0].StackGuid).To(Equal("f3cecf19-4567-4dca-ad35-2a3af733cbde"))
+			Expect(applications[0].HealthCheckType).To(Equal("port"))
+			Expect(applications[0].PackageState).To(Equal("STAGED"))
+		})
+
+		It("parses apps listed by the v3 API", func() {
+			applications, err := ApplicationsParser{}.Parse([]byte(`{
+   "pagination": {"total_results": 1, "total_pages": 1},
+   "resources": [
+      {
+         "guid": "1cb006ee-fb05-47e1-b541-c34179ddc446",
+         "name": "my_app",
+         "state": "STOPPED",
+         "created_at": "2016-03-17T21:41:30Z",
+         "updated_at": "2016-03-18T11:32:30Z",
+         "lifecycle": {
+            "type": "buildpack",
+            "data": {
+               "buildpacks": ["java_buildpack"],
+               "stack": "cflinuxfs2"
+            }
+         },
+         "relationships": {
+            "space": {
+               "data": {"guid": "2f35885d-0c9d-4423-83ad-fd05066f8576"}
+            }
+         }
+      }
+   ]
+}`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(applications).To(HaveLen(1))
+			Expect(applications[0].Guid).To(Equal("1cb006ee-fb05-47e1-b541-c34179ddc446"))
+			Expect(applications[0].Name).To(Equal("my_app"))
+			Expect(applications[0].State).To(Equal(Stopped))
+			Expect(applications[0].SpaceGuid).To(Equal("2f35885d-0c9d-4423-83ad-fd05066f8576"))
+			Expect(applications[0].Buildpack).To(Equal("java_buildpack"))
+			Expect(applications[0].StackName).To(Equal("cflinuxfs2"))
+		})
+	})
+
+	Describe("V3Application", func() {
+		It("takes the instances and memory from the web process", func() {
+			app := V3Application{Guid: "some-app-guid", Name: "some-app"}.ToApplication([]V3Process{
+				{Type: "worker", Instances: 5, MemoryInMB: 2048},
+				{Type: "web", Instances: 2, MemoryInMB: 256},
+			})
+
+			Expect(app.Guid).To(Equal("some-app-guid"))
+			Expect(app.InstanceCount).To(Equal(2))
+			Expect(app.Memory).To(Equal(int64(256)))
+		})
+	})
+
+	Describe("V3Process", func() {
+		It("belongs to the app it is related to", func() {
+			var process V3Process
+			Expect(json.Unmarshal([]byte(`{"type": "web", "relationships": {"app": {"data": {"guid": "some-app-guid"}}}}`), &process)).To(Succeed())
+			Expect(process.AppGuid()).To(Equal("some-app-guid"))
+		})
+
+		It("belongs to the app it links to on older v3 APIs", func() {
+			var process V3Process
+			Expect(json.Unmarshal([]byte(`{"type": "web", "links": {"app": {"href": "https://api.example.com/v3/apps/some-app-guid"}}}`), &process)).To(Succeed())
+			Expect(process.AppGuid()).To(Equal("some-app-guid"))
 		})
 	})
 })
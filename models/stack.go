@@ -0,0 +1,36 @@
+package models
+
+import "encoding/json"
+
+type Stacks []Stack
+
+type StackEntity struct {
+	Name string `json:"name"`
+}
+
+type StackMetadata struct {
+	Guid string `json:"guid"`
+}
+
+type StacksResponse struct {
+	Resources Stacks `json:"resources"`
+}
+
+type Stack struct {
+	StackEntity   `json:"entity"`
+	StackMetadata `json:"metadata"`
+}
+
+type StacksParser struct{}
+
+func (a StacksParser) Parse(body []byte) (Stacks, error) {
+	var response StacksResponse
+	var emptyStacks Stacks
+
+	err := json.Unmarshal(body, &response)
+	if err != nil {
+		return emptyStacks, err
+	}
+
+	return response.Resources, nil
+}
@@ -0,0 +1,23 @@
+package thingdoer
+
+import "github.com/cloudfoundry-incubator/diego-enabler/models"
+
+// AllApps gets the apps on both runtimes in one pass, leaving it to the
+// caller to tell them apart by their Diego flag.
+func (c AppsGetter) AllApps(appsParser ApplicationsParser, paginatedRequester PaginatedRequester) (models.Applications, error) {
+	var noApps models.Applications
+
+	applications, err := c.listApps(nil, appsParser, paginatedRequester)
+	if err != nil {
+		return noApps, err
+	}
+
+	if c.LookupRoutes {
+		err = c.lookupRoutes(applications)
+		if err != nil {
+			return noApps, err
+		}
+	}
+
+	return applications, nil
+}
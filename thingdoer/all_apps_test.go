@@ -0,0 +1,94 @@
+package thingdoer_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/diego-enabler/api"
+	"github.com/cloudfoundry-incubator/diego-enabler/models"
+	"github.com/cloudfoundry-incubator/diego-enabler/thingdoer"
+	"github.com/cloudfoundry-incubator/diego-enabler/thingdoer/thingdoerfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AllApps", func() {
+	var (
+		command                thingdoer.AppsGetter
+		fakePaginatedRequester *thingdoerfakes.FakePaginatedRequester
+		fakeApplicationsParser *thingdoerfakes.FakeApplicationsParser
+		apps                   models.Applications
+		err                    error
+	)
+
+	BeforeEach(func() {
+		command = thingdoer.AppsGetter{}
+		fakePaginatedRequester = new(thingdoerfakes.FakePaginatedRequester)
+		fakeApplicationsParser = new(thingdoerfakes.FakeApplicationsParser)
+	})
+
+	JustBeforeEach(func() {
+		apps, err = command.AllApps(fakeApplicationsParser, fakePaginatedRequester)
+	})
+
+	It("should create a request without a diego filter", func() {
+		Expect(fakePaginatedRequester.DoCallCount()).To(Equal(1))
+		filters, _ := fakePaginatedRequester.DoArgsForCall(0)
+		Expect(filters).To(BeEmpty())
+	})
+
+	Context("when an organization name is specified", func() {
+		BeforeEach(func() {
+			command.OrganizationGuids = []string{"some-organization-guid"}
+		})
+
+		It("should create a request with organization guid set", func() {
+			expectedFilters := api.Filters{
+				api.EqualFilter{
+					Name:  "organization_guid",
+					Value: "some-organization-guid",
+				},
+			}
+
+			Expect(fakePaginatedRequester.DoCallCount()).To(Equal(1))
+			filters, _ := fakePaginatedRequester.DoArgsForCall(0)
+			Expect(filters).To(Equal(expectedFilters))
+		})
+	})
+
+	Context("when the paginated requester fails", func() {
+		var requestError error
+
+		BeforeEach(func() {
+			requestError = errors.New("making API requests failed")
+			fakePaginatedRequester.DoReturns([][]byte{}, requestError)
+		})
+
+		It("returns the requester error", func() {
+			Expect(apps).To(BeEmpty())
+			Expect(err).To(Equal(requestError))
+		})
+	})
+
+	Context("when the parsing succeeds", func() {
+		BeforeEach(func() {
+			fakePaginatedRequester.DoReturns([][]byte{[]byte("some-json")}, nil)
+			fakeApplicationsParser.ParseReturns(models.Applications{
+				models.Application{
+					ApplicationEntity:   models.ApplicationEntity{Diego: true},
+					ApplicationMetadata: models.ApplicationMetadata{Guid: "diego-guid"},
+				},
+				models.Application{
+					ApplicationEntity:   models.ApplicationEntity{Diego: false},
+					ApplicationMetadata: models.ApplicationMetadata{Guid: "dea-guid"},
+				},
+			}, nil)
+		})
+
+		It("returns the applications on both runtimes", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(apps).To(HaveLen(2))
+			Expect(apps[0].Guid).To(Equal("diego-guid"))
+			Expect(apps[1].Guid).To(Equal("dea-guid"))
+		})
+	})
+})
@@ -0,0 +1,43 @@
+package thingdoer
+
+import "github.com/cloudfoundry-incubator/diego-enabler/models"
+
+//go:generate counterfeiter . NameMatcher
+type NameMatcher interface {
+	Match(string) bool
+}
+
+// AppNameFilter keeps the apps whose name matches at least one Include
+// matcher, or all apps when there are none, and drops those matching any
+// Exclude matcher.
+type AppNameFilter struct {
+	Include []NameMatcher
+	Exclude []NameMatcher
+}
+
+func (f AppNameFilter) Apply(apps models.Applications) (models.Applications, int) {
+	var kept models.Applications
+
+	for _, app := range apps {
+		if len(f.Include) > 0 && !matchesAny(f.Include, app.Name) {
+			continue
+		}
+
+		if matchesAny(f.Exclude, app.Name) {
+			continue
+		}
+
+		kept = append(kept, app)
+	}
+
+	return kept, len(apps) - len(kept)
+}
+
+func matchesAny(matchers []NameMatcher, name string) bool {
+	for _, matcher := range matchers {
+		if matcher.Match(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package thingdoer_test
+
+import (
+	"strings"
+
+	"github.com/cloudfoundry-incubator/diego-enabler/models"
+	. "github.com/cloudfoundry-incubator/diego-enabler/thingdoer"
+	"github.com/cloudfoundry-incubator/diego-enabler/thingdoer/thingdoerfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AppNameFilter", func() {
+	var (
+		apps   models.Applications
+		filter AppNameFilter
+	)
+
+	prefixMatcher := func(prefix string) NameMatcher {
+		matcher := new(thingdoerfakes.FakeNameMatcher)
+		matcher.MatchStub = func(name string) bool {
+			return strings.HasPrefix(name, prefix)
+		}
+		return matcher
+	}
+
+	appNamed := func(name string) models.Application {
+		return models.Application{
+			ApplicationEntity: models.ApplicationEntity{Name: name},
+		}
+	}
+
+	BeforeEach(func() {
+		apps = models.Applications{
+			appNamed("payments-api"),
+			appNamed("payments-worker"),
+			appNamed("catalog"),
+		}
+		filter = AppNameFilter{}
+	})
+
+	Context("when there are no matchers", func() {
+		It("keeps every app", func() {
+			kept, excluded := filter.Apply(apps)
+			Expect(kept).To(Equal(apps))
+			Expect(excluded).To(Equal(0))
+		})
+	})
+
+	Context("when there are include matchers", func() {
+		BeforeEach(func() {
+			filter.Include = []NameMatcher{prefixMatcher("payments-")}
+		})
+
+		It("keeps only matching apps", func() {
+			kept, excluded := filter.Apply(apps)
+			Expect(kept).To(Equal(apps[:2]))
+			Expect(excluded).To(Equal(1))
+		})
+	})
+
+	Context("when there are exclude matchers", func() {
+		BeforeEach(func() {
+			filter.Exclude = []NameMatcher{prefixMatcher("payments-w"), prefixMatcher("cat")}
+		})
+
+		It("drops matching apps", func() {
+			kept, excluded := filter.Apply(apps)
+			Expect(kept).To(Equal(apps[:1]))
+			Expect(excluded).To(Equal(2))
+		})
+	})
+
+	Context("when there are include and exclude matchers", func() {
+		BeforeEach(func() {
+			filter.Include = []NameMatcher{prefixMatcher("payments-")}
+			filter.Exclude = []NameMatcher{prefixMatcher("payments-api")}
+		})
+
+		It("drops excluded apps from the included ones", func() {
+			kept, excluded := filter.Apply(apps)
+			Expect(kept).To(Equal(models.Applications{apps[1]}))
+			Expect(excluded).To(Equal(2))
+		})
+	})
+})
@@ -1,112 +1,75 @@
 package thingdoer
 
 import (
-	"encoding/json"
-	"errors"
 	"fmt"
-	"strings"
+	"sync"
 
 	"github.com/cloudfoundry-incubator/diego-enabler/api"
 	"github.com/cloudfoundry-incubator/diego-enabler/models"
 )
 
-type ccError struct {
-	Code        int    `json:"code"`
-	Description string `json:"description"`
-	ErrorCode   string `json:"error_code"`
-}
-
-func (e ccError) Error() string {
-	return fmt.Sprintf("CC code:       %d\nCC error code: %s\nDescription:   %s",
-		e.Code, e.ErrorCode, e.Description)
-}
-
-func handleCCError(ccErrResponse string) error {
-	ccErr := ccError{}
-	err := json.Unmarshal([]byte(ccErrResponse), &ccErr)
-	if err != nil {
-		return fmt.Errorf("Unexpected response:\n%s", ccErrResponse)
-	}
+// lookupRoutes fills in the routes of every app, which the apps endpoint
+// does not return.
+func (c AppsGetter) lookupRoutes(applications models.Applications) error {
+	return lookupEach(applications, "routes", func(app *models.Application) error {
+		routeCount, err := c.RouteCounter.CountAppRoutes(app.Guid)
+		if err != nil {
+			return err
+		}
 
-	return ccErr
+		app.HasRoutes = routeCount > 0
+		app.RouteCount = routeCount
+		return nil
+	})
 }
 
-func (c AppsGetter) ApplicationHasRoutes(appGUID string) (bool, error) {
-	response, err := c.CliConnection.CliCommandWithoutTerminalOutput("curl", fmt.Sprintf("/v2/apps/%s/routes", appGUID))
-	if err != nil {
-		return false, err
-	}
-
-	strResponse := strings.Join(response, "")
-
-	var ccMetadata struct {
-		TotalResults int `json:"total_results"`
-	}
-	err = json.Unmarshal([]byte(strResponse), &ccMetadata)
-	if err != nil {
-		return false, errors.New(strResponse)
+// lookupEach looks up what of every app is not listed with it, for up to
+// api.DefaultRouteLookupsInFlight apps at a time.
+func lookupEach(applications models.Applications, what string, lookup func(*models.Application) error) error {
+	var (
+		wg       sync.WaitGroup
+		mutex    sync.Mutex
+		firstErr error
+	)
+	inFlight := make(chan struct{}, api.DefaultRouteLookupsInFlight)
+
+	for i := range applications {
+		inFlight <- struct{}{}
+		wg.Add(1)
+
+		go func(app *models.Application) {
+			defer wg.Done()
+			defer func() { <-inFlight }()
+
+			err := lookup(app)
+			if err != nil {
+				mutex.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("Unable to get %s for app '%s'\n%s", what, app.Name, err.Error())
+				}
+				mutex.Unlock()
+			}
+		}(&applications[i])
 	}
 
-	if strings.Contains(strResponse, `"error_code":`) {
-		err = handleCCError(strResponse)
-	}
+	wg.Wait()
 
-	return ccMetadata.TotalResults > 0, err
+	return firstErr
 }
 
+// DeaApps gets the apps still on the DEAs.
 func (c AppsGetter) DeaApps(appsParser ApplicationsParser, paginatedRequester PaginatedRequester) (models.Applications, error) {
 	var noApps models.Applications
 
-	filter := api.Filters{
-		api.EqualFilter{
-			Name:  "diego",
-			Value: false,
-		},
-	}
-
-	if c.OrganizationGuid != "" {
-		filter = append(
-			filter,
-			api.EqualFilter{
-				Name:  "organization_guid",
-				Value: c.OrganizationGuid,
-			},
-		)
-	} else if c.SpaceGuid != "" {
-		filter = append(
-			filter,
-			api.EqualFilter{
-				Name:  "space_guid",
-				Value: c.SpaceGuid,
-			},
-		)
-	}
-
-	params := map[string]interface{}{}
-
-	responseBodies, err := paginatedRequester.Do(filter, params)
+	diego := false
+	applications, err := c.listApps(&diego, appsParser, paginatedRequester)
 	if err != nil {
 		return noApps, err
 	}
 
-	var applications models.Applications
-
-	for _, nextBody := range responseBodies {
-		apps, err := appsParser.Parse(nextBody)
-		if err != nil {
-			return noApps, err
-		}
-
-		applications = append(applications, apps...)
-	}
-
-	for i, app := range applications {
-		hasRoutes, err := c.ApplicationHasRoutes(app.Guid)
-		if err != nil {
-			return noApps, fmt.Errorf("Unable to get routes for app '%s'\n%s", app.Name, err.Error())
-		}
-
-		applications[i].HasRoutes = hasRoutes
+	err = c.lookupRoutes(applications)
+	if err != nil {
+		return noApps, err
 	}
 
 	return applications, nil
@@ -2,9 +2,11 @@ package thingdoer_test
 
 import (
 	"errors"
+	"fmt"
+	"sync"
+	"time"
 
 	"github.com/cloudfoundry-incubator/diego-enabler/api"
-	"github.com/cloudfoundry-incubator/diego-enabler/api/apifakes"
 	"github.com/cloudfoundry-incubator/diego-enabler/models"
 	"github.com/cloudfoundry-incubator/diego-enabler/thingdoer"
 	"github.com/cloudfoundry-incubator/diego-enabler/thingdoer/thingdoerfakes"
@@ -15,7 +17,7 @@ import (
 var _ = Describe("DeaApps", func() {
 	var (
 		command                thingdoer.AppsGetter
-		fakeConnection         *apifakes.FakeConnection
+		fakeRouteCounter       *thingdoerfakes.FakeRouteCounter
 		fakeApplicationsParser *thingdoerfakes.FakeApplicationsParser
 		fakePaginatedRequester *thingdoerfakes.FakePaginatedRequester
 		apps                   models.Applications
@@ -23,8 +25,8 @@ var _ = Describe("DeaApps", func() {
 	)
 
 	BeforeEach(func() {
-		fakeConnection = new(apifakes.FakeConnection)
-		command = thingdoer.AppsGetter{CliConnection: fakeConnection}
+		fakeRouteCounter = new(thingdoerfakes.FakeRouteCounter)
+		command = thingdoer.AppsGetter{RouteCounter: fakeRouteCounter}
 		fakePaginatedRequester = new(thingdoerfakes.FakePaginatedRequester)
 		fakeApplicationsParser = new(thingdoerfakes.FakeApplicationsParser)
 	})
@@ -49,7 +51,7 @@ var _ = Describe("DeaApps", func() {
 
 		Context("when an organization name is specified", func() {
 			BeforeEach(func() {
-				command.OrganizationGuid = "some-organization-guid"
+				command.OrganizationGuids = []string{"some-organization-guid"}
 			})
 
 			It("should create a request with organization guid set", func() {
@@ -70,9 +72,37 @@ var _ = Describe("DeaApps", func() {
 			})
 		})
 
+		Context("when a state is specified", func() {
+			BeforeEach(func() {
+				command.SpaceGuids = []string{"some-space-guid"}
+				command.State = "STOPPED"
+			})
+
+			It("should create a request with the state set", func() {
+				expectedFilters := api.Filters{
+					api.EqualFilter{
+						Name:  "diego",
+						Value: false,
+					},
+					api.EqualFilter{
+						Name:  "space_guid",
+						Value: "some-space-guid",
+					},
+					api.EqualFilter{
+						Name:  "state",
+						Value: "STOPPED",
+					},
+				}
+
+				Expect(fakePaginatedRequester.DoCallCount()).To(Equal(1))
+				filters, _ := fakePaginatedRequester.DoArgsForCall(0)
+				Expect(filters).To(Equal(expectedFilters))
+			})
+		})
+
 		Context("when an space name is specified", func() {
 			BeforeEach(func() {
-				command.SpaceGuid = "some-space-guid"
+				command.SpaceGuids = []string{"some-space-guid"}
 			})
 
 			It("should create a request with space guid set", func() {
@@ -93,6 +123,133 @@ var _ = Describe("DeaApps", func() {
 			})
 		})
 
+		Context("when listing apps from the v3 API", func() {
+			var (
+				fakeRuntimeRequester *thingdoerfakes.FakePaginatedRequester
+				fakeProcessGetter    *thingdoerfakes.FakeProcessGetter
+			)
+
+			process := func(appGuid string, instances int) models.V3Process {
+				var p models.V3Process
+				p.Type = "web"
+				p.Instances = instances
+				p.MemoryInMB = 256
+				p.Relationships.App.Data.Guid = appGuid
+				return p
+			}
+
+			BeforeEach(func() {
+				fakeRuntimeRequester = new(thingdoerfakes.FakePaginatedRequester)
+				fakeProcessGetter = new(thingdoerfakes.FakeProcessGetter)
+				command.Backend = api.BackendV3
+				command.RuntimeRequester = fakeRuntimeRequester
+				command.Processes = fakeProcessGetter
+				command.SpaceGuids = []string{"some-space-guid"}
+
+				fakeRuntimeRequester.DoReturns([][]byte{[]byte("v2-json")}, nil)
+				fakePaginatedRequester.DoReturns([][]byte{[]byte("v3-json")}, nil)
+				fakeApplicationsParser.ParseStub = func(body []byte) (models.Applications, error) {
+					if string(body) == "v2-json" {
+						return models.Applications{
+							{
+								ApplicationEntity:   models.ApplicationEntity{Name: "dea-app"},
+								ApplicationMetadata: models.ApplicationMetadata{Guid: "dea-app-guid"},
+							},
+							{
+								ApplicationEntity:   models.ApplicationEntity{Name: "deleted-app"},
+								ApplicationMetadata: models.ApplicationMetadata{Guid: "deleted-app-guid"},
+							},
+						}, nil
+					}
+					return models.Applications{
+						{
+							ApplicationEntity:   models.ApplicationEntity{Name: "dea-app", StackName: "cflinuxfs2"},
+							ApplicationMetadata: models.ApplicationMetadata{Guid: "dea-app-guid"},
+						},
+					}, nil
+				}
+				fakeProcessGetter.GetProcessesReturns([]models.V3Process{
+					process("dea-app-guid", 2),
+					process("other-app-guid", 5),
+				}, nil)
+				fakeRouteCounter.CountAppRoutesReturns(1, nil)
+			})
+
+			It("asks /v2/apps which apps are on the DEAs", func() {
+				Expect(fakeRuntimeRequester.DoCallCount()).To(Equal(1))
+				filters, _ := fakeRuntimeRequester.DoArgsForCall(0)
+				Expect(filters).To(Equal(api.Filters{
+					api.EqualFilter{Name: "diego", Value: false},
+					api.EqualFilter{Name: "space_guid", Value: "some-space-guid"},
+				}))
+			})
+
+			It("reads only those apps and their processes from the v3 API, in one request each", func() {
+				Expect(fakePaginatedRequester.DoCallCount()).To(Equal(1))
+				filters, _ := fakePaginatedRequester.DoArgsForCall(0)
+				Expect(filters).To(Equal(api.Filters{
+					api.InclusionFilter{Name: "guid", Values: []interface{}{"dea-app-guid", "deleted-app-guid"}},
+				}))
+
+				Expect(fakeProcessGetter.GetProcessesCallCount()).To(Equal(1))
+				Expect(fakeProcessGetter.GetProcessesArgsForCall(0)).To(Equal([]string{"dea-app-guid", "deleted-app-guid"}))
+			})
+
+			It("lists the DEA apps as the v3 API has them, with their processes", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(apps).To(HaveLen(1))
+				Expect(apps[0].Guid).To(Equal("dea-app-guid"))
+				Expect(apps[0].Diego).To(BeFalse())
+				Expect(apps[0].StackName).To(Equal("cflinuxfs2"))
+				Expect(apps[0].InstanceCount).To(Equal(2))
+				Expect(apps[0].Memory).To(Equal(int64(256)))
+				Expect(apps[0].RouteCount).To(Equal(1))
+			})
+
+			Context("when there are more apps than fit on a page", func() {
+				BeforeEach(func() {
+					fakeApplicationsParser.ParseStub = func(body []byte) (models.Applications, error) {
+						var apps models.Applications
+						for i := 0; i < api.MaxResultsPerPage+1; i++ {
+							apps = append(apps, models.Application{
+								ApplicationMetadata: models.ApplicationMetadata{Guid: fmt.Sprintf("app-guid-%d", i)},
+							})
+						}
+						return apps, nil
+					}
+				})
+
+				It("reads the apps and their processes a page at a time", func() {
+					Expect(fakePaginatedRequester.DoCallCount()).To(Equal(2))
+					Expect(fakeProcessGetter.GetProcessesCallCount()).To(Equal(2))
+					Expect(fakeProcessGetter.GetProcessesArgsForCall(0)).To(HaveLen(api.MaxResultsPerPage))
+					Expect(fakeProcessGetter.GetProcessesArgsForCall(1)).To(Equal([]string{fmt.Sprintf("app-guid-%d", api.MaxResultsPerPage)}))
+				})
+			})
+
+			Context("when looking up the processes fails", func() {
+				BeforeEach(func() {
+					fakeProcessGetter.GetProcessesReturns(nil, errors.New("no processes"))
+				})
+
+				It("returns the error", func() {
+					Expect(apps).To(BeEmpty())
+					Expect(err).To(MatchError("no processes"))
+				})
+			})
+
+			Context("when listing the runtime of the apps fails", func() {
+				BeforeEach(func() {
+					fakeRuntimeRequester.DoReturns(nil, errors.New("no runtimes"))
+				})
+
+				It("returns the error", func() {
+					Expect(apps).To(BeEmpty())
+					Expect(err).To(MatchError("no runtimes"))
+				})
+			})
+		})
+
 		Context("when the paginated requester fails", func() {
 			var requestError error
 
@@ -151,23 +308,17 @@ var _ = Describe("DeaApps", func() {
 
 				Context("when no errors are encountered getting routes for apps", func() {
 					BeforeEach(func() {
-						fakeConnection.CliCommandWithoutTerminalOutputReturns(
-							[]string{
-								"{",
-								`"total_results": 15`,
-								"}",
-							},
-							nil,
-						)
+						fakeRouteCounter.CountAppRoutesReturns(15, nil)
 					})
 
 					It("returns a list of diego applications", func() {
 						expectedApps := models.Applications{
 							models.Application{
 								models.ApplicationEntity{
-									Name:      "app-1",
-									Diego:     false,
-									HasRoutes: true,
+									Name:       "app-1",
+									Diego:      false,
+									HasRoutes:  true,
+									RouteCount: 15,
 								},
 								models.ApplicationMetadata{
 									Guid: "some-guid",
@@ -175,9 +326,10 @@ var _ = Describe("DeaApps", func() {
 							},
 							models.Application{
 								models.ApplicationEntity{
-									Name:      "app-1",
-									Diego:     false,
-									HasRoutes: true,
+									Name:       "app-1",
+									Diego:      false,
+									HasRoutes:  true,
+									RouteCount: 15,
 								},
 								models.ApplicationMetadata{
 									Guid: "some-guid",
@@ -192,120 +344,57 @@ var _ = Describe("DeaApps", func() {
 
 				Context("when getting routes for an application fails", func() {
 					BeforeEach(func() {
-						fakeConnection.CliCommandWithoutTerminalOutputReturns(
-							nil,
-							errors.New("getting routes error"),
-						)
+						fakeRouteCounter.CountAppRoutesReturns(0, errors.New("getting routes error"))
 					})
 
 					It("returns a getting routes error", func() {
 						Expect(err).To(MatchError("Unable to get routes for app 'app-1'\ngetting routes error"))
 					})
 				})
-			})
-		})
-	})
 
-	Describe("ApplicationHasRoutes", func() {
-		Context("when the application has routes", func() {
-			BeforeEach(func() {
-				fakeConnection.CliCommandWithoutTerminalOutputReturns(
-					[]string{
-						"{",
-						`"total_results": 15,`,
-						`"total_pages": 1`,
-						"}",
-					},
-					nil,
-				)
-			})
+				Context("when there are several apps", func() {
+					var (
+						mutex       sync.Mutex
+						inFlight    int
+						maxInFlight int
+					)
 
-			It("returns true", func() {
-				hasRoutes, err := command.ApplicationHasRoutes("some-app-guid")
-				Expect(err).ToNot(HaveOccurred())
-				Expect(hasRoutes).To(BeTrue())
-
-				Expect(fakeConnection.CliCommandWithoutTerminalOutputCallCount()).To(Equal(1))
-				Expect(fakeConnection.CliCommandWithoutTerminalOutputArgsForCall(0)).To(Equal(
-					[]string{
-						"curl",
-						"/v2/apps/some-app-guid/routes",
-					}))
-			})
-		})
-
-		Context("when the application does not have routes", func() {
-			BeforeEach(func() {
-				fakeConnection.CliCommandWithoutTerminalOutputReturns(
-					[]string{
-						"{",
-						`   "total_results": 0,`,
-						`   "total_pages": 1`,
-						"}",
-					},
-					nil,
-				)
-			})
-
-			It("returns false", func() {
-				hasRoutes, err := command.ApplicationHasRoutes("some-app-guid")
-				Expect(err).ToNot(HaveOccurred())
-				Expect(hasRoutes).To(BeFalse())
-			})
-		})
-
-		Context("when a rpc server error is encountered", func() {
-			var returnedErr error
-
-			BeforeEach(func() {
-				returnedErr = errors.New("rpc error")
-				fakeConnection.CliCommandWithoutTerminalOutputReturns(
-					nil,
-					returnedErr,
-				)
-			})
-
-			It("returns the error", func() {
-				_, err := command.ApplicationHasRoutes("some-app-guid")
-				Expect(err).To(MatchError(returnedErr))
-			})
-		})
-
-		Context("when a http error is encountered", func() {
-			BeforeEach(func() {
-				fakeConnection.CliCommandWithoutTerminalOutputReturns(
-					[]string{
-						"502 Bad Gateway: Registered endpoint failed to handle the request.",
-					},
-					nil,
-				)
-			})
-
-			It("returns the error", func() {
-				_, err := command.ApplicationHasRoutes("some-app-guid")
-				Expect(err).To(MatchError("502 Bad Gateway: Registered endpoint failed to handle the request."))
-			})
-		})
-
-		Context("when a cloud controller error is encountered", func() {
-			BeforeEach(func() {
-				fakeConnection.CliCommandWithoutTerminalOutputReturns(
-					[]string{
-						"{",
-						`   "code": 10000,`,
-						`   "description": "Unknown request",`,
-						`   "error_code": "CF-NotFound"`,
-						"}",
-					},
-					nil,
-				)
-			})
+					BeforeEach(func() {
+						inFlight, maxInFlight = 0, 0
+						bothInFlight := make(chan struct{})
+
+						fakeRouteCounter.CountAppRoutesStub = func(string) (int, error) {
+							mutex.Lock()
+							inFlight++
+							if inFlight > maxInFlight {
+								maxInFlight = inFlight
+							}
+							if inFlight == 2 {
+								close(bothInFlight)
+							}
+							mutex.Unlock()
+
+							select {
+							case <-bothInFlight:
+							case <-time.After(time.Second):
+							}
+
+							mutex.Lock()
+							inFlight--
+							mutex.Unlock()
+							return 3, nil
+						}
+					})
 
-			It("returns a cloud controller error", func() {
-				_, err := command.ApplicationHasRoutes("some-app-guid")
-				Expect(err).To(MatchError(`CC code:       10000
-CC error code: CF-NotFound
-Description:   Unknown request`))
+					It("looks up their routes at the same time", func() {
+						Expect(err).NotTo(HaveOccurred())
+						Expect(maxInFlight).To(Equal(2))
+						Expect(fakeRouteCounter.CountAppRoutesCallCount()).To(Equal(2))
+						Expect(fakeRouteCounter.CountAppRoutesArgsForCall(0)).To(Equal("some-guid"))
+						Expect(apps[0].RouteCount).To(Equal(3))
+						Expect(apps[1].RouteCount).To(Equal(3))
+					})
+				})
 			})
 		})
 	})
@@ -1,6 +1,8 @@
 package thingdoer
 
 import (
+	"time"
+
 	"github.com/cloudfoundry-incubator/diego-enabler/api"
 	"github.com/cloudfoundry-incubator/diego-enabler/models"
 )
@@ -12,59 +14,227 @@ type ApplicationsParser interface {
 	Parse([]byte) (models.Applications, error)
 }
 
+//go:generate counterfeiter . RouteCounter
+type RouteCounter interface {
+	CountAppRoutes(appGUID string) (int, error)
+}
+
+//go:generate counterfeiter . ProcessGetter
+type ProcessGetter interface {
+	GetProcesses(appGUIDs []string) ([]models.V3Process, error)
+}
+
 type AppsGetter struct {
-	OrganizationGuid string
-	SpaceGuid        string
-	CliConnection    api.Connection
+	// OrganizationGuids and SpaceGuids are the orgs and spaces to get apps
+	// from. Apps in any of them are returned; all apps when both are empty.
+	OrganizationGuids []string
+	SpaceGuids        []string
+	State             string
+
+	// UpdatedSince and CreatedBefore only keep the apps changed in a window
+	// of time; either is ignored when zero.
+	UpdatedSince  time.Time
+	CreatedBefore time.Time
+
+	// RouteCounter looks up the routes of up to
+	// api.DefaultRouteLookupsInFlight apps at a time.
+	RouteCounter RouteCounter
+
+	// Backend is the Cloud Controller API the apps are listed from. With the
+	// v3 backend the apps are listed by RuntimeRequester from /v2/apps, which
+	// knows their runtime, and then read from /v3/apps with their Processes
+	// a page of apps at a time.
+	Backend          api.Backend
+	RuntimeRequester PaginatedRequester
+	Processes        ProcessGetter
+
+	// LookupRoutes makes DiegoApps fill in the routes of every app, which
+	// DeaApps always does since migrating to Diego depends on them.
+	LookupRoutes bool
 }
 
-func (c AppsGetter) DiegoApps(
+// runtimeFilter starts the filter for the apps on one runtime, or on both
+// when diego is nil.
+func runtimeFilter(diego *bool) func() *api.FilterBuilder {
+	return func() *api.FilterBuilder {
+		builder := api.NewFilterBuilder()
+		if diego == nil {
+			return builder
+		}
+		return builder.Equal("diego", *diego)
+	}
+}
+
+// listApps gets the apps on one runtime, or on both when diego is nil. Only
+// the v2 API can filter on the runtime, so the v3 backend reads the apps the
+// v2 listing has from the v3 API.
+func (c AppsGetter) listApps(
+	diego *bool,
 	appsParser ApplicationsParser,
 	paginatedRequester PaginatedRequester,
 ) (models.Applications, error) {
 	var noApps models.Applications
 
-	filter := api.Filters{
-		api.EqualFilter{
-			Name:  "diego",
-			Value: true,
-		},
+	if c.Backend != api.BackendV3 {
+		return c.getApps(c.appsFilters(runtimeFilter(diego)), appsParser, paginatedRequester)
 	}
 
-	if c.OrganizationGuid != "" {
-		filter = append(
-			filter,
-			api.EqualFilter{
-				Name:  "organization_guid",
-				Value: c.OrganizationGuid,
-			},
-		)
-	} else if c.SpaceGuid != "" {
-		filter = append(
-			filter,
-			api.EqualFilter{
-				Name:  "space_guid",
-				Value: c.SpaceGuid,
-			},
-		)
+	runtimeApps, err := c.getApps(c.appsFilters(runtimeFilter(diego)), appsParser, c.RuntimeRequester)
+	if err != nil {
+		return noApps, err
 	}
 
-	params := map[string]interface{}{}
+	var applications models.Applications
+	for start := 0; start < len(runtimeApps); start += api.MaxResultsPerPage {
+		end := start + api.MaxResultsPerPage
+		if end > len(runtimeApps) {
+			end = len(runtimeApps)
+		}
 
-	responseBodies, err := paginatedRequester.Do(filter, params)
+		apps, err := c.getV3Apps(runtimeApps[start:end], appsParser, paginatedRequester)
+		if err != nil {
+			return noApps, err
+		}
+		applications = append(applications, apps...)
+	}
+
+	return applications, nil
+}
+
+// getV3Apps reads a page of v2 apps from the v3 API, with one request for
+// the apps and one for their processes. Apps deleted in the meantime are left
+// out.
+func (c AppsGetter) getV3Apps(
+	v2Apps models.Applications,
+	appsParser ApplicationsParser,
+	paginatedRequester PaginatedRequester,
+) (models.Applications, error) {
+	var noApps models.Applications
+
+	guids := make([]string, len(v2Apps))
+	for i, app := range v2Apps {
+		guids[i] = app.Guid
+	}
+
+	v3Apps, err := c.getApps([]api.Filters{api.NewFilterBuilder().OneOf("guid", guids).Filters()}, appsParser, paginatedRequester)
+	if err != nil {
+		return noApps, err
+	}
+
+	processes, err := c.Processes.GetProcesses(guids)
 	if err != nil {
 		return noApps, err
 	}
 
+	byGuid := map[string]models.Application{}
+	for _, app := range v3Apps {
+		byGuid[app.Guid] = app
+	}
+
+	processesByApp := map[string][]models.V3Process{}
+	for _, process := range processes {
+		appGuid := process.AppGuid()
+		processesByApp[appGuid] = append(processesByApp[appGuid], process)
+	}
+
 	var applications models.Applications
+	for _, v2App := range v2Apps {
+		app, ok := byGuid[v2App.Guid]
+		if !ok {
+			continue
+		}
 
-	for _, nextBody := range responseBodies {
-		apps, err := appsParser.Parse(nextBody)
+		app.Diego = v2App.Diego
+		app.ApplyProcesses(processesByApp[app.Guid])
+		applications = append(applications, app)
+	}
+
+	return applications, nil
+}
+
+// appsFilters returns the filter of every request needed to get the apps.
+// The Cloud Controller combines filters with AND, so apps in the orgs and
+// apps in the spaces take a request each.
+func (c AppsGetter) appsFilters(newBuilder func() *api.FilterBuilder) []api.Filters {
+	if len(c.OrganizationGuids) == 0 || len(c.SpaceGuids) == 0 {
+		return []api.Filters{
+			c.appsFilter(newBuilder().
+				OneOf("organization_guid", c.OrganizationGuids).
+				OneOf("space_guid", c.SpaceGuids)),
+		}
+	}
+
+	return []api.Filters{
+		c.appsFilter(newBuilder().OneOf("organization_guid", c.OrganizationGuids)),
+		c.appsFilter(newBuilder().OneOf("space_guid", c.SpaceGuids)),
+	}
+}
+
+// appsFilter adds the state and time window of the apps to get.
+func (c AppsGetter) appsFilter(builder *api.FilterBuilder) api.Filters {
+	return builder.
+		EqualIfSet("state", c.State).
+		Since("updated_at", c.UpdatedSince).
+		Before("created_at", c.CreatedBefore).
+		Filters()
+}
+
+// getApps gets the apps matching any of filters, each app once.
+func (c AppsGetter) getApps(
+	filters []api.Filters,
+	appsParser ApplicationsParser,
+	paginatedRequester PaginatedRequester,
+) (models.Applications, error) {
+	var noApps models.Applications
+
+	var applications models.Applications
+	seen := map[string]bool{}
+
+	for _, filter := range filters {
+		params := map[string]interface{}{}
+
+		responseBodies, err := paginatedRequester.Do(filter, params)
 		if err != nil {
 			return noApps, err
 		}
 
-		applications = append(applications, apps...)
+		for _, nextBody := range responseBodies {
+			apps, err := appsParser.Parse(nextBody)
+			if err != nil {
+				return noApps, err
+			}
+
+			for _, app := range apps {
+				// An app in one of the spaces can be in one of the orgs too.
+				if len(filters) > 1 && seen[app.Guid] {
+					continue
+				}
+				seen[app.Guid] = true
+				applications = append(applications, app)
+			}
+		}
+	}
+
+	return applications, nil
+}
+
+func (c AppsGetter) DiegoApps(
+	appsParser ApplicationsParser,
+	paginatedRequester PaginatedRequester,
+) (models.Applications, error) {
+	var noApps models.Applications
+
+	diego := true
+	applications, err := c.listApps(&diego, appsParser, paginatedRequester)
+	if err != nil {
+		return noApps, err
+	}
+
+	if c.LookupRoutes {
+		err = c.lookupRoutes(applications)
+		if err != nil {
+			return noApps, err
+		}
 	}
 
 	return applications, nil
@@ -2,6 +2,11 @@ package thingdoer_test
 
 import (
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/cloudfoundry-incubator/diego-enabler/api"
 	"github.com/cloudfoundry-incubator/diego-enabler/models"
@@ -45,7 +50,7 @@ var _ = Describe("DiegoApps", func() {
 
 	Context("when an organization name is specified", func() {
 		BeforeEach(func() {
-			command.OrganizationGuid = "some-organization-guid"
+			command.OrganizationGuids = []string{"some-organization-guid"}
 		})
 
 		It("should create a request with organization guid set", func() {
@@ -66,9 +71,112 @@ var _ = Describe("DiegoApps", func() {
 		})
 	})
 
+	Context("when a state is specified", func() {
+		BeforeEach(func() {
+			command.SpaceGuids = []string{"some-space-guid"}
+			command.State = "STOPPED"
+		})
+
+		It("should create a request with the state set", func() {
+			expectedFilters := api.Filters{
+				api.EqualFilter{
+					Name:  "diego",
+					Value: true,
+				},
+				api.EqualFilter{
+					Name:  "space_guid",
+					Value: "some-space-guid",
+				},
+				api.EqualFilter{
+					Name:  "state",
+					Value: "STOPPED",
+				},
+			}
+
+			Expect(fakePaginatedRequester.DoCallCount()).To(Equal(1))
+			filters, _ := fakePaginatedRequester.DoArgsForCall(0)
+			Expect(filters).To(Equal(expectedFilters))
+		})
+	})
+
+	Context("when a time window is specified", func() {
+		var updatedSince, createdBefore time.Time
+
+		BeforeEach(func() {
+			updatedSince = time.Date(2016, time.June, 1, 0, 0, 0, 0, time.UTC)
+			createdBefore = time.Date(2016, time.July, 1, 0, 0, 0, 0, time.UTC)
+			command.UpdatedSince = updatedSince
+			command.CreatedBefore = createdBefore
+		})
+
+		It("should create a request with the time window set", func() {
+			expectedFilters := api.Filters{
+				api.EqualFilter{
+					Name:  "diego",
+					Value: true,
+				},
+				api.ComparisonFilter{
+					Name:     "updated_at",
+					Operator: api.GreaterThanOrEqual,
+					Value:    updatedSince,
+				},
+				api.ComparisonFilter{
+					Name:     "created_at",
+					Operator: api.LessThan,
+					Value:    createdBefore,
+				},
+			}
+
+			Expect(fakePaginatedRequester.DoCallCount()).To(Equal(1))
+			filters, _ := fakePaginatedRequester.DoArgsForCall(0)
+			Expect(filters).To(Equal(expectedFilters))
+		})
+
+		Context("when the Cloud Controller cannot filter on the time window", func() {
+			var server *httptest.Server
+
+			BeforeEach(func() {
+				server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					for _, q := range r.URL.Query()["q"] {
+						if strings.HasPrefix(q, "updated_at") || strings.HasPrefix(q, "created_at") {
+							w.WriteHeader(http.StatusBadRequest)
+							w.Write([]byte(`{
+								"code": 1001,
+								"description": "The query parameter is invalid: updated_at is not a valid field",
+								"error_code": "CF-BadQueryParameter"
+							}`))
+							return
+						}
+					}
+					w.Write([]byte(`{"total_pages": 1, "resources": []}`))
+				}))
+
+				baseUrl, err := url.Parse(server.URL)
+				Expect(err).NotTo(HaveOccurred())
+				client := &api.Client{BaseUrl: baseUrl}
+				requester := &api.PaginatedRequester{
+					RequestFactory: client.HandleFiltersAndParameters(client.NewGetAppsRequest),
+					Client:         http.DefaultClient,
+					PageParser:     api.PageParser{},
+				}
+				fakePaginatedRequester.DoStub = requester.Do
+			})
+
+			AfterEach(func() {
+				server.Close()
+			})
+
+			It("returns the error instead of an empty listing", func() {
+				Expect(apps).To(BeEmpty())
+				Expect(err).To(MatchError("CF-BadQueryParameter - The query parameter is invalid: updated_at is not a valid field"))
+				Expect(fakeApplicationsParser.ParseCallCount()).To(BeZero())
+			})
+		})
+	})
+
 	Context("when an space name is specified", func() {
 		BeforeEach(func() {
-			command.SpaceGuid = "some-space-guid"
+			command.SpaceGuids = []string{"some-space-guid"}
 		})
 
 		It("should create a request with space guid set", func() {
@@ -89,6 +197,149 @@ var _ = Describe("DiegoApps", func() {
 		})
 	})
 
+	Context("when listing apps from the v3 API", func() {
+		var fakeRuntimeRequester *thingdoerfakes.FakePaginatedRequester
+
+		BeforeEach(func() {
+			fakeRuntimeRequester = new(thingdoerfakes.FakePaginatedRequester)
+			command.Backend = api.BackendV3
+			command.RuntimeRequester = fakeRuntimeRequester
+			command.Processes = new(thingdoerfakes.FakeProcessGetter)
+			command.SpaceGuids = []string{"some-space-guid"}
+			command.State = "STARTED"
+
+			fakeRuntimeRequester.DoReturns([][]byte{[]byte("v2-json")}, nil)
+			fakePaginatedRequester.DoReturns([][]byte{[]byte("v3-json")}, nil)
+			fakeApplicationsParser.ParseStub = func(body []byte) (models.Applications, error) {
+				if string(body) == "v2-json" {
+					return models.Applications{
+						{
+							ApplicationEntity:   models.ApplicationEntity{Diego: true, State: "STARTED"},
+							ApplicationMetadata: models.ApplicationMetadata{Guid: "started-guid"},
+						},
+					}, nil
+				}
+				return models.Applications{
+					{
+						ApplicationEntity:   models.ApplicationEntity{State: "STARTED"},
+						ApplicationMetadata: models.ApplicationMetadata{Guid: "started-guid"},
+					},
+					{
+						ApplicationEntity:   models.ApplicationEntity{State: "STOPPED"},
+						ApplicationMetadata: models.ApplicationMetadata{Guid: "stopped-guid"},
+					},
+				}, nil
+			}
+		})
+
+		It("only asks the v3 API for the apps the v2 API lists", func() {
+			expectedFilters := api.Filters{
+				api.EqualFilter{
+					Name:  "guid",
+					Value: "started-guid",
+				},
+			}
+
+			filters, _ := fakePaginatedRequester.DoArgsForCall(0)
+			Expect(filters).To(Equal(expectedFilters))
+		})
+
+		It("filters on the runtime and state through /v2/apps", func() {
+			expectedFilters := api.Filters{
+				api.EqualFilter{
+					Name:  "diego",
+					Value: true,
+				},
+				api.EqualFilter{
+					Name:  "space_guid",
+					Value: "some-space-guid",
+				},
+				api.EqualFilter{
+					Name:  "state",
+					Value: "STARTED",
+				},
+			}
+
+			filters, _ := fakeRuntimeRequester.DoArgsForCall(0)
+			Expect(filters).To(Equal(expectedFilters))
+		})
+
+		It("keeps the apps the v2 API lists on the runtime in the state asked for", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(apps).To(HaveLen(1))
+			Expect(apps[0].Guid).To(Equal("started-guid"))
+			Expect(apps[0].Diego).To(BeTrue())
+		})
+	})
+
+	Context("when several orgs are specified", func() {
+		BeforeEach(func() {
+			command.OrganizationGuids = []string{"org-guid-1", "org-guid-2"}
+		})
+
+		It("should create a request for apps in any of them", func() {
+			expectedFilters := api.Filters{
+				api.EqualFilter{
+					Name:  "diego",
+					Value: true,
+				},
+				api.InclusionFilter{
+					Name:   "organization_guid",
+					Values: []interface{}{"org-guid-1", "org-guid-2"},
+				},
+			}
+
+			Expect(fakePaginatedRequester.DoCallCount()).To(Equal(1))
+			filters, _ := fakePaginatedRequester.DoArgsForCall(0)
+			Expect(filters).To(Equal(expectedFilters))
+		})
+	})
+
+	Context("when both orgs and spaces are specified", func() {
+		BeforeEach(func() {
+			command.OrganizationGuids = []string{"org-guid"}
+			command.SpaceGuids = []string{"space-guid-1", "space-guid-2"}
+
+			fakePaginatedRequester.DoReturns([][]byte{[]byte("some-json")}, nil)
+			fakeApplicationsParser.ParseStub = func([]byte) (models.Applications, error) {
+				if fakeApplicationsParser.ParseCallCount() == 1 {
+					return models.Applications{
+						models.Application{ApplicationMetadata: models.ApplicationMetadata{Guid: "org-app-guid"}},
+						models.Application{ApplicationMetadata: models.ApplicationMetadata{Guid: "shared-app-guid"}},
+					}, nil
+				}
+				return models.Applications{
+					models.Application{ApplicationMetadata: models.ApplicationMetadata{Guid: "shared-app-guid"}},
+					models.Application{ApplicationMetadata: models.ApplicationMetadata{Guid: "space-app-guid"}},
+				}, nil
+			}
+		})
+
+		It("should create a request for the orgs and one for the spaces", func() {
+			Expect(fakePaginatedRequester.DoCallCount()).To(Equal(2))
+
+			orgFilters, _ := fakePaginatedRequester.DoArgsForCall(0)
+			Expect(orgFilters).To(Equal(api.Filters{
+				api.EqualFilter{Name: "diego", Value: true},
+				api.EqualFilter{Name: "organization_guid", Value: "org-guid"},
+			}))
+
+			spaceFilters, _ := fakePaginatedRequester.DoArgsForCall(1)
+			Expect(spaceFilters).To(Equal(api.Filters{
+				api.EqualFilter{Name: "diego", Value: true},
+				api.InclusionFilter{Name: "space_guid", Values: []interface{}{"space-guid-1", "space-guid-2"}},
+			}))
+		})
+
+		It("returns every app once", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(apps).To(HaveLen(3))
+			Expect(apps[0].Guid).To(Equal("org-app-guid"))
+			Expect(apps[1].Guid).To(Equal("shared-app-guid"))
+			Expect(apps[2].Guid).To(Equal("space-app-guid"))
+		})
+	})
+
 	Context("when the paginated requester fails", func() {
 		var requestError error
 
@@ -167,6 +418,31 @@ var _ = Describe("DiegoApps", func() {
 				Expect(apps).To(Equal(expectedApps))
 				Expect(err).NotTo(HaveOccurred())
 			})
+
+			It("does not look up routes", func() {
+				Expect(apps[0].HasRoutes).To(BeFalse())
+			})
+
+			Context("when looking up routes", func() {
+				var fakeRouteCounter *thingdoerfakes.FakeRouteCounter
+
+				BeforeEach(func() {
+					fakeRouteCounter = new(thingdoerfakes.FakeRouteCounter)
+					fakeRouteCounter.CountAppRoutesReturns(2, nil)
+
+					command.RouteCounter = fakeRouteCounter
+					command.LookupRoutes = true
+				})
+
+				It("fills in the routes of every app", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(fakeRouteCounter.CountAppRoutesCallCount()).To(Equal(2))
+					Expect(fakeRouteCounter.CountAppRoutesArgsForCall(0)).To(Equal("some-guid"))
+
+					Expect(apps[0].HasRoutes).To(BeTrue())
+					Expect(apps[0].RouteCount).To(Equal(2))
+				})
+			})
 		})
 	})
 })
@@ -0,0 +1,33 @@
+package thingdoer
+
+import (
+	"github.com/cloudfoundry-incubator/diego-enabler/api"
+	"github.com/cloudfoundry-incubator/diego-enabler/models"
+)
+
+//go:generate counterfeiter . StacksParser
+type StacksParser interface {
+	Parse([]byte) (models.Stacks, error)
+}
+
+func Stacks(stacksParser StacksParser, paginatedRequester PaginatedRequester) (models.Stacks, error) {
+	var noStacks models.Stacks
+
+	responseBodies, err := paginatedRequester.Do(api.Filters{}, map[string]interface{}{})
+	if err != nil {
+		return noStacks, err
+	}
+
+	var stacks models.Stacks
+
+	for _, nextBody := range responseBodies {
+		parsed, err := stacksParser.Parse(nextBody)
+		if err != nil {
+			return noStacks, err
+		}
+
+		stacks = append(stacks, parsed...)
+	}
+
+	return stacks, nil
+}
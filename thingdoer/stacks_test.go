@@ -0,0 +1,79 @@
+package thingdoer_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/diego-enabler/models"
+	"github.com/cloudfoundry-incubator/diego-enabler/thingdoer"
+	"github.com/cloudfoundry-incubator/diego-enabler/thingdoer/thingdoerfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Stacks", func() {
+	var (
+		fakePaginatedRequester *thingdoerfakes.FakePaginatedRequester
+		fakeStacksParser       *thingdoerfakes.FakeStacksParser
+		stacks                 models.Stacks
+		err                    error
+	)
+
+	BeforeEach(func() {
+		fakePaginatedRequester = new(thingdoerfakes.FakePaginatedRequester)
+		fakeStacksParser = new(thingdoerfakes.FakeStacksParser)
+	})
+
+	JustBeforeEach(func() {
+		stacks, err = thingdoer.Stacks(fakeStacksParser, fakePaginatedRequester)
+	})
+
+	Context("when the paginated requester fails", func() {
+		var requestError error
+
+		BeforeEach(func() {
+			requestError = errors.New("making API requests failed")
+			fakePaginatedRequester.DoReturns([][]byte{}, requestError)
+		})
+
+		It("returns the requester error", func() {
+			Expect(stacks).To(BeEmpty())
+			Expect(err).To(Equal(requestError))
+		})
+	})
+
+	Context("when the paginated requester succeeds", func() {
+		BeforeEach(func() {
+			fakePaginatedRequester.DoReturns([][]byte{[]byte("some-json"), []byte("some-other-json")}, nil)
+		})
+
+		Context("when the parsing fails", func() {
+			var parseError error
+
+			BeforeEach(func() {
+				parseError = errors.New("parsing json failed")
+				fakeStacksParser.ParseReturns(nil, parseError)
+			})
+
+			It("returns the parse error", func() {
+				Expect(stacks).To(BeEmpty())
+				Expect(err).To(Equal(parseError))
+			})
+		})
+
+		Context("when the parsing succeeds", func() {
+			var stack = models.Stack{
+				StackEntity:   models.StackEntity{Name: "cflinuxfs2"},
+				StackMetadata: models.StackMetadata{Guid: "some-guid"},
+			}
+
+			BeforeEach(func() {
+				fakeStacksParser.ParseReturns(models.Stacks{stack}, nil)
+			})
+
+			It("returns the stacks from every page", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stacks).To(Equal(models.Stacks{stack, stack}))
+			})
+		})
+	})
+})
@@ -0,0 +1,53 @@
+// This file was generated by counterfeiter
+package thingdoerfakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry-incubator/diego-enabler/thingdoer"
+)
+
+type FakeNameMatcher struct {
+	MatchStub        func(string) bool
+	matchMutex       sync.RWMutex
+	matchArgsForCall []struct {
+		arg1 string
+	}
+	matchReturns struct {
+		result1 bool
+	}
+}
+
+func (fake *FakeNameMatcher) Match(arg1 string) bool {
+	fake.matchMutex.Lock()
+	fake.matchArgsForCall = append(fake.matchArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	fake.matchMutex.Unlock()
+	if fake.MatchStub != nil {
+		return fake.MatchStub(arg1)
+	} else {
+		return fake.matchReturns.result1
+	}
+}
+
+func (fake *FakeNameMatcher) MatchCallCount() int {
+	fake.matchMutex.RLock()
+	defer fake.matchMutex.RUnlock()
+	return len(fake.matchArgsForCall)
+}
+
+func (fake *FakeNameMatcher) MatchArgsForCall(i int) string {
+	fake.matchMutex.RLock()
+	defer fake.matchMutex.RUnlock()
+	return fake.matchArgsForCall[i].arg1
+}
+
+func (fake *FakeNameMatcher) MatchReturns(result1 bool) {
+	fake.MatchStub = nil
+	fake.matchReturns = struct {
+		result1 bool
+	}{result1}
+}
+
+var _ thingdoer.NameMatcher = new(FakeNameMatcher)
@@ -0,0 +1,61 @@
+// This file was generated by counterfeiter
+package thingdoerfakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry-incubator/diego-enabler/models"
+	"github.com/cloudfoundry-incubator/diego-enabler/thingdoer"
+)
+
+type FakeProcessGetter struct {
+	GetProcessesStub        func(appGUIDs []string) ([]models.V3Process, error)
+	getProcessesMutex       sync.RWMutex
+	getProcessesArgsForCall []struct {
+		appGUIDs []string
+	}
+	getProcessesReturns struct {
+		result1 []models.V3Process
+		result2 error
+	}
+}
+
+func (fake *FakeProcessGetter) GetProcesses(appGUIDs []string) ([]models.V3Process, error) {
+	var appGUIDsCopy []string
+	if appGUIDs != nil {
+		appGUIDsCopy = make([]string, len(appGUIDs))
+		copy(appGUIDsCopy, appGUIDs)
+	}
+	fake.getProcessesMutex.Lock()
+	fake.getProcessesArgsForCall = append(fake.getProcessesArgsForCall, struct {
+		appGUIDs []string
+	}{appGUIDsCopy})
+	fake.getProcessesMutex.Unlock()
+	if fake.GetProcessesStub != nil {
+		return fake.GetProcessesStub(appGUIDs)
+	} else {
+		return fake.getProcessesReturns.result1, fake.getProcessesReturns.result2
+	}
+}
+
+func (fake *FakeProcessGetter) GetProcessesCallCount() int {
+	fake.getProcessesMutex.RLock()
+	defer fake.getProcessesMutex.RUnlock()
+	return len(fake.getProcessesArgsForCall)
+}
+
+func (fake *FakeProcessGetter) GetProcessesArgsForCall(i int) []string {
+	fake.getProcessesMutex.RLock()
+	defer fake.getProcessesMutex.RUnlock()
+	return fake.getProcessesArgsForCall[i].appGUIDs
+}
+
+func (fake *FakeProcessGetter) GetProcessesReturns(result1 []models.V3Process, result2 error) {
+	fake.GetProcessesStub = nil
+	fake.getProcessesReturns = struct {
+		result1 []models.V3Process
+		result2 error
+	}{result1, result2}
+}
+
+var _ thingdoer.ProcessGetter = new(FakeProcessGetter)
@@ -0,0 +1,55 @@
+// This file was generated by counterfeiter
+package thingdoerfakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry-incubator/diego-enabler/thingdoer"
+)
+
+type FakeRouteCounter struct {
+	CountAppRoutesStub        func(appGUID string) (int, error)
+	countAppRoutesMutex       sync.RWMutex
+	countAppRoutesArgsForCall []struct {
+		appGUID string
+	}
+	countAppRoutesReturns struct {
+		result1 int
+		result2 error
+	}
+}
+
+func (fake *FakeRouteCounter) CountAppRoutes(appGUID string) (int, error) {
+	fake.countAppRoutesMutex.Lock()
+	fake.countAppRoutesArgsForCall = append(fake.countAppRoutesArgsForCall, struct {
+		appGUID string
+	}{appGUID})
+	fake.countAppRoutesMutex.Unlock()
+	if fake.CountAppRoutesStub != nil {
+		return fake.CountAppRoutesStub(appGUID)
+	} else {
+		return fake.countAppRoutesReturns.result1, fake.countAppRoutesReturns.result2
+	}
+}
+
+func (fake *FakeRouteCounter) CountAppRoutesCallCount() int {
+	fake.countAppRoutesMutex.RLock()
+	defer fake.countAppRoutesMutex.RUnlock()
+	return len(fake.countAppRoutesArgsForCall)
+}
+
+func (fake *FakeRouteCounter) CountAppRoutesArgsForCall(i int) string {
+	fake.countAppRoutesMutex.RLock()
+	defer fake.countAppRoutesMutex.RUnlock()
+	return fake.countAppRoutesArgsForCall[i].appGUID
+}
+
+func (fake *FakeRouteCounter) CountAppRoutesReturns(result1 int, result2 error) {
+	fake.CountAppRoutesStub = nil
+	fake.countAppRoutesReturns = struct {
+		result1 int
+		result2 error
+	}{result1, result2}
+}
+
+var _ thingdoer.RouteCounter = new(FakeRouteCounter)
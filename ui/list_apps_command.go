@@ -1,58 +1,304 @@
 package ui
 
 import (
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"os"
+	"sort"
+	"strings"
 
 	"github.com/cloudfoundry/cli/cf/terminal"
+	"gopkg.in/yaml.v2"
 )
 
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+	OutputCSV   = "csv"
+	OutputYAML  = "yaml"
+)
+
+const (
+	SortByName    = "name"
+	SortByOrg     = "org"
+	SortBySpace   = "space"
+	SortByRuntime = "runtime"
+)
+
+const (
+	ColumnState        = "state"
+	ColumnInstances    = "instances"
+	ColumnMemory       = "memory"
+	ColumnDisk         = "disk"
+	ColumnStack        = "stack"
+	ColumnBuildpack    = "buildpack"
+	ColumnRoutes       = "routes"
+	ColumnHealthCheck  = "health-check"
+	ColumnPackageState = "package-state"
+)
+
+// Columns are the extra columns a listing can show after the name, space and
+// org of every app.
+var Columns = []string{
+	ColumnState,
+	ColumnInstances,
+	ColumnMemory,
+	ColumnDisk,
+	ColumnStack,
+	ColumnBuildpack,
+	ColumnRoutes,
+	ColumnHealthCheck,
+	ColumnPackageState,
+}
+
+// ListAppsCommand lists the apps on one runtime, or on both runtimes with a
+// runtime column when Runtime is empty.
 type ListAppsCommand struct {
-	Username     string
-	Runtime      Runtime
-	Organization string
-	Space        string
-	UI           terminal.UI
+	Username string
+	Runtime  Runtime
+	Scopes   []Scope
+	Output   string
+	Columns  []string
+	SortBy   string
+	GroupBy  string
+	UI       terminal.UI
+}
+
+type appRecord struct {
+	Name             string            `json:"name" yaml:"name"`
+	Guid             string            `json:"guid" yaml:"guid"`
+	Space            string            `json:"space" yaml:"space"`
+	SpaceGuid        string            `json:"space_guid" yaml:"space_guid"`
+	Organization     string            `json:"org" yaml:"org"`
+	OrganizationGuid string            `json:"org_guid" yaml:"org_guid"`
+	Runtime          string            `json:"runtime" yaml:"runtime"`
+	Columns          map[string]string `json:"columns,omitempty" yaml:"columns,omitempty"`
+}
+
+// machineReadable reports whether the listing is meant for scripts, in which
+// case only the apps themselves are written to stdout.
+func (c *ListAppsCommand) machineReadable() bool {
+	return c.Output != "" && c.Output != OutputTable
 }
 
 func (c *ListAppsCommand) BeforeAll() {
-	switch {
-	case c.Space != "" && c.Organization != "":
-		fmt.Printf(
-			"Getting apps on the %s runtime in org %s / %s as %s...\n",
-			terminal.EntityNameColor(c.Runtime.String()),
-			terminal.EntityNameColor(c.Organization),
-			terminal.EntityNameColor(c.Space),
-			terminal.EntityNameColor(c.Username),
-		)
-	case c.Organization != "":
-		fmt.Printf(
-			"Getting apps on the %s runtime in org %s as %s...\n",
-			terminal.EntityNameColor(c.Runtime.String()),
-			terminal.EntityNameColor(c.Organization),
-			terminal.EntityNameColor(c.Username),
-		)
-	default:
-		fmt.Printf(
-			"Getting apps on the %s runtime as %s...\n",
-			terminal.EntityNameColor(c.Runtime.String()),
-			terminal.EntityNameColor(c.Username),
-		)
+	if c.machineReadable() {
+		return
+	}
+
+	runtimes := "all runtimes"
+	if c.Runtime != "" {
+		runtimes = fmt.Sprintf("the %s runtime", terminal.EntityNameColor(c.Runtime.String()))
 	}
+
+	fmt.Printf(
+		"Getting apps on %s%s as %s...\n",
+		runtimes,
+		inScopes(c.Scopes),
+		terminal.EntityNameColor(c.Username),
+	)
 }
 
-func (c *ListAppsCommand) AfterAll(apps []ApplicationPrinter) {
+// AfterAll shows the apps. It returns the error writing machine readable
+// output, so that scripts reading it do not take a partial listing for a
+// complete one.
+func (c *ListAppsCommand) AfterAll(apps []ApplicationPrinter, excluded int) error {
+	apps = c.sort(apps)
+
+	if c.machineReadable() {
+		err := c.writeApps(apps)
+		if err != nil {
+			return fmt.Errorf("Failed to write apps: %s", err)
+		}
+
+		if excluded > 0 {
+			fmt.Fprintf(os.Stderr, "%d apps excluded by --include/--exclude\n", excluded)
+		}
+		return nil
+	}
+
 	SayOK()
 
+	if c.GroupBy == "" {
+		c.printTable(apps)
+	} else {
+		for start := 0; start < len(apps); {
+			group := groupKey(apps[start], c.GroupBy)
+
+			end := start + 1
+			for end < len(apps) && groupKey(apps[end], c.GroupBy) == group {
+				end++
+			}
+
+			fmt.Println()
+			fmt.Printf("%s %s:\n", c.GroupBy, terminal.EntityNameColor(group))
+			c.printTable(apps[start:end])
+
+			start = end
+		}
+	}
+
+	if excluded > 0 {
+		fmt.Println()
+		fmt.Printf("%d apps excluded by --include/--exclude\n", excluded)
+	}
+
+	return nil
+}
+
+func (c *ListAppsCommand) printTable(apps []ApplicationPrinter) {
 	headers := []string{
 		"name",
 		"space",
 		"org",
 	}
+	if c.Runtime == "" {
+		headers = append(headers, "runtime")
+	}
+	headers = append(headers, c.Columns...)
 	t := terminal.NewTable(c.UI, headers)
 
 	for _, app := range apps {
-		t.Add(app.Name(), app.Space(), app.Organization())
+		row := []string{app.Name(), app.Space(), app.Organization()}
+		if c.Runtime == "" {
+			row = append(row, app.Runtime().String())
+		}
+		for _, column := range c.Columns {
+			row = append(row, app.Column(column))
+		}
+		t.Add(row...)
 	}
 
 	t.Print()
 }
+
+// sort orders the apps by GroupBy, then by SortBy and then by name. Apps are
+// left in the order they were fetched in when neither is set.
+func (c *ListAppsCommand) sort(apps []ApplicationPrinter) []ApplicationPrinter {
+	var keys []string
+	if c.GroupBy == SortBySpace {
+		keys = append(keys, SortByOrg)
+	}
+	for _, key := range []string{c.GroupBy, c.SortBy} {
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return apps
+	}
+	keys = append(keys, SortByName)
+
+	sorted := make([]ApplicationPrinter, len(apps))
+	copy(sorted, apps)
+	sort.Stable(appsByKeys{apps: sorted, keys: keys})
+	return sorted
+}
+
+type appsByKeys struct {
+	apps []ApplicationPrinter
+	keys []string
+}
+
+func (a appsByKeys) Len() int      { return len(a.apps) }
+func (a appsByKeys) Swap(i, j int) { a.apps[i], a.apps[j] = a.apps[j], a.apps[i] }
+func (a appsByKeys) Less(i, j int) bool {
+	for _, key := range a.keys {
+		left := strings.ToLower(sortKey(a.apps[i], key))
+		right := strings.ToLower(sortKey(a.apps[j], key))
+		if left != right {
+			return left < right
+		}
+	}
+	return false
+}
+
+// groupKey tells spaces with the same name in different orgs apart.
+func groupKey(app ApplicationPrinter, key string) string {
+	if key == SortBySpace {
+		return app.Organization() + " / " + app.Space()
+	}
+	return sortKey(app, key)
+}
+
+func sortKey(app ApplicationPrinter, key string) string {
+	switch key {
+	case SortByOrg:
+		return app.Organization()
+	case SortBySpace:
+		return app.Space()
+	case SortByRuntime:
+		return app.Runtime().String()
+	default:
+		return app.Name()
+	}
+}
+
+func (c *ListAppsCommand) writeApps(apps []ApplicationPrinter) error {
+	records := []appRecord{}
+	for _, app := range apps {
+		records = append(records, appRecord{
+			Name:             app.Name(),
+			Guid:             app.Guid(),
+			Space:            app.Space(),
+			SpaceGuid:        app.SpaceGuid(),
+			Organization:     app.Organization(),
+			OrganizationGuid: app.OrganizationGuid(),
+			Runtime:          app.Runtime().String(),
+			Columns:          columns(app, c.Columns),
+		})
+	}
+
+	switch c.Output {
+	case OutputJSON:
+		contents, err := json.MarshalIndent(records, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Println(string(contents))
+		return err
+	case OutputYAML:
+		contents, err := yaml.Marshal(records)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Print(string(contents))
+		return err
+	case OutputCSV:
+		writer := csv.NewWriter(os.Stdout)
+		header := []string{"name", "guid", "space", "space_guid", "org", "org_guid", "runtime"}
+		writer.Write(append(header, c.Columns...))
+		for _, record := range records {
+			row := []string{
+				record.Name,
+				record.Guid,
+				record.Space,
+				record.SpaceGuid,
+				record.Organization,
+				record.OrganizationGuid,
+				record.Runtime,
+			}
+			for _, column := range c.Columns {
+				row = append(row, record.Columns[column])
+			}
+			writer.Write(row)
+		}
+		writer.Flush()
+		return writer.Error()
+	default:
+		return fmt.Errorf("unknown output format %s", c.Output)
+	}
+}
+
+func columns(app ApplicationPrinter, names []string) map[string]string {
+	if len(names) == 0 {
+		return nil
+	}
+
+	values := map[string]string{}
+	for _, name := range names {
+		values[name] = app.Column(name)
+	}
+	return values
+}
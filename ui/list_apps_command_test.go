@@ -0,0 +1,239 @@
+package ui_test
+
+import (
+	"encoding/json"
+	"os"
+
+	. "github.com/cloudfoundry-incubator/diego-enabler/ui"
+	"github.com/cloudfoundry-incubator/diego-enabler/ui/uifakes"
+	"github.com/cloudfoundry/cli/cf/terminal"
+	"github.com/cloudfoundry/cli/cf/trace"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("ListAppsCommand", func() {
+	var (
+		command    ListAppsCommand
+		appPrinter *uifakes.FakeApplicationPrinter
+		output     *Buffer
+	)
+
+	BeforeEach(func() {
+		command = ListAppsCommand{
+			Username: "some-user",
+			Runtime:  Diego,
+		}
+
+		appPrinter = new(uifakes.FakeApplicationPrinter)
+		appPrinter.NameReturns("some-app")
+		appPrinter.GuidReturns("some-app-guid")
+		appPrinter.SpaceReturns("some-space")
+		appPrinter.SpaceGuidReturns("some-space-guid")
+		appPrinter.OrganizationReturns("some-org")
+		appPrinter.OrganizationGuidReturns("some-org-guid")
+		appPrinter.RuntimeReturns(Diego)
+	})
+
+	Context("with JSON output", func() {
+		BeforeEach(func() {
+			command.Output = OutputJSON
+		})
+
+		It("writes only the apps, including their GUIDs", func() {
+			output = captureStdout(func() {
+				command.BeforeAll()
+				Expect(command.AfterAll([]ApplicationPrinter{appPrinter}, 1)).To(Succeed())
+			})
+
+			var apps []map[string]string
+			Expect(json.Unmarshal(output.Contents(), &apps)).To(Succeed())
+			Expect(apps).To(Equal([]map[string]string{{
+				"name":       "some-app",
+				"guid":       "some-app-guid",
+				"space":      "some-space",
+				"space_guid": "some-space-guid",
+				"org":        "some-org",
+				"org_guid":   "some-org-guid",
+				"runtime":    "Diego",
+			}}))
+		})
+
+		It("writes an empty list when there are no apps", func() {
+			output = captureStdout(func() {
+				Expect(command.AfterAll(nil, 0)).To(Succeed())
+			})
+
+			Expect(string(output.Contents())).To(Equal("[]\n"))
+		})
+
+		Context("when the apps cannot be written", func() {
+			var stdout *os.File
+
+			BeforeEach(func() {
+				closed, err := os.Open(os.DevNull)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(closed.Close()).To(Succeed())
+
+				stdout = os.Stdout
+				os.Stdout = closed
+			})
+
+			AfterEach(func() {
+				os.Stdout = stdout
+			})
+
+			It("returns the error", func() {
+				err := command.AfterAll([]ApplicationPrinter{appPrinter}, 0)
+				Expect(err).To(MatchError(ContainSubstring("Failed to write apps")))
+			})
+		})
+	})
+
+	Context("with an unknown output format", func() {
+		BeforeEach(func() {
+			command.Output = "xml"
+		})
+
+		It("returns an error", func() {
+			err := command.AfterAll([]ApplicationPrinter{appPrinter}, 0)
+			Expect(err).To(MatchError("Failed to write apps: unknown output format xml"))
+		})
+	})
+
+	Context("with CSV output", func() {
+		BeforeEach(func() {
+			command.Output = OutputCSV
+		})
+
+		It("writes a header and one row per app", func() {
+			output = captureStdout(func() {
+				Expect(command.AfterAll([]ApplicationPrinter{appPrinter}, 0)).To(Succeed())
+			})
+
+			Expect(string(output.Contents())).To(Equal(
+				"name,guid,space,space_guid,org,org_guid,runtime\n" +
+					"some-app,some-app-guid,some-space,some-space-guid,some-org,some-org-guid,Diego\n",
+			))
+		})
+	})
+
+	Context("with YAML output", func() {
+		BeforeEach(func() {
+			command.Output = OutputYAML
+		})
+
+		It("writes one document listing the apps", func() {
+			output = captureStdout(func() {
+				Expect(command.AfterAll([]ApplicationPrinter{appPrinter}, 0)).To(Succeed())
+			})
+
+			Expect(output).To(Say("- name: some-app"))
+			Expect(output).To(Say("guid: some-app-guid"))
+			Expect(output).To(Say("space_guid: some-space-guid"))
+			Expect(output).To(Say("org_guid: some-org-guid"))
+		})
+	})
+
+	Context("with extra columns", func() {
+		BeforeEach(func() {
+			command.Columns = []string{ColumnMemory, ColumnStack}
+			appPrinter.ColumnStub = func(name string) string {
+				return map[string]string{
+					ColumnMemory: "1G",
+					ColumnStack:  "cflinuxfs2",
+				}[name]
+			}
+		})
+
+		It("adds the columns to CSV output in the requested order", func() {
+			command.Output = OutputCSV
+			output = captureStdout(func() {
+				Expect(command.AfterAll([]ApplicationPrinter{appPrinter}, 0)).To(Succeed())
+			})
+
+			Expect(string(output.Contents())).To(Equal(
+				"name,guid,space,space_guid,org,org_guid,runtime,memory,stack\n" +
+					"some-app,some-app-guid,some-space,some-space-guid,some-org,some-org-guid,Diego,1G,cflinuxfs2\n",
+			))
+		})
+
+		It("adds the columns to JSON output", func() {
+			command.Output = OutputJSON
+			output = captureStdout(func() {
+				Expect(command.AfterAll([]ApplicationPrinter{appPrinter}, 0)).To(Succeed())
+			})
+
+			var apps []struct {
+				Columns map[string]string `json:"columns"`
+			}
+			Expect(json.Unmarshal(output.Contents(), &apps)).To(Succeed())
+			Expect(apps[0].Columns).To(Equal(map[string]string{
+				"memory": "1G",
+				"stack":  "cflinuxfs2",
+			}))
+		})
+	})
+
+	Context("when listing apps on all runtimes", func() {
+		var otherApp *uifakes.FakeApplicationPrinter
+
+		BeforeEach(func() {
+			command.Runtime = ""
+			command.UI = terminal.NewUI(os.Stdin, terminal.NewTeePrinter(), trace.NewLogger(false, "", ""))
+
+			otherApp = new(uifakes.FakeApplicationPrinter)
+			otherApp.NameReturns("other-app")
+			otherApp.SpaceReturns("other-space")
+			otherApp.OrganizationReturns("some-org")
+			otherApp.RuntimeReturns(DEA)
+		})
+
+		It("shows the runtime of every app", func() {
+			output = captureStdout(func() {
+				command.BeforeAll()
+				Expect(command.AfterAll([]ApplicationPrinter{appPrinter, otherApp}, 0)).To(Succeed())
+			})
+
+			Expect(output).To(Say("Getting apps on all runtimes as .+some-user"))
+			Expect(output).To(Say("name.+space.+org.+runtime"))
+			Expect(output).To(Say("some-app.+some-space.+some-org.+Diego"))
+			Expect(output).To(Say("other-app.+other-space.+some-org.+DEA"))
+		})
+
+		It("sorts the apps by the sort key", func() {
+			command.SortBy = SortByRuntime
+			output = captureStdout(func() {
+				Expect(command.AfterAll([]ApplicationPrinter{appPrinter, otherApp}, 0)).To(Succeed())
+			})
+
+			Expect(output).To(Say("other-app.+DEA"))
+			Expect(output).To(Say("some-app.+Diego"))
+		})
+
+		It("prints one table per group", func() {
+			command.GroupBy = SortBySpace
+			output = captureStdout(func() {
+				Expect(command.AfterAll([]ApplicationPrinter{appPrinter, otherApp}, 0)).To(Succeed())
+			})
+
+			Expect(output).To(Say("space .*some-org / other-space.*:"))
+			Expect(output).To(Say("other-app"))
+			Expect(output).To(Say("space .*some-org / some-space.*:"))
+			Expect(output).To(Say("some-app"))
+		})
+
+		It("writes the runtime of every app in machine readable output", func() {
+			command.Output = OutputCSV
+			command.SortBy = SortByName
+			output = captureStdout(func() {
+				Expect(command.AfterAll([]ApplicationPrinter{appPrinter, otherApp}, 0)).To(Succeed())
+			})
+
+			Expect(output).To(Say(",,other-space,,some-org,,DEA"))
+			Expect(output).To(Say("some-app,.+,Diego"))
+		})
+	})
+})
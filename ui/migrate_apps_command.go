@@ -1,43 +1,128 @@
 package ui
 
 import (
+	"bufio"
 	"fmt"
 	"io"
+	"strings"
+	"time"
 
 	"github.com/cloudfoundry/cli/cf/terminal"
 )
 
+type MigrationSummary struct {
+	Attempts     int
+	OKWarnings   int
+	FailWarnings int
+	Errors       int
+	Crashed      int
+	TimedOut     int
+	RolledBack   []ApplicationPrinter
+	NotAttempted []ApplicationPrinter
+	Excluded     int
+	Interrupted  bool
+}
+
 type MigrateAppsCommand struct {
-	Username     string
-	Runtime      Runtime
-	Organization string
-	Space        string
+	Username string
+	Runtime  Runtime
+	Scopes   []Scope
+	DryRun   bool
+
+	// Stdin is where ConfirmAllOrgs reads the answer from.
+	Stdin io.Reader
 }
 
 func (c *MigrateAppsCommand) BeforeAll() {
-	switch {
-	case c.Organization != "" && c.Space != "":
-		fmt.Printf(
-			"Migrating apps to %s in org %s / %s as %s...\n",
-			terminal.EntityNameColor(c.Runtime.String()),
-			terminal.EntityNameColor(c.Organization),
-			terminal.EntityNameColor(c.Space),
-			terminal.EntityNameColor(c.Username),
-		)
-	case c.Organization != "":
-		fmt.Printf(
-			"Migrating apps to %s in org %s as %s...\n",
-			terminal.EntityNameColor(c.Runtime.String()),
-			terminal.EntityNameColor(c.Organization),
-			terminal.EntityNameColor(c.Username),
-		)
-	default:
-		fmt.Printf(
-			"Migrating apps to %s as %s...\n",
-			terminal.EntityNameColor(c.Runtime.String()),
-			terminal.EntityNameColor(c.Username),
-		)
+	if c.DryRun {
+		fmt.Print("Dry run: ")
 	}
+
+	fmt.Printf(
+		"Migrating apps to %s%s as %s...\n",
+		terminal.EntityNameColor(c.Runtime.String()),
+		inScopes(c.Scopes),
+		terminal.EntityNameColor(c.Username),
+	)
+}
+
+func (c *MigrateAppsCommand) ResumeJournal(path string, skipped int) {
+	fmt.Printf(
+		"Skipping %d apps already migrated according to journal %s\n",
+		skipped,
+		terminal.EntityNameColor(path),
+	)
+}
+
+func (c *MigrateAppsCommand) PlanWritten(path string, apps int) {
+	SayOK()
+	fmt.Printf(
+		"Wrote plan to migrate %d apps to %s to %s\n",
+		apps,
+		terminal.EntityNameColor(c.Runtime.String()),
+		terminal.EntityNameColor(path),
+	)
+}
+
+func (c *MigrateAppsCommand) PlanChangedWarning(name string, organization string, space string) {
+	fmt.Printf(
+		"WARNING: Skipping app %s in space %s / org %s because it changed since the plan was made\n",
+		terminal.EntityNameColor(name),
+		terminal.EntityNameColor(space),
+		terminal.EntityNameColor(organization),
+	)
+}
+
+func (c *MigrateAppsCommand) BeforeCanary(canary int) {
+	fmt.Println()
+	fmt.Printf("Migrating a canary of %d apps to %s first...\n", canary, terminal.EntityNameColor(c.Runtime.String()))
+}
+
+func (c *MigrateAppsCommand) CanarySucceeded(canary int) {
+	fmt.Println()
+	fmt.Printf("Canary of %d apps succeeded, migrating the remaining apps...\n", canary)
+}
+
+func (c *MigrateAppsCommand) CanaryFailed(canary int) {
+	fmt.Println()
+	fmt.Printf("Error: Canary of %d apps failed, not migrating the remaining apps\n", canary)
+}
+
+func (c *MigrateAppsCommand) MaxFailuresReached(maxFailures int) {
+	fmt.Println()
+	fmt.Printf("Error: %d apps failed to migrate, not migrating the remaining apps\n", maxFailures)
+}
+
+func (c *MigrateAppsCommand) Interrupted() {
+	fmt.Println()
+	fmt.Println("Interrupted: finishing the apps in progress, not migrating the remaining apps. Interrupt again to abort immediately")
+}
+
+func (c *MigrateAppsCommand) Aborted() {
+	fmt.Println()
+	fmt.Println("Aborted: apps in progress may be left half migrated")
+}
+
+// ConfirmAllOrgs asks before migrating the apps of every org. Only y or yes
+// confirms.
+func (c *MigrateAppsCommand) ConfirmAllOrgs(apps int) bool {
+	fmt.Printf(
+		"\nReally migrate %d apps in all orgs to %s?%s ",
+		apps,
+		terminal.EntityNameColor(c.Runtime.String()),
+		terminal.PromptColor(">"),
+	)
+
+	answer, _ := bufio.NewReader(c.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
+func (c *MigrateAppsCommand) Cancelled() {
+	fmt.Println("Migration cancelled")
 }
 
 func (c *MigrateAppsCommand) BeforeEach(app ApplicationPrinter) {
@@ -52,6 +137,36 @@ func (c *MigrateAppsCommand) BeforeEach(app ApplicationPrinter) {
 	)
 }
 
+func (c *MigrateAppsCommand) DryRunEach(app ApplicationPrinter, restart bool) {
+	action := "would be configured to run on %s but not started"
+	if restart {
+		action = "would be restarted on %s"
+	}
+
+	fmt.Printf(
+		"App %s in org %s / space %s "+action+"\n",
+		terminal.EntityNameColor(app.Name()),
+		terminal.EntityNameColor(app.Organization()),
+		terminal.EntityNameColor(app.Space()),
+		terminal.EntityNameColor(c.Runtime.String()),
+	)
+}
+
+func (c *MigrateAppsCommand) DryRunAfterAll(apps int, restarts int, warnings int, excluded int) {
+	fmt.Println()
+	fmt.Printf(
+		"Dry run of migration to %s completed: %d apps would be migrated, %d restarted, %d warnings\n",
+		terminal.EntityNameColor(c.Runtime.String()),
+		apps,
+		restarts,
+		warnings,
+	)
+
+	if excluded > 0 {
+		fmt.Printf("%d apps excluded by --include/--exclude\n", excluded)
+	}
+}
+
 func (c *MigrateAppsCommand) CompletedEach(app ApplicationPrinter) {
 	fmt.Println()
 	fmt.Printf(
@@ -68,11 +183,56 @@ func (c *MigrateAppsCommand) DuringEach() {
 	fmt.Print(".")
 }
 
-func (c *MigrateAppsCommand) AfterAll(attempts int, okWarnings int, failWarnings int, errors int) {
-	successes := attempts - failWarnings - errors
-	warnings := okWarnings + failWarnings
+func (c *MigrateAppsCommand) AfterAll(summary MigrationSummary) {
+	successes := summary.Attempts - summary.FailWarnings - summary.Errors - summary.Crashed - summary.TimedOut - len(summary.RolledBack)
+	warnings := summary.OKWarnings + summary.FailWarnings
+	outcome := "completed"
+	if summary.Interrupted {
+		outcome = "interrupted"
+	}
 	fmt.Println()
-	fmt.Printf("Migration to %s completed: %d apps, %d errors, %d warnings\n", terminal.EntityNameColor(c.Runtime.String()), successes, errors, warnings)
+	fmt.Printf(
+		"Migration to %s %s: %d apps, %d errors, %d warnings, %d crashed, %d timed out, %d rolled back\n",
+		terminal.EntityNameColor(c.Runtime.String()),
+		outcome,
+		successes,
+		summary.Errors,
+		warnings,
+		summary.Crashed,
+		summary.TimedOut,
+		len(summary.RolledBack),
+	)
+
+	if summary.Excluded > 0 {
+		fmt.Printf("%d apps excluded by --include/--exclude\n", summary.Excluded)
+	}
+
+	if len(summary.NotAttempted) > 0 {
+		fmt.Printf("%d apps were not attempted\n", len(summary.NotAttempted))
+	}
+
+	if len(summary.RolledBack) > 0 {
+		fmt.Println()
+		fmt.Printf("Apps rolled back to %s:\n", terminal.EntityNameColor(c.Runtime.Flip().String()))
+		printAppList(summary.RolledBack)
+	}
+
+	if len(summary.NotAttempted) > 0 {
+		fmt.Println()
+		fmt.Printf("Apps not attempted, still on %s:\n", terminal.EntityNameColor(c.Runtime.Flip().String()))
+		printAppList(summary.NotAttempted)
+	}
+}
+
+func printAppList(apps []ApplicationPrinter) {
+	for _, app := range apps {
+		fmt.Printf(
+			"  %s in space %s / org %s\n",
+			terminal.EntityNameColor(app.Name()),
+			terminal.EntityNameColor(app.Space()),
+			terminal.EntityNameColor(app.Organization()),
+		)
+	}
 }
 
 func (c *MigrateAppsCommand) UserWarning(app ApplicationPrinter) {
@@ -109,3 +269,72 @@ func (c *MigrateAppsCommand) HealthCheckNoneWarning(app ApplicationPrinter, writ
 		terminal.EntityNameColor(c.Username),
 	)
 }
+
+func (c *MigrateAppsCommand) CrashedEach(app ApplicationPrinter) {
+	fmt.Println()
+	fmt.Printf(
+		"Error: App %s crashed after migrating to %s in space %s / org %s as %s\n",
+		terminal.EntityNameColor(app.Name()),
+		terminal.EntityNameColor(c.Runtime.String()),
+		terminal.EntityNameColor(app.Space()),
+		terminal.EntityNameColor(app.Organization()),
+		terminal.EntityNameColor(c.Username),
+	)
+}
+
+func (c *MigrateAppsCommand) RollingBackEach(app ApplicationPrinter) {
+	fmt.Printf(
+		"Rolling back app %s to %s in space %s / org %s as %s...\n",
+		terminal.EntityNameColor(app.Name()),
+		terminal.EntityNameColor(c.Runtime.Flip().String()),
+		terminal.EntityNameColor(app.Space()),
+		terminal.EntityNameColor(app.Organization()),
+		terminal.EntityNameColor(c.Username),
+	)
+}
+
+func (c *MigrateAppsCommand) RolledBackEach(app ApplicationPrinter) {
+	fmt.Println()
+	fmt.Printf(
+		"Rolled back app %s to %s in space %s / org %s as %s\n",
+		terminal.EntityNameColor(app.Name()),
+		terminal.EntityNameColor(c.Runtime.Flip().String()),
+		terminal.EntityNameColor(app.Space()),
+		terminal.EntityNameColor(app.Organization()),
+		terminal.EntityNameColor(c.Username),
+	)
+}
+
+func (c *MigrateAppsCommand) FailRollback(app ApplicationPrinter, err error) {
+	fmt.Println()
+	fmt.Printf(
+		"Error: Failed to roll back app %s to %s in space %s / org %s as %s: %s\n",
+		terminal.EntityNameColor(app.Name()),
+		terminal.EntityNameColor(c.Runtime.Flip().String()),
+		terminal.EntityNameColor(app.Space()),
+		terminal.EntityNameColor(app.Organization()),
+		terminal.EntityNameColor(c.Username),
+		terminal.EntityNameColor(err.Error()),
+	)
+}
+
+func (c *MigrateAppsCommand) TimedOutEach(app ApplicationPrinter, timeout time.Duration) {
+	fmt.Println()
+	fmt.Printf(
+		"Error: App %s did not start on %s within %s in space %s / org %s as %s\n",
+		terminal.EntityNameColor(app.Name()),
+		terminal.EntityNameColor(c.Runtime.String()),
+		terminal.EntityNameColor(timeout.String()),
+		terminal.EntityNameColor(app.Space()),
+		terminal.EntityNameColor(app.Organization()),
+		terminal.EntityNameColor(c.Username),
+	)
+}
+
+func (c *MigrateAppsCommand) JournalWarning(path string, err error) {
+	fmt.Printf(
+		"WARNING: Failed to update journal %s: %s\n",
+		terminal.EntityNameColor(path),
+		terminal.EntityNameColor(err.Error()),
+	)
+}
@@ -1,6 +1,8 @@
 package ui_test
 
 import (
+	"strings"
+
 	. "github.com/cloudfoundry-incubator/diego-enabler/ui"
 	"github.com/cloudfoundry-incubator/diego-enabler/ui/uifakes"
 
@@ -29,6 +31,86 @@ var _ = Describe("MigrateAppsCommand", func() {
 		output = NewBuffer()
 	})
 
+	Describe("ConfirmAllOrgs", func() {
+		BeforeEach(func() {
+			command.Runtime = Diego
+		})
+
+		It("asks with the number of apps", func() {
+			command.Stdin = strings.NewReader("y\n")
+
+			var confirmed bool
+			output = captureStdout(func() {
+				confirmed = command.ConfirmAllOrgs(12)
+			})
+
+			Expect(confirmed).To(BeTrue())
+			Expect(output).To(Say("Really migrate 12 apps in all orgs to .+Diego.+\\?"))
+		})
+
+		It("accepts yes in any case", func() {
+			command.Stdin = strings.NewReader("YES\n")
+			output = captureStdout(func() {
+				Expect(command.ConfirmAllOrgs(1)).To(BeTrue())
+			})
+		})
+
+		It("does not migrate for any other answer", func() {
+			for _, answer := range []string{"n\n", "\n", "sure\n", ""} {
+				command.Stdin = strings.NewReader(answer)
+				output = captureStdout(func() {
+					Expect(command.ConfirmAllOrgs(1)).To(BeFalse())
+				})
+			}
+		})
+	})
+
+	Describe("AfterAll", func() {
+		It("does not count crashed or timed out apps as migrated", func() {
+			output = captureStdout(func() {
+				command.AfterAll(MigrationSummary{
+					Attempts:   5,
+					OKWarnings: 1,
+					Errors:     1,
+					Crashed:    1,
+					TimedOut:   1,
+				})
+			})
+
+			Expect(output).To(Say("completed: 2 apps, 1 errors, 1 warnings, 1 crashed, 1 timed out, 0 rolled back"))
+		})
+
+		It("lists the apps that were rolled back", func() {
+			command.Runtime = Diego
+			output = captureStdout(func() {
+				command.AfterAll(MigrationSummary{
+					Attempts:   2,
+					RolledBack: []ApplicationPrinter{appPrinter},
+				})
+			})
+
+			Expect(output).To(Say("completed: 1 apps, 0 errors, 0 warnings, 0 crashed, 0 timed out, 1 rolled back"))
+			Expect(output).To(Say("Apps rolled back to .+DEA.+:"))
+			Expect(output).To(Say("some-app.+ in space .+some-space.+ / org .+some-org"))
+		})
+
+		It("lists the apps that were never attempted after an interrupt", func() {
+			command.Runtime = Diego
+			output = captureStdout(func() {
+				command.AfterAll(MigrationSummary{
+					Attempts:     1,
+					NotAttempted: []ApplicationPrinter{appPrinter},
+					Interrupted:  true,
+				})
+			})
+
+			Expect(output).To(Say("interrupted: 1 apps, 0 errors"))
+			Expect(output).To(Say("1 apps were not attempted"))
+			Expect(output).To(Say("Apps not attempted, still on .+DEA.+:"))
+			Expect(output).To(Say("some-app.+ in space .+some-space.+ / org .+some-org"))
+		})
+	})
+
 	Describe("HealthCheckNoneWarning", func() {
 		It("writes a warning to the output", func() {
 			command.HealthCheckNoneWarning(appPrinter, output)
@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/cloudfoundry/cli/cf/terminal"
+)
+
+// RuntimeUsage adds up the apps on one runtime. Memory is the memory of every
+// instance, so an app with 2 instances of 512M uses 1G.
+type RuntimeUsage struct {
+	Apps      int
+	Instances int
+	Memory    int64
+}
+
+// RuntimeSummaryRow is the usage of both runtimes in an org, or in a space
+// when Space is set.
+type RuntimeSummaryRow struct {
+	Organization string
+	Space        string
+	DEA          RuntimeUsage
+	Diego        RuntimeUsage
+}
+
+func (r *RuntimeSummaryRow) Add(runtime Runtime, instances int, memory int64) {
+	usage := &r.DEA
+	if runtime == Diego {
+		usage = &r.Diego
+	}
+
+	usage.Apps++
+	usage.Instances += instances
+	usage.Memory += int64(instances) * memory
+}
+
+// PercentMigrated is the share of apps that run on Diego.
+func (r RuntimeSummaryRow) PercentMigrated() float64 {
+	total := r.DEA.Apps + r.Diego.Apps
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(r.Diego.Apps) / float64(total)
+}
+
+type RuntimeSummaryCommand struct {
+	Username string
+	Scopes   []Scope
+	UI       terminal.UI
+}
+
+func (c *RuntimeSummaryCommand) BeforeAll() {
+	fmt.Printf(
+		"Getting runtime summary%s as %s...\n",
+		inScopes(c.Scopes),
+		terminal.EntityNameColor(c.Username),
+	)
+}
+
+// AfterAll prints one table per org and one per space, each ending with the
+// total of all rows.
+func (c *RuntimeSummaryCommand) AfterAll(orgs []RuntimeSummaryRow, spaces []RuntimeSummaryRow) {
+	SayOK()
+
+	usageHeaders := []string{
+		"dea apps", "dea instances", "dea memory",
+		"diego apps", "diego instances", "diego memory",
+		"migrated",
+	}
+
+	total := RuntimeSummaryRow{}
+	t := terminal.NewTable(c.UI, append([]string{"org"}, usageHeaders...))
+	for _, org := range orgs {
+		t.Add(append([]string{org.Organization}, usageColumns(org)...)...)
+
+		total.DEA = addUsage(total.DEA, org.DEA)
+		total.Diego = addUsage(total.Diego, org.Diego)
+	}
+	t.Add(append([]string{"total"}, usageColumns(total)...)...)
+	t.Print()
+
+	fmt.Println()
+
+	t = terminal.NewTable(c.UI, append([]string{"org", "space"}, usageHeaders...))
+	for _, space := range spaces {
+		t.Add(append([]string{space.Organization, space.Space}, usageColumns(space)...)...)
+	}
+	t.Print()
+}
+
+func usageColumns(row RuntimeSummaryRow) []string {
+	return []string{
+		strconv.Itoa(row.DEA.Apps),
+		strconv.Itoa(row.DEA.Instances),
+		FormatMegabytes(row.DEA.Memory),
+		strconv.Itoa(row.Diego.Apps),
+		strconv.Itoa(row.Diego.Instances),
+		FormatMegabytes(row.Diego.Memory),
+		fmt.Sprintf("%.1f%%", row.PercentMigrated()),
+	}
+}
+
+func addUsage(a, b RuntimeUsage) RuntimeUsage {
+	return RuntimeUsage{
+		Apps:      a.Apps + b.Apps,
+		Instances: a.Instances + b.Instances,
+		Memory:    a.Memory + b.Memory,
+	}
+}
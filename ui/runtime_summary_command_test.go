@@ -0,0 +1,85 @@
+package ui_test
+
+import (
+	"os"
+
+	. "github.com/cloudfoundry-incubator/diego-enabler/ui"
+	"github.com/cloudfoundry/cli/cf/terminal"
+	"github.com/cloudfoundry/cli/cf/trace"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("RuntimeSummaryCommand", func() {
+	Describe("RuntimeSummaryRow", func() {
+		It("reports the percentage of apps on Diego", func() {
+			row := RuntimeSummaryRow{}
+			row.Add(Diego, 2, 512)
+			row.Add(DEA, 1, 512)
+			row.Add(DEA, 1, 512)
+			row.Add(DEA, 1, 512)
+
+			Expect(row.PercentMigrated()).To(Equal(25.0))
+		})
+
+		It("reports 0% when there are no apps", func() {
+			Expect(RuntimeSummaryRow{}.PercentMigrated()).To(Equal(0.0))
+		})
+	})
+
+	Describe("BeforeAll", func() {
+		It("names the orgs and spaces the summary is restricted to", func() {
+			output := captureStdout(func() {
+				command := RuntimeSummaryCommand{
+					Username: "some-user",
+					Scopes: []Scope{
+						{Organization: "org-a"},
+						{Organization: "org-b", Space: "space-b"},
+					},
+				}
+				command.BeforeAll()
+			})
+
+			Expect(output).To(Say("Getting runtime summary in org .*org-a.*, org .*org-b.* / .*space-b.* as .*some-user"))
+		})
+	})
+
+	Describe("AfterAll", func() {
+		It("prints the orgs with a total and the spaces", func() {
+			output := captureStdout(func() {
+				command := RuntimeSummaryCommand{
+					UI: terminal.NewUI(os.Stdin, terminal.NewTeePrinter(), trace.NewLogger(false, "", "")),
+				}
+				command.AfterAll(
+					[]RuntimeSummaryRow{
+						{
+							Organization: "org-a",
+							DEA:          RuntimeUsage{Apps: 1, Instances: 2, Memory: 1024},
+							Diego:        RuntimeUsage{Apps: 3, Instances: 3, Memory: 768},
+						},
+						{
+							Organization: "org-b",
+							Diego:        RuntimeUsage{Apps: 1, Instances: 1, Memory: 256},
+						},
+					},
+					[]RuntimeSummaryRow{
+						{
+							Organization: "org-a",
+							Space:        "space-a",
+							Diego:        RuntimeUsage{Apps: 1, Instances: 1, Memory: 256},
+						},
+					},
+				)
+			})
+
+			Expect(output).To(Say(`org.+dea apps.+dea instances.+dea memory.+diego apps.+diego instances.+diego memory.+migrated`))
+			Expect(output).To(Say(`org-a.+1\s+2\s+1G\s+3\s+3\s+768M\s+75.0`))
+			Expect(output).To(Say(`org-b.+0\s+0\s+0M\s+1\s+1\s+256M\s+100.0`))
+			Expect(output).To(Say(`total.+1\s+2\s+1G\s+4\s+4\s+1G\s+80.0`))
+			Expect(output).To(Say(`org.+space.+dea apps`))
+			Expect(output).To(Say(`org-a.+space-a\s+0\s+0\s+0M\s+1\s+1\s+256M\s+100.0`))
+		})
+	})
+})
@@ -3,6 +3,8 @@ package ui
 import (
 	"fmt"
 	"strings"
+
+	"github.com/cloudfoundry/cli/cf/terminal"
 )
 
 type Runtime string
@@ -41,9 +43,49 @@ func ParseRuntime(runtime string) (Runtime, error) {
 	}
 }
 
+// Scope is an org, or a space in it, that a command was restricted to.
+type Scope struct {
+	Organization string
+	Space        string
+}
+
+func (s Scope) String() string {
+	if s.Space == "" {
+		return "org " + terminal.EntityNameColor(s.Organization)
+	}
+	return fmt.Sprintf("org %s / %s", terminal.EntityNameColor(s.Organization), terminal.EntityNameColor(s.Space))
+}
+
+// inScopes describes the scopes as " in org ORG / SPACE, org ORG", or as
+// nothing when there are none.
+func inScopes(scopes []Scope) string {
+	if len(scopes) == 0 {
+		return ""
+	}
+
+	var described []string
+	for _, scope := range scopes {
+		described = append(described, scope.String())
+	}
+	return " in " + strings.Join(described, ", ")
+}
+
+// FormatMegabytes matches the way cf apps shows memory and disk quotas.
+func FormatMegabytes(megabytes int64) string {
+	if megabytes >= 1024 && megabytes%1024 == 0 {
+		return fmt.Sprintf("%dG", megabytes/1024)
+	}
+	return fmt.Sprintf("%dM", megabytes)
+}
+
 //go:generate counterfeiter . ApplicationPrinter
 type ApplicationPrinter interface {
 	Name() string
 	Organization() string
 	Space() string
+	Guid() string
+	OrganizationGuid() string
+	SpaceGuid() string
+	Runtime() Runtime
+	Column(name string) string
 }
@@ -1,8 +1,12 @@
 package ui_test
 
 import (
+	"io"
+	"os"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
 
 	"testing"
 )
@@ -11,3 +15,21 @@ func TestUi(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Ui Suite")
 }
+
+func captureStdout(f func()) *Buffer {
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	Expect(err).NotTo(HaveOccurred())
+
+	os.Stdout = w
+	f()
+	os.Stdout = stdout
+	w.Close()
+
+	buf := NewBuffer()
+	_, err = io.Copy(buf, r)
+	Expect(err).NotTo(HaveOccurred())
+	r.Close()
+
+	return buf
+}
@@ -26,6 +26,38 @@ type FakeApplicationPrinter struct {
 	spaceReturns     struct {
 		result1 string
 	}
+	GuidStub        func() string
+	guidMutex       sync.RWMutex
+	guidArgsForCall []struct{}
+	guidReturns     struct {
+		result1 string
+	}
+	OrganizationGuidStub        func() string
+	organizationGuidMutex       sync.RWMutex
+	organizationGuidArgsForCall []struct{}
+	organizationGuidReturns     struct {
+		result1 string
+	}
+	SpaceGuidStub        func() string
+	spaceGuidMutex       sync.RWMutex
+	spaceGuidArgsForCall []struct{}
+	spaceGuidReturns     struct {
+		result1 string
+	}
+	RuntimeStub        func() ui.Runtime
+	runtimeMutex       sync.RWMutex
+	runtimeArgsForCall []struct{}
+	runtimeReturns     struct {
+		result1 ui.Runtime
+	}
+	ColumnStub        func(name string) string
+	columnMutex       sync.RWMutex
+	columnArgsForCall []struct {
+		name string
+	}
+	columnReturns struct {
+		result1 string
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -105,6 +137,139 @@ func (fake *FakeApplicationPrinter) SpaceReturns(result1 string) {
 	}{result1}
 }
 
+func (fake *FakeApplicationPrinter) Guid() string {
+	fake.guidMutex.Lock()
+	fake.guidArgsForCall = append(fake.guidArgsForCall, struct{}{})
+	fake.recordInvocation("Guid", []interface{}{})
+	fake.guidMutex.Unlock()
+	if fake.GuidStub != nil {
+		return fake.GuidStub()
+	} else {
+		return fake.guidReturns.result1
+	}
+}
+
+func (fake *FakeApplicationPrinter) GuidCallCount() int {
+	fake.guidMutex.RLock()
+	defer fake.guidMutex.RUnlock()
+	return len(fake.guidArgsForCall)
+}
+
+func (fake *FakeApplicationPrinter) GuidReturns(result1 string) {
+	fake.GuidStub = nil
+	fake.guidReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeApplicationPrinter) OrganizationGuid() string {
+	fake.organizationGuidMutex.Lock()
+	fake.organizationGuidArgsForCall = append(fake.organizationGuidArgsForCall, struct{}{})
+	fake.recordInvocation("OrganizationGuid", []interface{}{})
+	fake.organizationGuidMutex.Unlock()
+	if fake.OrganizationGuidStub != nil {
+		return fake.OrganizationGuidStub()
+	} else {
+		return fake.organizationGuidReturns.result1
+	}
+}
+
+func (fake *FakeApplicationPrinter) OrganizationGuidCallCount() int {
+	fake.organizationGuidMutex.RLock()
+	defer fake.organizationGuidMutex.RUnlock()
+	return len(fake.organizationGuidArgsForCall)
+}
+
+func (fake *FakeApplicationPrinter) OrganizationGuidReturns(result1 string) {
+	fake.OrganizationGuidStub = nil
+	fake.organizationGuidReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeApplicationPrinter) SpaceGuid() string {
+	fake.spaceGuidMutex.Lock()
+	fake.spaceGuidArgsForCall = append(fake.spaceGuidArgsForCall, struct{}{})
+	fake.recordInvocation("SpaceGuid", []interface{}{})
+	fake.spaceGuidMutex.Unlock()
+	if fake.SpaceGuidStub != nil {
+		return fake.SpaceGuidStub()
+	} else {
+		return fake.spaceGuidReturns.result1
+	}
+}
+
+func (fake *FakeApplicationPrinter) SpaceGuidCallCount() int {
+	fake.spaceGuidMutex.RLock()
+	defer fake.spaceGuidMutex.RUnlock()
+	return len(fake.spaceGuidArgsForCall)
+}
+
+func (fake *FakeApplicationPrinter) SpaceGuidReturns(result1 string) {
+	fake.SpaceGuidStub = nil
+	fake.spaceGuidReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeApplicationPrinter) Runtime() ui.Runtime {
+	fake.runtimeMutex.Lock()
+	fake.runtimeArgsForCall = append(fake.runtimeArgsForCall, struct{}{})
+	fake.recordInvocation("Runtime", []interface{}{})
+	fake.runtimeMutex.Unlock()
+	if fake.RuntimeStub != nil {
+		return fake.RuntimeStub()
+	} else {
+		return fake.runtimeReturns.result1
+	}
+}
+
+func (fake *FakeApplicationPrinter) RuntimeCallCount() int {
+	fake.runtimeMutex.RLock()
+	defer fake.runtimeMutex.RUnlock()
+	return len(fake.runtimeArgsForCall)
+}
+
+func (fake *FakeApplicationPrinter) RuntimeReturns(result1 ui.Runtime) {
+	fake.RuntimeStub = nil
+	fake.runtimeReturns = struct {
+		result1 ui.Runtime
+	}{result1}
+}
+
+func (fake *FakeApplicationPrinter) Column(name string) string {
+	fake.columnMutex.Lock()
+	fake.columnArgsForCall = append(fake.columnArgsForCall, struct {
+		name string
+	}{name})
+	fake.recordInvocation("Column", []interface{}{name})
+	fake.columnMutex.Unlock()
+	if fake.ColumnStub != nil {
+		return fake.ColumnStub(name)
+	} else {
+		return fake.columnReturns.result1
+	}
+}
+
+func (fake *FakeApplicationPrinter) ColumnCallCount() int {
+	fake.columnMutex.RLock()
+	defer fake.columnMutex.RUnlock()
+	return len(fake.columnArgsForCall)
+}
+
+func (fake *FakeApplicationPrinter) ColumnArgsForCall(i int) string {
+	fake.columnMutex.RLock()
+	defer fake.columnMutex.RUnlock()
+	return fake.columnArgsForCall[i].name
+}
+
+func (fake *FakeApplicationPrinter) ColumnReturns(result1 string) {
+	fake.ColumnStub = nil
+	fake.columnReturns = struct {
+		result1 string
+	}{result1}
+}
+
 func (fake *FakeApplicationPrinter) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -114,6 +279,16 @@ func (fake *FakeApplicationPrinter) Invocations() map[string][][]interface{} {
 	defer fake.organizationMutex.RUnlock()
 	fake.spaceMutex.RLock()
 	defer fake.spaceMutex.RUnlock()
+	fake.guidMutex.RLock()
+	defer fake.guidMutex.RUnlock()
+	fake.organizationGuidMutex.RLock()
+	defer fake.organizationGuidMutex.RUnlock()
+	fake.spaceGuidMutex.RLock()
+	defer fake.spaceGuidMutex.RUnlock()
+	fake.runtimeMutex.RLock()
+	defer fake.runtimeMutex.RUnlock()
+	fake.columnMutex.RLock()
+	defer fake.columnMutex.RUnlock()
 	return fake.invocations
 }
 